@@ -0,0 +1,87 @@
+// Package crm delivers website leads to a customer relationship manager.
+package crm
+
+import (
+	"context"
+	"errors"
+)
+
+// Lead is the CRM-agnostic view of a website lead.
+type Lead struct {
+	ID        string   `json:"id"`
+	Kind      string   `json:"kind"`
+	EventType string   `json:"eventType"` // e.g., "General Inquiry", "Seller Inquiry"
+	Source    string   `json:"source"`
+	Message   string   `json:"message,omitempty"`
+	FirstName string   `json:"firstName"`
+	LastName  string   `json:"lastName"`
+	Email     string   `json:"email"`
+	Phone     string   `json:"phone"`
+	Address   *Address `json:"address,omitempty"`
+	// Property is the listing the lead asked about, if any.
+	Property *Property `json:"property,omitempty"`
+	// Attribution of the visit that produced the lead
+	Campaign *Campaign `json:"campaign,omitempty"`
+	PageURL  string    `json:"pageUrl,omitempty"`
+	Referrer string    `json:"referrer,omitempty"`
+	// AssignedTo is the agent chosen by the lead routing rules, if any.
+	AssignedTo string `json:"assignedTo,omitempty"`
+	// Tags are added to the person, such as the lead's score band.
+	Tags []string `json:"tags,omitempty"`
+}
+
+type Address struct {
+	Street string `json:"street"`
+	City   string `json:"city"`
+	State  string `json:"state"`
+	Zip    string `json:"zip,omitempty"`
+}
+
+// Property identifies a listing by address, MLS number or both.
+type Property struct {
+	Street    string `json:"street,omitempty"`
+	City      string `json:"city,omitempty"`
+	State     string `json:"state,omitempty"`
+	Zip       string `json:"zip,omitempty"`
+	MLSNumber string `json:"mlsNumber,omitempty"`
+}
+
+// Campaign holds the UTM parameters and ad click IDs of a lead's visit.
+type Campaign struct {
+	Source   string `json:"source,omitempty"`
+	Medium   string `json:"medium,omitempty"`
+	Campaign string `json:"campaign,omitempty"`
+	GCLID    string `json:"gclid,omitempty"`
+	FBCLID   string `json:"fbclid,omitempty"`
+}
+
+// Provider sends leads to a CRM.
+type Provider interface {
+	Name() string
+	SendLead(ctx context.Context, lead Lead) error
+}
+
+// ErrPersonNotFound is returned by GetPerson for people deleted from the CRM.
+var ErrPersonNotFound = errors.New("person not found")
+
+// Person is a contact as the CRM currently has it.
+type Person struct {
+	ID     int64
+	Stage  string // e.g. "Lead", "Active Client", "Closed"
+	Emails []string
+	Phones []string
+}
+
+// PersonFetcher is implemented by providers that can look people up, which
+// is needed to sync changes made in the CRM back to our leads.
+type PersonFetcher interface {
+	GetPerson(ctx context.Context, id int64) (Person, error)
+}
+
+// TagUpdater is implemented by providers that can retag a lead already
+// sent, such as when rescoring moves it to another score band.
+type TagUpdater interface {
+	// UpdateTags replaces the tags starting with prefix on the lead's
+	// person with lead.Tags. A lead the CRM doesn't have yet is skipped.
+	UpdateTags(ctx context.Context, lead Lead, prefix string) error
+}
@@ -0,0 +1,321 @@
+package crm
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	fubEventsURL = "https://api.followupboss.com/v1/events"
+	fubPeopleURL = "https://api.followupboss.com/v1/people/"
+)
+
+// FollowUpBoss sends leads to the Follow Up Boss events API.
+type FollowUpBoss struct {
+	apiKey    string
+	system    string
+	systemKey string
+	client    *http.Client
+}
+
+func NewFollowUpBoss(apiKey, system, systemKey string) *FollowUpBoss {
+	return &FollowUpBoss{
+		apiKey:    apiKey,
+		system:    system,
+		systemKey: systemKey,
+		client:    &http.Client{Timeout: 15 * time.Second},
+	}
+}
+
+// Follow Up Boss API payload structures
+type fubEmail struct {
+	Value string `json:"value"`
+	Type  string `json:"type"`
+}
+
+type fubPhone struct {
+	Value string `json:"value"`
+	Type  string `json:"type"`
+}
+
+type fubAddress struct {
+	Type   string `json:"type"`
+	Street string `json:"street"`
+	City   string `json:"city"`
+	State  string `json:"state"`
+	Code   string `json:"code,omitempty"`
+}
+
+type fubPerson struct {
+	FirstName string       `json:"firstName"`
+	LastName  string       `json:"lastName"`
+	Emails    []fubEmail   `json:"emails"`
+	Phones    []fubPhone   `json:"phones"`
+	Addresses []fubAddress `json:"addresses,omitempty"`
+	// AssignedTo is the name of the Follow Up Boss user to assign.
+	AssignedTo string   `json:"assignedTo,omitempty"`
+	Tags       []string `json:"tags,omitempty"`
+}
+
+type fubProperty struct {
+	Street    string `json:"street,omitempty"`
+	City      string `json:"city,omitempty"`
+	State     string `json:"state,omitempty"`
+	Code      string `json:"code,omitempty"`
+	MLSNumber string `json:"mlsNumber,omitempty"`
+}
+
+type fubCampaign struct {
+	Source   string `json:"source,omitempty"`
+	Medium   string `json:"medium,omitempty"`
+	Campaign string `json:"campaign,omitempty"`
+}
+
+type fubEvent struct {
+	Source       string       `json:"source"`
+	Type         string       `json:"type"`
+	Message      string       `json:"message,omitempty"`
+	Person       fubPerson    `json:"person"`
+	Property     *fubProperty `json:"property,omitempty"`
+	Campaign     *fubCampaign `json:"campaign,omitempty"`
+	PageURL      string       `json:"pageUrl,omitempty"`
+	PageReferrer string       `json:"pageReferrer,omitempty"`
+}
+
+func (f *FollowUpBoss) Name() string {
+	return "fub"
+}
+
+func (f *FollowUpBoss) SendLead(ctx context.Context, lead Lead) error {
+	payloadJSON, err := json.Marshal(newFUBEvent(lead))
+	if err != nil {
+		return fmt.Errorf("failed to marshal FUB event: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", fubEventsURL, bytes.NewBuffer(payloadJSON))
+	if err != nil {
+		return fmt.Errorf("failed to create FUB request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-System", f.system)
+	req.Header.Set("X-System-Key", f.systemKey)
+	req.SetBasicAuth(f.apiKey, "")
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send FUB request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("FUB request failed with status %s: %s", resp.Status, body)
+	}
+
+	return nil
+}
+
+func newFUBEvent(lead Lead) fubEvent {
+	event := fubEvent{
+		Source:       lead.Source,
+		Type:         lead.EventType,
+		Message:      lead.Message,
+		PageURL:      lead.PageURL,
+		PageReferrer: lead.Referrer,
+		Person: fubPerson{
+			FirstName: lead.FirstName,
+			LastName:  lead.LastName,
+			Emails: []fubEmail{
+				{
+					Value: lead.Email,
+					Type:  "Personal",
+				},
+			},
+			Phones: []fubPhone{
+				{
+					Value: lead.Phone,
+					Type:  "Mobile",
+				},
+			},
+			AssignedTo: lead.AssignedTo,
+			Tags:       lead.Tags,
+		},
+	}
+
+	if lead.Address != nil {
+		event.Person.Addresses = []fubAddress{
+			{
+				Type:   "home",
+				Street: lead.Address.Street,
+				City:   lead.Address.City,
+				State:  lead.Address.State,
+				Code:   lead.Address.Zip,
+			},
+		}
+	}
+
+	if lead.Property != nil {
+		event.Property = &fubProperty{
+			Street:    lead.Property.Street,
+			City:      lead.Property.City,
+			State:     lead.Property.State,
+			Code:      lead.Property.Zip,
+			MLSNumber: lead.Property.MLSNumber,
+		}
+	}
+
+	if lead.Campaign != nil {
+		event.Campaign = &fubCampaign{
+			Source:   lead.Campaign.Source,
+			Medium:   lead.Campaign.Medium,
+			Campaign: lead.Campaign.Campaign,
+		}
+	}
+
+	return event
+}
+
+// GetPerson fetches a person by their Follow Up Boss ID.
+func (f *FollowUpBoss) GetPerson(ctx context.Context, id int64) (Person, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", fubPeopleURL+strconv.FormatInt(id, 10), nil)
+	if err != nil {
+		return Person{}, fmt.Errorf("failed to create FUB request: %w", err)
+	}
+	req.Header.Set("X-System", f.system)
+	req.Header.Set("X-System-Key", f.systemKey)
+	req.SetBasicAuth(f.apiKey, "")
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return Person{}, fmt.Errorf("failed to send FUB request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return Person{}, ErrPersonNotFound
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(resp.Body)
+		return Person{}, fmt.Errorf("FUB request failed with status %s: %s", resp.Status, body)
+	}
+
+	var p struct {
+		ID     int64      `json:"id"`
+		Stage  string     `json:"stage"`
+		Emails []fubEmail `json:"emails"`
+		Phones []fubPhone `json:"phones"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&p); err != nil {
+		return Person{}, fmt.Errorf("failed to decode FUB person: %w", err)
+	}
+
+	person := Person{ID: p.ID, Stage: p.Stage}
+	for _, e := range p.Emails {
+		person.Emails = append(person.Emails, e.Value)
+	}
+	for _, ph := range p.Phones {
+		person.Phones = append(person.Phones, ph.Value)
+	}
+	return person, nil
+}
+
+// UpdateTags finds the person by the lead's email and replaces their tags
+// starting with prefix with lead.Tags.
+func (f *FollowUpBoss) UpdateTags(ctx context.Context, lead Lead, prefix string) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", strings.TrimSuffix(fubPeopleURL, "/")+"?fields=id,tags&limit=1&email="+url.QueryEscape(lead.Email), nil)
+	if err != nil {
+		return fmt.Errorf("failed to create FUB request: %w", err)
+	}
+	var res struct {
+		People []struct {
+			ID   int64    `json:"id"`
+			Tags []string `json:"tags"`
+		} `json:"people"`
+	}
+	if err := f.do(req, &res); err != nil {
+		return err
+	}
+	if len(res.People) == 0 {
+		return nil
+	}
+
+	person := res.People[0]
+	tags := replaceTags(person.Tags, prefix, lead.Tags)
+	if slices.Equal(tags, person.Tags) {
+		return nil
+	}
+
+	body, err := json.Marshal(map[string][]string{"tags": tags})
+	if err != nil {
+		return fmt.Errorf("failed to marshal FUB tags: %w", err)
+	}
+	req, err = http.NewRequestWithContext(ctx, "PUT", fubPeopleURL+strconv.FormatInt(person.ID, 10), bytes.NewBuffer(body))
+	if err != nil {
+		return fmt.Errorf("failed to create FUB request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return f.do(req, nil)
+}
+
+// do sends an authenticated request and decodes the response into v, if
+// it isn't nil.
+func (f *FollowUpBoss) do(req *http.Request, v any) error {
+	req.Header.Set("X-System", f.system)
+	req.Header.Set("X-System-Key", f.systemKey)
+	req.SetBasicAuth(f.apiKey, "")
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send FUB request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("FUB request failed with status %s: %s", resp.Status, body)
+	}
+	if v == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("failed to decode FUB response: %w", err)
+	}
+	return nil
+}
+
+// replaceTags drops the tags starting with prefix and appends add.
+func replaceTags(tags []string, prefix string, add []string) []string {
+	out := []string{}
+	for _, t := range tags {
+		if !strings.HasPrefix(t, prefix) {
+			out = append(out, t)
+		}
+	}
+	return append(out, add...)
+}
+
+// VerifyFUBSignature reports whether signature, the FUB-Signature header of
+// a Follow Up Boss webhook, is the hex HMAC-SHA256 of the base64-encoded
+// body keyed with the system key.
+func VerifyFUBSignature(body []byte, signature, systemKey string) bool {
+	if signature == "" || systemKey == "" {
+		return false
+	}
+	mac := hmac.New(sha256.New, []byte(systemKey))
+	mac.Write([]byte(base64.StdEncoding.EncodeToString(body)))
+	return hmac.Equal([]byte(signature), []byte(hex.EncodeToString(mac.Sum(nil))))
+}
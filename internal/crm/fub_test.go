@@ -0,0 +1,60 @@
+package crm
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"slices"
+	"testing"
+)
+
+func TestVerifyFUBSignature(t *testing.T) {
+	body := []byte(`{"eventId":"1","event":"peopleStageUpdated","resourceIds":[42]}`)
+	mac := hmac.New(sha256.New, []byte("system-key"))
+	mac.Write([]byte(base64.StdEncoding.EncodeToString(body)))
+	valid := hex.EncodeToString(mac.Sum(nil))
+
+	tests := []struct {
+		name      string
+		body      []byte
+		signature string
+		systemKey string
+		want      bool
+	}{
+		{"Valid signature", body, valid, "system-key", true},
+		{"Wrong system key", body, valid, "other-key", false},
+		{"Tampered body", []byte(`{"eventId":"2"}`), valid, "system-key", false},
+		{"Missing signature", body, "", "system-key", false},
+		{"No system key configured", body, valid, "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := VerifyFUBSignature(tt.body, tt.signature, tt.systemKey); got != tt.want {
+				t.Errorf("VerifyFUBSignature() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReplaceTags(t *testing.T) {
+	tests := []struct {
+		name string
+		tags []string
+		add  []string
+		want []string
+	}{
+		{"No tags", nil, []string{"Score: Hot"}, []string{"Score: Hot"}},
+		{"Replaces the band", []string{"Buyer", "Score: Cold"}, []string{"Score: Warm"}, []string{"Buyer", "Score: Warm"}},
+		{"Keeps other tags", []string{"Score: Cold", "Zillow", "Score: Warm"}, []string{"Score: Hot"}, []string{"Zillow", "Score: Hot"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := replaceTags(tt.tags, "Score: ", tt.add); !slices.Equal(got, tt.want) {
+				t.Errorf("replaceTags() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,23 @@
+package crm
+
+import (
+	"context"
+	"log"
+)
+
+// Noop logs leads instead of sending them anywhere. Useful for running the
+// API locally without CRM credentials.
+type Noop struct{}
+
+func NewNoop() *Noop {
+	return &Noop{}
+}
+
+func (n *Noop) Name() string {
+	return "noop"
+}
+
+func (n *Noop) SendLead(ctx context.Context, lead Lead) error {
+	log.Printf("CRM disabled, not sending %s lead %s (%s)", lead.Kind, lead.ID, lead.Email)
+	return nil
+}
@@ -0,0 +1,61 @@
+package crm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/DiegoGarciaCo/websitesAPI/internal/webhooks"
+)
+
+// Webhook posts leads as JSON to an arbitrary URL, signed as described in
+// package webhooks.
+type Webhook struct {
+	url    string
+	secret string
+	client *http.Client
+}
+
+func NewWebhook(url, secret string) *Webhook {
+	return &Webhook{
+		url:    url,
+		secret: secret,
+		client: &http.Client{Timeout: 15 * time.Second},
+	}
+}
+
+func (wh *Webhook) Name() string {
+	return "webhook"
+}
+
+func (wh *Webhook) SendLead(ctx context.Context, lead Lead) error {
+	body, err := json.Marshal(lead)
+	if err != nil {
+		return fmt.Errorf("failed to marshal lead: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", wh.url, bytes.NewBuffer(body))
+	if err != nil {
+		return fmt.Errorf("failed to create webhook request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	webhooks.SetHeaders(req.Header, wh.secret, time.Now(), body)
+
+	resp, err := wh.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send webhook request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("webhook request failed with status %s: %s", resp.Status, respBody)
+	}
+
+	return nil
+}
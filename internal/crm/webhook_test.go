@@ -0,0 +1,50 @@
+package crm
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWebhookSendLead(t *testing.T) {
+	var gotSignature, gotTimestamp string
+	var gotBody []byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotSignature = r.Header.Get("X-Webhook-Signature")
+		gotTimestamp = r.Header.Get("X-Webhook-Timestamp")
+		gotBody, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	wh := NewWebhook(srv.URL, "secret")
+	err := wh.SendLead(context.Background(), Lead{ID: "1", Kind: "contact", Email: "jane@example.com"})
+	if err != nil {
+		t.Fatalf("SendLead() error = %v", err)
+	}
+
+	mac := hmac.New(sha256.New, []byte("secret"))
+	mac.Write([]byte(gotTimestamp + "."))
+	mac.Write(gotBody)
+	want := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+	if gotSignature != want {
+		t.Errorf("SendLead() signature = %v, want %v", gotSignature, want)
+	}
+}
+
+func TestWebhookSendLeadError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer srv.Close()
+
+	wh := NewWebhook(srv.URL, "secret")
+	if err := wh.SendLead(context.Background(), Lead{}); err == nil {
+		t.Error("SendLead() expected error for non-2xx response")
+	}
+}
@@ -6,17 +6,190 @@ package database
 
 import (
 	"database/sql"
+	"encoding/json"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+type ConsentRecord struct {
+	ID          uuid.UUID
+	LeadID      uuid.NullUUID
+	Email       string
+	Purpose     string
+	Consented   bool
+	TextVersion string
+	IpAddress   string
+	UserAgent   string
+	Route       string
+	CreatedAt   time.Time
+}
+
 type Csft struct {
 	Token     string
 	UserID    uuid.UUID
 	CreatedAt time.Time
 }
 
+type EmailEvent struct {
+	ID         uuid.UUID
+	Email      string
+	Event      string
+	MessageID  string
+	Payload    json.RawMessage
+	OccurredAt time.Time
+	ReceivedAt time.Time
+}
+
+type EmailSuppression struct {
+	ID           uuid.UUID
+	Email        string
+	Reason       string
+	SuppressedAt time.Time
+	CreatedAt    time.Time
+}
+
+type IdempotencyKey struct {
+	Key            string
+	RequestHash    string
+	ResponseStatus int32
+	ContentType    string
+	ResponseBody   []byte
+	CreatedAt      time.Time
+	ExpiresAt      time.Time
+}
+
+type Lead struct {
+	ID               uuid.UUID
+	LeadType         string
+	FirstName        string
+	LastName         string
+	Email            string
+	Phone            string
+	Message          string
+	Payload          json.RawMessage
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
+	Status           string
+	Source           string
+	UtmSource        string
+	UtmMedium        string
+	UtmCampaign      string
+	Gclid            string
+	Fbclid           string
+	Referrer         string
+	LandingPage      string
+	MarketingConsent bool
+	AssignedAgent    string
+	RoutingRuleID    uuid.NullUUID
+	Score            int32
+	CrmPersonID      sql.NullInt64
+	CrmStage         string
+}
+
+type LeadDelivery struct {
+	ID            uuid.UUID
+	LeadID        uuid.UUID
+	Target        string
+	Status        string
+	Attempts      int32
+	LastError     sql.NullString
+	NextAttemptAt time.Time
+	DeliveredAt   sql.NullTime
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+type LeadDeliveryAttempt struct {
+	ID          uuid.UUID
+	DeliveryID  uuid.UUID
+	Attempt     int32
+	Status      string
+	Error       sql.NullString
+	AttemptedAt time.Time
+}
+
+type LeadNote struct {
+	ID        uuid.UUID
+	LeadID    uuid.UUID
+	UserID    uuid.NullUUID
+	Body      string
+	CreatedAt time.Time
+}
+
+type MortgageAssumption struct {
+	ID              uuid.UUID
+	State           string
+	County          string
+	Zip             string
+	TaxRate         float64
+	AnnualInsurance float64
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+}
+
+type NewsletterSubscription struct {
+	ID             uuid.UUID
+	Email          string
+	FirstName      string
+	Status         string
+	ConsentVersion string
+	IpAddress      string
+	UserAgent      string
+	ExpiresAt      time.Time
+	ConfirmedAt    sql.NullTime
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+type NotificationDigestItem struct {
+	ID            uuid.UUID
+	LeadID        uuid.UUID
+	Recipients    []string
+	CreatedAt     time.Time
+	SentAt        sql.NullTime
+	Attempts      int32
+	NextAttemptAt time.Time
+	LastError     sql.NullString
+	FailedAt      sql.NullTime
+}
+
+type NotificationRule struct {
+	ID                uuid.UUID
+	LeadType          string
+	Recipients        []string
+	TemplateID        int32
+	Enabled           bool
+	RespectQuietHours bool
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
+}
+
+type OpenHouse struct {
+	ID              uuid.UUID
+	Address         string
+	City            string
+	State           string
+	Zip             string
+	MlsNumber       string
+	StartsAt        time.Time
+	EndsAt          time.Time
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+	DeviceTokenHash []byte
+}
+
+type OpenHouseSignIn struct {
+	ID          uuid.UUID
+	OpenHouseID uuid.UUID
+	ClientID    string
+	LeadID      uuid.UUID
+	HasAgent    bool
+	PreApproved bool
+	SignedInAt  time.Time
+	CreatedAt   time.Time
+}
+
 type Post struct {
 	ID          uuid.UUID
 	Title       string
@@ -32,6 +205,18 @@ type Post struct {
 	Tags        []string
 }
 
+type PrivacyRequest struct {
+	Seq         int64
+	ID          uuid.UUID
+	Action      string
+	SubjectHash string
+	Details     string
+	PerformedBy string
+	CreatedAt   time.Time
+	PrevHash    string
+	Hash        string
+}
+
 type RefreshToken struct {
 	Token     string
 	CreatedAt time.Time
@@ -41,6 +226,47 @@ type RefreshToken struct {
 	RevokedAt sql.NullTime
 }
 
+type RoutingRule struct {
+	ID          uuid.UUID
+	Name        string
+	Priority    int32
+	Enabled     bool
+	LeadTypes   []string
+	Zips        []string
+	Cities      []string
+	MinPrice    sql.NullFloat64
+	MaxPrice    sql.NullFloat64
+	ActiveHours string
+	Strategy    string
+	Agents      []string
+	NextAgent   int32
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+type ScoringRule struct {
+	ID        uuid.UUID
+	Signal    string
+	Value     string
+	MinValue  sql.NullFloat64
+	MaxValue  sql.NullFloat64
+	Points    int32
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+type SpamQuarantine struct {
+	ID        uuid.UUID
+	Route     string
+	Reason    string
+	Score     int32
+	Email     string
+	IpAddress string
+	UserAgent string
+	Payload   json.RawMessage
+	CreatedAt time.Time
+}
+
 type User struct {
 	ID           uuid.UUID
 	Email        string
@@ -51,3 +277,39 @@ type User struct {
 	CreatedAt    sql.NullTime
 	UpdatedAt    sql.NullTime
 }
+
+type WebhookDelivery struct {
+	ID             uuid.UUID
+	SubscriptionID uuid.UUID
+	Event          string
+	Payload        json.RawMessage
+	Status         string
+	Attempts       int32
+	LastError      sql.NullString
+	NextAttemptAt  time.Time
+	DeliveredAt    sql.NullTime
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+type WebhookDeliveryAttempt struct {
+	ID             uuid.UUID
+	DeliveryID     uuid.UUID
+	Attempt        int32
+	Status         string
+	ResponseStatus sql.NullInt32
+	Error          sql.NullString
+	DurationMs     int32
+	AttemptedAt    time.Time
+}
+
+type WebhookSubscription struct {
+	ID          uuid.UUID
+	Url         string
+	Secret      string
+	Events      []string
+	Description string
+	Active      bool
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
@@ -312,10 +312,11 @@ func (q *Queries) ListPublishedPosts(ctx context.Context) ([]ListPublishedPostsR
 	return items, nil
 }
 
-const publishPost = `-- name: PublishPost :exec
+const publishPost = `-- name: PublishPost :one
 UPDATE posts
 SET status = $2, updated_at = CURRENT_TIMESTAMP, published_at = CURRENT_TIMESTAMP
 WHERE id = $1
+RETURNING id, title, slug, content, excerpt, author, published_at, thumbnail, status, created_at, updated_at, tags
 `
 
 type PublishPostParams struct {
@@ -323,9 +324,24 @@ type PublishPostParams struct {
 	Status sql.NullString
 }
 
-func (q *Queries) PublishPost(ctx context.Context, arg PublishPostParams) error {
-	_, err := q.db.ExecContext(ctx, publishPost, arg.ID, arg.Status)
-	return err
+func (q *Queries) PublishPost(ctx context.Context, arg PublishPostParams) (Post, error) {
+	row := q.db.QueryRowContext(ctx, publishPost, arg.ID, arg.Status)
+	var i Post
+	err := row.Scan(
+		&i.ID,
+		&i.Title,
+		&i.Slug,
+		&i.Content,
+		&i.Excerpt,
+		&i.Author,
+		&i.PublishedAt,
+		&i.Thumbnail,
+		&i.Status,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		pq.Array(&i.Tags),
+	)
+	return i, err
 }
 
 const saveAndPublishPost = `-- name: SaveAndPublishPost :one
@@ -0,0 +1,110 @@
+// Package emailevents reads the callbacks Brevo sends for marketing and
+// transactional email events.
+//
+// Brevo is configured to send the shared secret as a bearer token, and may
+// post a single event object or, for batched webhooks, an array of them.
+package emailevents
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Event types we record. Brevo's other events are ignored.
+const (
+	Unsubscribe = "unsubscribe"
+	HardBounce  = "hard_bounce"
+	Spam        = "spam"
+	Delivered   = "delivered"
+)
+
+var ErrInvalidPayload = errors.New("invalid email event payload")
+
+// eventTypes maps the names marketing and transactional webhooks use to
+// our event types.
+var eventTypes = map[string]string{
+	"unsubscribe":  Unsubscribe,
+	"unsubscribed": Unsubscribe,
+	"hard_bounce":  HardBounce,
+	"hardbounce":   HardBounce,
+	"spam":         Spam,
+	"complaint":    Spam,
+	"delivered":    Delivered,
+}
+
+// Event is one recorded email event.
+type Event struct {
+	Type       string
+	Email      string // lowercased
+	MessageID  string // empty for marketing campaigns
+	OccurredAt time.Time
+	Raw        json.RawMessage
+}
+
+// Suppresses reports whether an event of type t means the address should
+// get no more email.
+func Suppresses(t string) bool {
+	return t == Unsubscribe || t == HardBounce || t == Spam
+}
+
+// Authorized reports whether h carries the shared secret as a bearer token.
+func Authorized(h http.Header, secret string) bool {
+	token, ok := strings.CutPrefix(h.Get("Authorization"), "Bearer ")
+	if !ok || secret == "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(strings.TrimSpace(token)), []byte(secret)) == 1
+}
+
+type payload struct {
+	Event     string `json:"event"`
+	Email     string `json:"email"`
+	MessageID string `json:"message-id"`
+	TSEvent   int64  `json:"ts_event"`
+}
+
+// Parse reads the events in body, skipping event types we don't record and
+// events without an email. received is used when an event has no time.
+func Parse(body []byte, received time.Time) ([]Event, error) {
+	var raws []json.RawMessage
+	trimmed := bytes.TrimSpace(body)
+	if len(trimmed) > 0 && trimmed[0] == '[' {
+		if err := json.Unmarshal(trimmed, &raws); err != nil {
+			return nil, ErrInvalidPayload
+		}
+	} else {
+		raws = []json.RawMessage{trimmed}
+	}
+
+	events := []Event{}
+	for _, raw := range raws {
+		var p payload
+		if err := json.Unmarshal(raw, &p); err != nil {
+			return nil, ErrInvalidPayload
+		}
+
+		t, ok := eventTypes[strings.ToLower(p.Event)]
+		email := strings.ToLower(strings.TrimSpace(p.Email))
+		if !ok || email == "" {
+			continue
+		}
+
+		at := received
+		if p.TSEvent > 0 {
+			at = time.Unix(p.TSEvent, 0)
+		}
+		events = append(events, Event{
+			Type:       t,
+			Email:      email,
+			MessageID:  p.MessageID,
+			OccurredAt: at.UTC(),
+			Raw:        raw,
+		})
+	}
+	return events, nil
+}
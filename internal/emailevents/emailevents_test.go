@@ -0,0 +1,113 @@
+package emailevents
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	received := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		body      string
+		wantTypes []string
+		wantErr   error
+	}{
+		{
+			name:      "Transactional hard bounce",
+			body:      `{"event":"hard_bounce","email":"Jane@Example.com","message-id":"<abc@smtp>","ts_event":1735689600}`,
+			wantTypes: []string{HardBounce},
+		},
+		{
+			name:      "Marketing unsubscribe",
+			body:      `{"event":"unsubscribe","email":"jane@example.com","camp_id":12}`,
+			wantTypes: []string{Unsubscribe},
+		},
+		{
+			name:      "Batch skips ignored events",
+			body:      `[{"event":"opened","email":"a@example.com"},{"event":"spam","email":"b@example.com"},{"event":"delivered","email":"c@example.com"}]`,
+			wantTypes: []string{Spam, Delivered},
+		},
+		{
+			name:      "Missing email is skipped",
+			body:      `{"event":"unsubscribed"}`,
+			wantTypes: []string{},
+		},
+		{
+			name:    "Invalid JSON",
+			body:    `{"event":`,
+			wantErr: ErrInvalidPayload,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			events, err := Parse([]byte(tt.body), received)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Parse() error = %v, want %v", err, tt.wantErr)
+			}
+			if len(events) != len(tt.wantTypes) {
+				t.Fatalf("Parse() returned %d events, want %d", len(events), len(tt.wantTypes))
+			}
+			for i, e := range events {
+				if e.Type != tt.wantTypes[i] {
+					t.Errorf("event %d type = %q, want %q", i, e.Type, tt.wantTypes[i])
+				}
+			}
+		})
+	}
+}
+
+func TestParseFields(t *testing.T) {
+	received := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	events, err := Parse([]byte(`{"event":"hard_bounce","email":" Jane@Example.com ","message-id":"<abc@smtp>","ts_event":1735689600}`), received)
+	if err != nil || len(events) != 1 {
+		t.Fatalf("Parse() = %v, %v", events, err)
+	}
+	e := events[0]
+	if e.Email != "jane@example.com" {
+		t.Errorf("Email = %q, want lowercased and trimmed", e.Email)
+	}
+	if e.MessageID != "<abc@smtp>" {
+		t.Errorf("MessageID = %q", e.MessageID)
+	}
+	if !e.OccurredAt.Equal(time.Unix(1735689600, 0)) {
+		t.Errorf("OccurredAt = %v, want ts_event", e.OccurredAt)
+	}
+
+	events, _ = Parse([]byte(`{"event":"spam","email":"jane@example.com"}`), received)
+	if !events[0].OccurredAt.Equal(received) {
+		t.Errorf("OccurredAt = %v, want the receive time without ts_event", events[0].OccurredAt)
+	}
+}
+
+func TestAuthorized(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		secret string
+		want   bool
+	}{
+		{"Matching token", "Bearer s3cret", "s3cret", true},
+		{"Wrong token", "Bearer nope", "s3cret", false},
+		{"Missing header", "", "s3cret", false},
+		{"Basic auth", "Basic czNjcmV0", "s3cret", false},
+		{"No secret configured", "Bearer ", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := http.Header{}
+			if tt.header != "" {
+				h.Set("Authorization", tt.header)
+			}
+			if got := Authorized(h, tt.secret); got != tt.want {
+				t.Errorf("Authorized() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,183 @@
+package handlers
+
+import (
+	"cmp"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/DiegoGarciaCo/websitesAPI/internal/crm"
+	"github.com/DiegoGarciaCo/websitesAPI/internal/database"
+)
+
+const (
+	maxAttributionLength = 255
+	maxURLLength         = 2048
+)
+
+// leadAttribution records where a visitor came from. The frontend sends it
+// alongside the form fields of every lead endpoint.
+type leadAttribution struct {
+	UTMSource   string `json:"utm_source,omitempty"`
+	UTMMedium   string `json:"utm_medium,omitempty"`
+	UTMCampaign string `json:"utm_campaign,omitempty"`
+	GCLID       string `json:"gclid,omitempty"`
+	FBCLID      string `json:"fbclid,omitempty"`
+	Referrer    string `json:"referrer,omitempty"`
+	LandingPage string `json:"landingPage,omitempty"`
+	// PagesVisited counts the pages seen before the form was sent, when
+	// the frontend tracks it. It feeds the lead score.
+	PagesVisited int `json:"pagesVisited,omitempty"`
+}
+
+// normalize trims every field and fills UTM parameters and click IDs the
+// frontend did not send from the landing page query string.
+func (a leadAttribution) normalize() leadAttribution {
+	a.Referrer = truncate(strings.TrimSpace(a.Referrer), maxURLLength)
+	a.LandingPage = truncate(strings.TrimSpace(a.LandingPage), maxURLLength)
+
+	var query url.Values
+	if u, err := url.Parse(a.LandingPage); err == nil {
+		query = u.Query()
+	}
+	fill := func(v *string, key string) {
+		if strings.TrimSpace(*v) == "" {
+			*v = query.Get(key)
+		}
+		*v = truncate(strings.TrimSpace(*v), maxAttributionLength)
+	}
+	fill(&a.UTMSource, "utm_source")
+	fill(&a.UTMMedium, "utm_medium")
+	fill(&a.UTMCampaign, "utm_campaign")
+	fill(&a.GCLID, "gclid")
+	fill(&a.FBCLID, "fbclid")
+	a.PagesVisited = max(a.PagesVisited, 0)
+
+	return a
+}
+
+// source names where the lead came from: the utm_source when present,
+// otherwise the ad network of a click ID or the referring site. It is empty
+// for direct visits.
+func (a leadAttribution) source() string {
+	switch {
+	case a.UTMSource != "":
+		return strings.ToLower(a.UTMSource)
+	case a.GCLID != "":
+		return "google-ads"
+	case a.FBCLID != "":
+		return "facebook-ads"
+	}
+
+	ref, err := url.Parse(a.Referrer)
+	if err != nil || ref.Hostname() == "" {
+		return ""
+	}
+	// Navigation within the site is not a source
+	if landing, err := url.Parse(a.LandingPage); err == nil && strings.EqualFold(landing.Hostname(), ref.Hostname()) {
+		return ""
+	}
+	return strings.TrimPrefix(strings.ToLower(ref.Hostname()), "www.")
+}
+
+func (a leadAttribution) campaign() *crm.Campaign {
+	if a.UTMSource == "" && a.UTMMedium == "" && a.UTMCampaign == "" && a.GCLID == "" && a.FBCLID == "" {
+		return nil
+	}
+	return &crm.Campaign{
+		Source:   a.UTMSource,
+		Medium:   a.UTMMedium,
+		Campaign: a.UTMCampaign,
+		GCLID:    a.GCLID,
+		FBCLID:   a.FBCLID,
+	}
+}
+
+func attributionForLead(lead database.Lead) leadAttribution {
+	return leadAttribution{
+		UTMSource:   lead.UtmSource,
+		UTMMedium:   lead.UtmMedium,
+		UTMCampaign: lead.UtmCampaign,
+		GCLID:       lead.Gclid,
+		FBCLID:      lead.Fbclid,
+		Referrer:    lead.Referrer,
+		LandingPage: lead.LandingPage,
+	}
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return strings.ToValidUTF8(s[:n], "")
+}
+
+// LeadAttribution summarizes lead counts by source, medium and campaign.
+// It accepts the from, to and type filters of ListLeads.
+func (cfg *apiCfg) LeadAttribution(w http.ResponseWriter, req *http.Request) {
+	type sourceResponse struct {
+		Source string `json:"source"`
+		Leads  int64  `json:"leads"`
+	}
+	type campaignResponse struct {
+		Source   string `json:"source"`
+		Medium   string `json:"medium"`
+		Campaign string `json:"campaign"`
+		Leads    int64  `json:"leads"`
+	}
+	type resParams struct {
+		Total     int64              `json:"total"`
+		Sources   []sourceResponse   `json:"sources"`
+		Campaigns []campaignResponse `json:"campaigns"`
+	}
+
+	filter, err := leadFilterFromQuery(req.URL.Query())
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	rows, err := cfg.DB.SummarizeLeadAttribution(req.Context(), database.SummarizeLeadAttributionParams{
+		LeadType:    filter.LeadType,
+		CreatedFrom: filter.CreatedFrom,
+		CreatedTo:   filter.CreatedTo,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Something went wrong", err)
+		return
+	}
+
+	res := resParams{
+		Sources:   []sourceResponse{},
+		Campaigns: make([]campaignResponse, len(rows)),
+	}
+	sourceIndex := map[string]int{}
+	for i, row := range rows {
+		source := row.Source
+		if source == "" {
+			source = "direct"
+		}
+
+		res.Total += row.Leads
+		res.Campaigns[i] = campaignResponse{
+			Source:   source,
+			Medium:   row.UtmMedium,
+			Campaign: row.UtmCampaign,
+			Leads:    row.Leads,
+		}
+
+		j, ok := sourceIndex[source]
+		if !ok {
+			j = len(res.Sources)
+			sourceIndex[source] = j
+			res.Sources = append(res.Sources, sourceResponse{Source: source})
+		}
+		res.Sources[j].Leads += row.Leads
+	}
+	slices.SortStableFunc(res.Sources, func(a, b sourceResponse) int {
+		return cmp.Compare(b.Leads, a.Leads)
+	})
+
+	respondWithJSON(w, http.StatusOK, res)
+}
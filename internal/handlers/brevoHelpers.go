@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -12,35 +13,42 @@ import (
 type attributes struct {
 	FirstName string `json:"FIRSTNAME"`
 	LastName  string `json:"LASTNAME"`
-	Sms	   string `json:"SMS"`
+	Sms       string `json:"SMS,omitempty"` // E.164
+	// Attribution of the contact's latest lead. These attributes must exist
+	// in Brevo.
+	Source      string `json:"LEAD_SOURCE,omitempty"`
+	UTMSource   string `json:"UTM_SOURCE,omitempty"`
+	UTMMedium   string `json:"UTM_MEDIUM,omitempty"`
+	UTMCampaign string `json:"UTM_CAMPAIGN,omitempty"`
 }
 
 type contact struct {
-	Email   string `json:"email"`
-	Attributes attributes `json:"attributes"`
-	ListIDs []int64 `json:"listIds"`
-	UpdateEnabled bool `json:"updateEnabled"`
+	Email         string     `json:"email"`
+	Attributes    attributes `json:"attributes"`
+	ListIDs       []int64    `json:"listIds,omitempty"`
+	UpdateEnabled bool       `json:"updateEnabled"`
+	// EmailBlacklisted is only sent when set, to lift an unsubscribe.
+	EmailBlacklisted *bool `json:"emailBlacklisted,omitempty"`
 }
 type ContactResponse struct {
-	Email           string    `json:"email"`
-	ID              int       `json:"id"`
-	EmailBlacklisted bool     `json:"emailBlacklisted"`
-	SmsBlacklisted   bool     `json:"smsBlacklisted"`
-	CreatedAt       string    `json:"createdAt"`
-	ModifiedAt      string    `json:"modifiedAt"`
-	Attributes      struct {
-		FirstName string `json:"FIRSTNAME"`
-		LastName  string `json:"LASTNAME"`
-		SMS       string `json:"SMS"`
-	} `json:"attributes"`
-	ListIDs    []int64         `json:"listIds"`
+	Email            string         `json:"email"`
+	ID               int            `json:"id"`
+	EmailBlacklisted bool           `json:"emailBlacklisted"`
+	SmsBlacklisted   bool           `json:"smsBlacklisted"`
+	CreatedAt        string         `json:"createdAt"`
+	ModifiedAt       string         `json:"modifiedAt"`
+	Attributes       map[string]any `json:"attributes"`
+	ListIDs          []int64        `json:"listIds"`
 }
 
-func (cfg *apiCfg) GetContactListIDs(email string) ([]int64, error) {
+// GetContact fetches a Brevo contact by email. It returns nil only when
+// Brevo has no such contact; any other failure is an error, so an outage
+// isn't mistaken for a missing contact.
+func (cfg *apiCfg) GetContact(ctx context.Context, email string) (*ContactResponse, error) {
 	endpoint := fmt.Sprintf("https://api.brevo.com/v3/contacts/%s", url.QueryEscape(email))
 
 	// Prepare the request
-	req, err := http.NewRequest("GET", endpoint, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", endpoint, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -48,20 +56,18 @@ func (cfg *apiCfg) GetContactListIDs(email string) ([]int64, error) {
 	req.Header.Set("api-key", cfg.BrevoAPIKey)
 
 	// Send the request
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
 		return nil, err
 	}
 
 	defer resp.Body.Close()
 
-	if resp.StatusCode == http.StatusBadRequest {
-		return nil, fmt.Errorf("failed to get contact list IDs: %s", resp.Status)
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil
 	}
-
 	if resp.StatusCode != http.StatusOK {
-		return nil, nil // Contact not found, return nil
+		return nil, fmt.Errorf("failed to get contact: %s", resp.Status)
 	}
 
 	var response ContactResponse
@@ -69,9 +75,45 @@ func (cfg *apiCfg) GetContactListIDs(email string) ([]int64, error) {
 		return nil, err
 	}
 
-	return response.ListIDs, nil
+	return &response, nil
+}
+
+func (cfg *apiCfg) GetContactListIDs(email string) ([]int64, error) {
+	contact, err := cfg.GetContact(context.Background(), email)
+	if err != nil || contact == nil {
+		return nil, err
+	}
+
+	return contact.ListIDs, nil
 }
 
+// DeleteContact removes a Brevo contact by email. A contact that doesn't
+// exist is not an error.
+func (cfg *apiCfg) DeleteContact(ctx context.Context, email string) error {
+	endpoint := fmt.Sprintf("https://api.brevo.com/v3/contacts/%s", url.QueryEscape(email))
+
+	req, err := http.NewRequestWithContext(ctx, "DELETE", endpoint, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("api-key", cfg.BrevoAPIKey)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("failed to delete contact: %s, response: %s", resp.Status, body)
+	}
+
+	return nil
+}
 
 func (cfg *apiCfg) CreateContact(contact contact) error {
 	endpoint := "https://api.brevo.com/v3/contacts"
@@ -84,7 +126,6 @@ func (cfg *apiCfg) CreateContact(contact contact) error {
 
 	contact.ListIDs = append(contact.ListIDs, listIDs...)
 	contact.ListIDs = dedupe(contact.ListIDs)
-	contact.Attributes.Sms = "+1" + contact.Attributes.Sms // Ensure SMS is in the correct format
 
 	// Create or update the contact
 	// Marshal the contact to JSON
@@ -119,3 +160,64 @@ func (cfg *apiCfg) CreateContact(contact contact) error {
 
 	return nil
 }
+
+type emailRecipient struct {
+	Email string `json:"email"`
+	Name  string `json:"name,omitempty"`
+}
+
+// SendTemplateEmail sends a Brevo transactional email built from a template.
+// params is exposed to the template as {{ params.* }}. Suppressed addresses
+// are skipped; nothing is sent when every recipient is suppressed.
+func (cfg *apiCfg) SendTemplateEmail(ctx context.Context, to []string, templateID int64, params any) error {
+	to, err := cfg.withoutSuppressed(ctx, to)
+	if err != nil {
+		return err
+	}
+	if len(to) == 0 {
+		return nil
+	}
+	return cfg.sendTemplateEmail(ctx, to, templateID, params)
+}
+
+// sendTemplateEmail sends a template email without checking suppressions.
+func (cfg *apiCfg) sendTemplateEmail(ctx context.Context, to []string, templateID int64, params any) error {
+	type brevoRequest struct {
+		To         []emailRecipient `json:"to"`
+		TemplateID int64            `json:"templateId"`
+		Params     any              `json:"params,omitempty"`
+	}
+
+	reqBody := brevoRequest{
+		TemplateID: templateID,
+		Params:     params,
+	}
+	for _, email := range to {
+		reqBody.To = append(reqBody.To, emailRecipient{Email: email})
+	}
+
+	jsonData, err := json.Marshal(reqBody)
+	if err != nil {
+		return fmt.Errorf("failed to marshal email request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", "https://api.brevo.com/v3/smtp/email", bytes.NewBuffer(jsonData))
+	if err != nil {
+		return fmt.Errorf("failed to create email request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("api-key", cfg.BrevoAPIKey)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send email request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("email request failed with status %s: %s", resp.Status, body)
+	}
+
+	return nil
+}
@@ -2,7 +2,14 @@ package handlers
 
 import (
 	"database/sql"
+	"log"
+	"time"
+
+	"github.com/DiegoGarciaCo/websitesAPI/internal/crm"
 	"github.com/DiegoGarciaCo/websitesAPI/internal/database"
+	"github.com/DiegoGarciaCo/websitesAPI/internal/mortgage"
+	"github.com/DiegoGarciaCo/websitesAPI/internal/notify"
+	"github.com/DiegoGarciaCo/websitesAPI/internal/spam"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 )
 
@@ -20,22 +27,77 @@ type apiCfg struct {
 	S3Region    string
 	BrevoAPIKey string
 	Env         string
+	CRM         crm.Provider
+	Spam        *spam.Checker
+	PhoneRegion string // region used to read phone numbers without a country code
+	Notify      notify.Config
+	// ConsentVersion is recorded with submissions that don't name the
+	// consent wording they showed.
+	ConsentVersion string
+	// Location is the agents' time zone, used for quiet hours and the active
+	// hours of routing rules.
+	Location   *time.Location
+	Newsletter NewsletterConfig
+	// BrevoWebhookSecret is the bearer token Brevo's webhooks send.
+	BrevoWebhookSecret string
+	// MortgageDefaults are the tax and insurance assumptions used where no
+	// location's assumptions match.
+	MortgageDefaults mortgage.Assumptions
+	// AuditLogKey keys the hash chain of the privacy request log.
+	AuditLogKey string
+	// SubjectHashKey keys the hashes that stand in for a privacy subject's
+	// email and phone.
+	SubjectHashKey string
+	// ShowingListID is the Brevo list showing requests join; 0 adds them
+	// to no list.
+	ShowingListID int64
+	// OpenHouseListID is the Brevo list open house visitors join; 0 adds
+	// them to no list.
+	OpenHouseListID int64
+
+	rescore rescoreJob
 }
 
-func NewConfig(port, secret, appPassword, fubkey, system, systemKey, s3Bucket, s3Region, brevoAPIKey, env string, db *database.Queries, sqlDB *sql.DB, s3Client *s3.Client) *apiCfg {
+func NewConfig(port, secret, appPassword, fubkey, system, systemKey, s3Bucket, s3Region, brevoAPIKey, brevoWebhookSecret, env, crmProvider, crmWebhookURL, crmWebhookSecret, phoneRegion, consentVersion, auditLogKey, subjectHashKey string, spamConfig spam.Config, notifyConfig notify.Config, location *time.Location, showingListID, openHouseListID int64, newsletterConfig NewsletterConfig, mortgageDefaults mortgage.Assumptions, db *database.Queries, sqlDB *sql.DB, s3Client *s3.Client) *apiCfg {
+	var provider crm.Provider
+	switch crmProvider {
+	case "webhook":
+		provider = crm.NewWebhook(crmWebhookURL, crmWebhookSecret)
+	case "noop":
+		provider = crm.NewNoop()
+	case "fub", "":
+		provider = crm.NewFollowUpBoss(fubkey, system, systemKey)
+	default:
+		log.Fatalf("Unknown CRM provider: %s", crmProvider)
+	}
+
 	return &apiCfg{
-		Port:        port,
-		Secret:      secret,
-		DB:          db,
-		SQLDB:       sqlDB,
-		AppPassword: appPassword,
-		FUBKey:      fubkey,
-		System:      system,
-		SystemKey:   systemKey,
-		S3Client:    s3Client,
-		S3Bucket:    s3Bucket,
-		S3Region:    s3Region,
-		BrevoAPIKey: brevoAPIKey,
-		Env:         env,
+		Port:           port,
+		Secret:         secret,
+		DB:             db,
+		SQLDB:          sqlDB,
+		AppPassword:    appPassword,
+		FUBKey:         fubkey,
+		System:         system,
+		SystemKey:      systemKey,
+		S3Client:       s3Client,
+		S3Bucket:       s3Bucket,
+		S3Region:       s3Region,
+		BrevoAPIKey:    brevoAPIKey,
+		Env:            env,
+		CRM:            provider,
+		Spam:           spam.NewChecker(spamConfig),
+		PhoneRegion:    phoneRegion,
+		Notify:         notifyConfig,
+		ConsentVersion: consentVersion,
+		Location:       location,
+		Newsletter:     newsletterConfig,
+
+		BrevoWebhookSecret: brevoWebhookSecret,
+		MortgageDefaults:   mortgageDefaults,
+		AuditLogKey:        auditLogKey,
+		SubjectHashKey:     subjectHashKey,
+		ShowingListID:      showingListID,
+		OpenHouseListID:    openHouseListID,
 	}
 }
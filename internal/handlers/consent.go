@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/DiegoGarciaCo/websitesAPI/internal/database"
+	"github.com/DiegoGarciaCo/websitesAPI/internal/validation"
+)
+
+const consentPurposeMarketing = "marketing"
+
+type consentRecordResponse struct {
+	ID          string    `json:"id"`
+	LeadID      string    `json:"leadId,omitempty"`
+	Email       string    `json:"email"`
+	Purpose     string    `json:"purpose"`
+	Consented   bool      `json:"consented"`
+	TextVersion string    `json:"textVersion"`
+	IPAddress   string    `json:"ipAddress"`
+	UserAgent   string    `json:"userAgent"`
+	Route       string    `json:"route"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+func newConsentRecordResponse(r database.ConsentRecord) consentRecordResponse {
+	res := consentRecordResponse{
+		ID:          r.ID.String(),
+		Email:       r.Email,
+		Purpose:     r.Purpose,
+		Consented:   r.Consented,
+		TextVersion: r.TextVersion,
+		IPAddress:   r.IpAddress,
+		UserAgent:   r.UserAgent,
+		Route:       r.Route,
+		CreatedAt:   r.CreatedAt,
+	}
+	if r.LeadID.Valid {
+		res.LeadID = r.LeadID.UUID.String()
+	}
+	return res
+}
+
+// ExportConsent returns every consent record for ?email= as JSON, or as a
+// CSV download with ?format=csv. Cells the client supplied are escaped
+// against formula injection.
+func (cfg *apiCfg) ExportConsent(w http.ResponseWriter, req *http.Request) {
+	query := req.URL.Query()
+
+	email, err := validation.Email(query.Get("email"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "A valid email is required", err)
+		return
+	}
+
+	records, err := cfg.DB.ListConsentRecordsByEmail(req.Context(), email)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Something went wrong", err)
+		return
+	}
+
+	res := make([]consentRecordResponse, len(records))
+	for i, r := range records {
+		res[i] = newConsentRecordResponse(r)
+	}
+
+	if query.Get("format") != "csv" {
+		respondWithJSON(w, http.StatusOK, res)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set("Content-Disposition", `attachment; filename="consent.csv"`)
+	w.WriteHeader(http.StatusOK)
+
+	writer := csv.NewWriter(w)
+	writer.Write([]string{"id", "lead_id", "email", "purpose", "consented", "text_version", "ip_address", "user_agent", "route", "created_at"})
+	for _, r := range res {
+		writer.Write([]string{
+			r.ID,
+			r.LeadID,
+			csvSafe(r.Email),
+			r.Purpose,
+			strconv.FormatBool(r.Consented),
+			r.TextVersion,
+			r.IPAddress,
+			csvSafe(r.UserAgent),
+			csvSafe(r.Route),
+			r.CreatedAt.Format(time.RFC3339),
+		})
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		log.Printf("Error writing consent export: %s", err)
+	}
+}
@@ -0,0 +1,129 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/DiegoGarciaCo/websitesAPI/internal/crm"
+	"github.com/DiegoGarciaCo/websitesAPI/internal/database"
+	"github.com/DiegoGarciaCo/websitesAPI/internal/validation"
+)
+
+const maxCRMWebhookBytes = 1 << 20
+
+// fubStageStatuses maps Follow Up Boss's default stages, lowercased, to
+// lead statuses. Leads of people in other stages keep their status; the
+// stage itself is always stored.
+var fubStageStatuses = map[string]string{
+	"lead":              "new",
+	"attempted contact": "contacted",
+	"contacted":         "contacted",
+	"nurture":           "contacted",
+	"unresponsive":      "contacted",
+	"hot prospect":      "qualified",
+	"active client":     "qualified",
+	"pending":           "qualified",
+	"under contract":    "qualified",
+	"closed":            "closed",
+	"past client":       "closed",
+	"trash":             "closed",
+}
+
+// fubWebhookEvents are the Follow Up Boss webhook events that sync leads.
+var fubWebhookEvents = map[string]bool{
+	"peopleCreated":      true,
+	"peopleUpdated":      true,
+	"peopleStageUpdated": true,
+}
+
+// FUBWebhook receives Follow Up Boss people webhooks. For each changed
+// person it fetches their current record and copies their stage, and the
+// matching status, to every lead with the same person, email or phone.
+// Webhooks are verified with the FUB-Signature header.
+func (cfg *apiCfg) FUBWebhook(w http.ResponseWriter, req *http.Request) {
+	type reqParams struct {
+		EventID     string  `json:"eventId"`
+		Event       string  `json:"event"`
+		ResourceIDs []int64 `json:"resourceIds"`
+	}
+
+	fetcher, ok := cfg.CRM.(crm.PersonFetcher)
+	if !ok {
+		respondWithError(w, http.StatusNotFound, "Not found", nil)
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, req.Body, maxCRMWebhookBytes))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Could not read request body", err)
+		return
+	}
+	if !crm.VerifyFUBSignature(body, req.Header.Get("FUB-Signature"), cfg.SystemKey) {
+		respondWithError(w, http.StatusUnauthorized, "Invalid signature", nil)
+		return
+	}
+
+	params := reqParams{}
+	if err := json.Unmarshal(body, &params); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload", err)
+		return
+	}
+	if !fubWebhookEvents[params.Event] {
+		respondWithJSON(w, http.StatusNoContent, nil)
+		return
+	}
+
+	ctx := req.Context()
+	for _, id := range params.ResourceIDs {
+		person, err := fetcher.GetPerson(ctx, id)
+		if errors.Is(err, crm.ErrPersonNotFound) {
+			continue
+		}
+		if err != nil {
+			// Follow Up Boss retries webhooks that fail
+			respondWithError(w, http.StatusBadGateway, "Could not fetch the person", err)
+			return
+		}
+
+		updated, err := cfg.DB.SyncLeadsFromCRM(ctx, cfg.crmSyncParams(person))
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Something went wrong", err)
+			return
+		}
+		if updated > 0 {
+			log.Printf("Synced %d leads from FUB person %d (%s, event %s)", updated, person.ID, person.Stage, params.EventID)
+		}
+	}
+
+	respondWithJSON(w, http.StatusNoContent, nil)
+}
+
+// crmSyncParams matches a CRM person to leads by ID, email and phone, with
+// phones normalized the way submissions store them.
+func (cfg *apiCfg) crmSyncParams(person crm.Person) database.SyncLeadsFromCRMParams {
+	params := database.SyncLeadsFromCRMParams{
+		PersonID: person.ID,
+		Stage:    truncate(person.Stage, 100),
+		Emails:   []string{},
+		Phones:   []string{},
+	}
+	if status, ok := fubStageStatuses[strings.ToLower(person.Stage)]; ok {
+		params.Status = sql.NullString{String: status, Valid: true}
+	}
+	for _, email := range person.Emails {
+		if email = strings.ToLower(strings.TrimSpace(email)); email != "" {
+			params.Emails = append(params.Emails, email)
+		}
+	}
+	for _, phone := range person.Phones {
+		if phone, err := validation.Phone(phone, cfg.PhoneRegion); err == nil {
+			params.Phones = append(params.Phones, phone)
+		}
+	}
+	return params
+}
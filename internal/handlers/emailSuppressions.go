@@ -0,0 +1,231 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/DiegoGarciaCo/websitesAPI/internal/database"
+	"github.com/DiegoGarciaCo/websitesAPI/internal/emailevents"
+	"github.com/google/uuid"
+)
+
+const maxEmailEventBytes = 1 << 20
+
+// emailSuppression returns why email gets no more email, or "" when it
+// isn't suppressed. The suppression of an erased person is found by the
+// keyed hash that replaced their email.
+func (cfg *apiCfg) emailSuppression(ctx context.Context, email string) (string, error) {
+	s, err := cfg.DB.GetEmailSuppression(ctx, database.GetEmailSuppressionParams{
+		Email:  email,
+		Erased: cfg.erasedEmail(email),
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to check email suppression: %w", err)
+	}
+	return s.Reason, nil
+}
+
+// withoutSuppressed drops suppressed addresses, including those of erased
+// people, from emails.
+func (cfg *apiCfg) withoutSuppressed(ctx context.Context, emails []string) ([]string, error) {
+	lower := make([]string, len(emails))
+	erased := make([]string, len(emails))
+	for i, email := range emails {
+		lower[i] = strings.ToLower(email)
+		erased[i] = cfg.erasedEmail(email)
+	}
+	suppressed, err := cfg.DB.ListSuppressedEmails(ctx, append(slices.Clone(lower), erased...))
+	if err != nil {
+		return nil, fmt.Errorf("failed to check email suppressions: %w", err)
+	}
+
+	out := []string{}
+	for i, email := range emails {
+		if !slices.Contains(suppressed, lower[i]) && !slices.Contains(suppressed, erased[i]) {
+			out = append(out, email)
+		}
+	}
+	return out, nil
+}
+
+// BrevoEvents receives Brevo's marketing and transactional webhooks. Every
+// unsubscribe, hard bounce, spam complaint and delivery is recorded; the
+// first three suppress the address. Repeated deliveries of an event are
+// ignored, and an older event never overrides a newer suppression.
+func (cfg *apiCfg) BrevoEvents(w http.ResponseWriter, req *http.Request) {
+	if cfg.BrevoWebhookSecret == "" {
+		respondWithError(w, http.StatusNotFound, "Not found", nil)
+		return
+	}
+	if !emailevents.Authorized(req.Header, cfg.BrevoWebhookSecret) {
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized", nil)
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, req.Body, maxEmailEventBytes))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Could not read request body", err)
+		return
+	}
+
+	events, err := emailevents.Parse(body, time.Now())
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload", err)
+		return
+	}
+
+	// Events and their suppressions are saved together, so an event is never
+	// recorded without the suppression it causes. Brevo retries failed
+	// callbacks.
+	ctx := req.Context()
+	if err := cfg.saveEmailEvents(ctx, events); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Something went wrong", err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusNoContent, nil)
+}
+
+func (cfg *apiCfg) saveEmailEvents(ctx context.Context, events []emailevents.Event) error {
+	tx, err := cfg.SQLDB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	qtx := cfg.DB.WithTx(tx)
+
+	var suppressed []emailevents.Event
+	for _, e := range events {
+		created, err := qtx.CreateEmailEvent(ctx, database.CreateEmailEventParams{
+			Email:      e.Email,
+			Event:      e.Type,
+			MessageID:  e.MessageID,
+			Payload:    e.Raw,
+			OccurredAt: e.OccurredAt,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to record email event: %w", err)
+		}
+		if created == 0 || !emailevents.Suppresses(e.Type) {
+			continue
+		}
+
+		err = qtx.UpsertEmailSuppression(ctx, database.UpsertEmailSuppressionParams{
+			Email:        e.Email,
+			Reason:       e.Type,
+			SuppressedAt: e.OccurredAt,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to suppress email: %w", err)
+		}
+		suppressed = append(suppressed, e)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	for _, e := range suppressed {
+		log.Printf("Suppressed %s after %s", e.Email, e.Type)
+	}
+	return nil
+}
+
+type emailSuppressionResponse struct {
+	ID           string    `json:"id"`
+	Email        string    `json:"email"`
+	Reason       string    `json:"reason"`
+	SuppressedAt time.Time `json:"suppressedAt"`
+}
+
+func newEmailSuppressionResponse(s database.EmailSuppression) emailSuppressionResponse {
+	return emailSuppressionResponse{
+		ID:           s.ID.String(),
+		Email:        s.Email,
+		Reason:       s.Reason,
+		SuppressedAt: s.SuppressedAt,
+	}
+}
+
+func (cfg *apiCfg) ListEmailSuppressions(w http.ResponseWriter, req *http.Request) {
+	type resParams struct {
+		Suppressions []emailSuppressionResponse `json:"suppressions"`
+		Total        int64                      `json:"total"`
+		Page         int                        `json:"page"`
+		PageSize     int                        `json:"pageSize"`
+	}
+
+	query := req.URL.Query()
+
+	page, err := queryInt(query, "page", 1)
+	if err != nil || page < 1 {
+		respondWithError(w, http.StatusBadRequest, "Invalid page", err)
+		return
+	}
+	pageSize, err := queryInt(query, "pageSize", defaultLeadPageSize)
+	if err != nil || pageSize < 1 || pageSize > maxLeadPageSize {
+		respondWithError(w, http.StatusBadRequest, "Invalid pageSize", err)
+		return
+	}
+
+	suppressions, err := cfg.DB.ListEmailSuppressions(req.Context(), database.ListEmailSuppressionsParams{
+		Limit:  int32(pageSize),
+		Offset: int32((page - 1) * pageSize),
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Something went wrong", err)
+		return
+	}
+
+	total, err := cfg.DB.CountEmailSuppressions(req.Context())
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Something went wrong", err)
+		return
+	}
+
+	res := resParams{
+		Suppressions: make([]emailSuppressionResponse, len(suppressions)),
+		Total:        total,
+		Page:         page,
+		PageSize:     pageSize,
+	}
+	for i, s := range suppressions {
+		res.Suppressions[i] = newEmailSuppressionResponse(s)
+	}
+
+	respondWithJSON(w, http.StatusOK, res)
+}
+
+// DeleteEmailSuppression lifts a suppression, for example after a bounced
+// mailbox has been fixed. The address is suppressed again if Brevo reports
+// a new event.
+func (cfg *apiCfg) DeleteEmailSuppression(w http.ResponseWriter, req *http.Request) {
+	UUID, err := uuid.Parse(req.PathValue("id"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid UUID", err)
+		return
+	}
+
+	deleted, err := cfg.DB.DeleteEmailSuppression(req.Context(), UUID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Something went wrong", err)
+		return
+	}
+	if deleted == 0 {
+		respondWithError(w, http.StatusNotFound, "Suppression not found", nil)
+		return
+	}
+
+	respondWithJSON(w, http.StatusNoContent, nil)
+}
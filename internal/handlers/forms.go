@@ -1,12 +1,14 @@
 package handlers
 
 import (
-	"bytes"
 	"encoding/json"
+	"errors"
 	"log"
 	"net/http"
 	"strconv"
-	"strings"
+
+	"github.com/DiegoGarciaCo/websitesAPI/internal/mortgage"
+	"github.com/DiegoGarciaCo/websitesAPI/internal/validation"
 )
 
 type data struct {
@@ -19,42 +21,49 @@ type data struct {
 	MonthlyPMI   float64
 	Taxes        float64
 	Insurance    float64
+	Program      string
+	UpfrontFee   float64 // financed into the loan
+	// The tax and insurance assumptions behind Taxes and Insurance
+	TaxRate           float64
+	AnnualInsurance   float64
+	AssumptionsSource string
 }
 
+// The handlers below are adapters that translate the original form payloads
+// into a leadSubmission for submitLead. Field errors are renamed back to the
+// names the original forms use.
+
+// renameLeadFields renames the fields of a validation error and passes any
+// other error through.
+func renameLeadFields(err error, names map[string]string) error {
+	var errs validation.Errors
+	if errors.As(err, &errs) {
+		return errs.Rename(names)
+	}
+	return err
+}
 
+// CalculateMortgage is the optional "email me this" step of the calculator:
+// it captures a lead and emails the breakdown that MortgageBreakdown returns.
 func (cfg *apiCfg) CalculateMortgage(w http.ResponseWriter, req *http.Request) {
 	type reqParams struct {
 		Price       string `json:"price"`
 		Interest    string `json:"interest"`
 		Years       string `json:"years"`
 		DownPayment string `json:"downPayment"`
+		Program     string `json:"program"`
+		CreditScore string `json:"creditScore"` // optional
+		State       string `json:"state"`
+		County      string `json:"county"`
+		Zip         string `json:"zip"`
 		FirstName   string `json:"firstName"`
 		LastName    string `json:"lastName"`
 		Email       string `json:"email"`
 		Number      string `json:"number"`
 		Subscribed  bool   `json:"subscribed"`
-	}
-
-	// Follow up Boss API payload structures
-	type Email struct {
-		Value string `json:"value"`
-		Type  string `json:"type"`
-	}
 
-	type Phone struct {
-		Value string `json:"value"`
-		Type  string `json:"type"`
-	}
-	type Person struct {
-		FirstName string  `json:"firstName"`
-		LastName  string  `json:"lastName"`
-		Emails    []Email `json:"emails"`
-		Phones    []Phone `json:"phones"`
-	}
-	type reqPayload struct {
-		Source string `json:"source"`
-		Type   string `json:"type"`
-		Person Person `json:"person"`
+		VASubsequentUse bool `json:"vaSubsequentUse"`
+		leadAttribution
 	}
 
 	// Decode request
@@ -67,181 +76,98 @@ func (cfg *apiCfg) CalculateMortgage(w http.ResponseWriter, req *http.Request) {
 		return
 	}
 
-	url := "https://api.followupboss.com/v1/events"
-
-	payload := reqPayload{
-		Source: cfg.System,
-		Type:   "Property Inquiry",
-		Person: Person{
-			FirstName: formData.FirstName,
-			LastName:  formData.LastName,
-			Emails: []Email{
-				{
-					Value: formData.Email,
-					Type:  "Personal",
-				},
-			},
-			Phones: []Phone{
-				{
-					Value: formData.Number,
-					Type:  "Mobile",
-				},
-			},
-		},
+	errs := validation.Errors{}
+	price, err := strconv.ParseFloat(formData.Price, 64)
+	if err != nil {
+		errs.Add("price", "Enter a valid price")
 	}
-
-	payloadJSON, err := json.Marshal(payload)
+	interest, err := strconv.ParseFloat(formData.Interest, 64)
 	if err != nil {
-		log.Printf("Error marshalling JSON: %s", err)
-		respondWithError(w, http.StatusInternalServerError, "Could not marshal JSON", err)
-		return
+		errs.Add("interest", "Enter a valid interest rate")
 	}
-
-	r, err := http.NewRequest("POST", url, bytes.NewBuffer(payloadJSON))
+	years, err := strconv.Atoi(formData.Years)
 	if err != nil {
-		log.Printf("Error creating request: %s", err)
-		respondWithError(w, http.StatusInternalServerError, "Could not create request", err)
-		return
+		errs.Add("years", "Enter a valid number of years")
 	}
-
-	r.Header.Set("Content-Type", "application/json")
-	r.Header.Set("X-System", cfg.System)
-	r.Header.Set("X-System-Key", cfg.SystemKey)
-	r.SetBasicAuth(cfg.FUBKey, "")
-
-	client := &http.Client{}
-	resp, err := client.Do(r)
+	downPayment, err := strconv.ParseFloat(formData.DownPayment, 64)
 	if err != nil {
-		log.Printf("Error sending request: %s", err)
-		respondWithError(w, http.StatusInternalServerError, "Could not send request", err)
-		return
+		errs.Add("downPayment", "Enter a valid down payment")
 	}
-	defer resp.Body.Close()
-
-
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		log.Printf("Error response from Follow Up Boss: %s", resp.Status)
-		respondWithError(w, http.StatusInternalServerError, "Could not send request", err)
+	var creditScore int
+	if formData.CreditScore != "" {
+		creditScore, err = strconv.Atoi(formData.CreditScore)
+		if err != nil {
+			errs.Add("creditScore", "Enter a valid credit score")
+		}
+	}
+	if len(errs) > 0 {
+		respondWithValidationErrors(w, errs)
 		return
 	}
 
-	// Create contact in Brevo
-	contact := contact{
-		Email: formData.Email,
-		Attributes: attributes{
-			FirstName: formData.FirstName,
-			LastName:  formData.LastName,
-			Sms:       formData.Number,
+	details, err := json.Marshal(mortgageDetails{
+		Price:       price,
+		Interest:    interest,
+		Years:       years,
+		DownPayment: downPayment,
+		Program:     formData.Program,
+		CreditScore: creditScore,
+		Location: mortgage.Location{
+			State:  formData.State,
+			County: formData.County,
+			Zip:    formData.Zip,
 		},
-		ListIDs: []int64{3},
-		UpdateEnabled: true,
-	}
 
-	err = cfg.CreateContact(contact)
+		VASubsequentUse: formData.VASubsequentUse,
+	})
 	if err != nil {
-		log.Printf("Error creating contact in Brevo: %s", err)
-		respondWithError(w, http.StatusInternalServerError, "Could not create contact in Brevo", err)
+		respondWithError(w, http.StatusInternalServerError, "Could not marshal JSON", err)
 		return
 	}
 
-	// Send Email Response
-	go func() {
-		price, err := strconv.Atoi(formData.Price)
-		if err != nil {
-			log.Printf("Error converting Price to integer: %v", err)
-			return
-		}
-		tax := float64(price) * 0.0211
-		var pmi float64
-		var monthlyPMI float64
-
-		downPaymentPercent, err := strconv.ParseFloat(formData.DownPayment, 64)
-		if err != nil {
-			log.Printf("Error converting DownPayment to integer: %v", err)
-			return
-		}
-
-		downPayment := float64(price) * (downPaymentPercent / 100)
-		if float64(downPayment)/float64(price) < 0.2 {
-			pmi = 0.0075
-			price, err := strconv.Atoi(formData.Price)
-			if err != nil {
-				log.Printf("Error converting Price to integer: %v", err)
-				return
-			}
-			monthlyPMI = (float64(price) * pmi) / 12
-		} else {
-			pmi = 0.0
-			monthlyPMI = 0.0
-		}
+	_, err = cfg.submitLead(req.Context(), newSubmissionMeta(req), leadSubmission{
+		Kind:       leadTypeMortgage,
+		FirstName:  formData.FirstName,
+		LastName:   formData.LastName,
+		Email:      formData.Email,
+		Phone:      formData.Number,
+		Subscribed: formData.Subscribed,
+		Details:    details,
 
-		interest, err := strconv.ParseFloat(formData.Interest, 64)
-		if err != nil {
-			log.Printf("Error converting Interest to float64: %v", err)
-			return
-		}
+		leadAttribution: formData.leadAttribution,
+	})
+	if err != nil {
+		respondWithLeadError(w, renameLeadFields(err, map[string]string{
+			"phone":               "number",
+			"details.price":       "price",
+			"details.interest":    "interest",
+			"details.years":       "years",
+			"details.downPayment": "downPayment",
+			"details.program":     "program",
+			"details.creditScore": "creditScore",
+			"details.state":       "state",
+			"details.county":      "county",
+			"details.zip":         "zip",
+		}))
+		return
+	}
 
-		years, err := strconv.Atoi(formData.Years)
-		if err != nil {
-			log.Printf("Error converting Years to integer: %v", err)
-			return
-		}
-		payment := CalculateMortgagePayment(float64(price)-float64(downPayment), interest, years)
-		totalPayment := payment + monthlyPMI + (tax / 12) + (2119 / 12)
-		if err = cfg.SendMortgageCalculation(data{
-			Price:        price,
-			Interest:     interest * 100,
-			Years:        years,
-			DownPayment:  downPayment,
-			Payment:      payment,
-			TotalPayment: totalPayment,
-			MonthlyPMI:   monthlyPMI,
-			Taxes:        tax / 12,
-			Insurance:    2119 / 12,
-		}, formData.Email, cfg.AppPassword); err != nil {
-			log.Printf("Error sending mortgage calculation email: %v", err)
-			return
-		}
-	}()
 	respondWithJSON(w, http.StatusNoContent, nil)
 }
 
 func (cfg *apiCfg) Estimate(w http.ResponseWriter, req *http.Request) {
 	type reqParams struct {
-		Name    string `json:"name"`
-		Address string `json:"address"`
-		City    string `json:"city"`
-		State   string `json:"state"`
-		Email   string `json:"email"`
-		Number  string `json:"number"`
-	}
-
-	type Email struct {
-		Value string `json:"value"`
-		Type  string `json:"type"`
-	}
-
-	type Phone struct {
-		Value string `json:"value"`
-		Type  string `json:"type"`
-	}
-	type Address struct {
-		Type   string `json:"type"`
-		Street string `json:"street"`
-		City   string `json:"city"`
-		State  string `json:"state"`
-	}
-	type Person struct {
-		FirstName string    `json:"firstName"`
-		LastName  string    `json:"lastName"`
-		Emails    []Email   `json:"emails"`
-		Phones    []Phone   `json:"phones"`
-		Addresses []Address `json:"addresses"`
-	}
-	type reqPayload struct {
-		Source string `json:"source"`
-		Type   string `json:"type"`
-		Person Person `json:"person"`
+		Name     string `json:"name"`
+		Address  string `json:"address"`
+		City     string `json:"city"`
+		State    string `json:"state"`
+		Zip      string `json:"zip"`
+		Timeline string `json:"timeline"`
+		Email    string `json:"email"`
+		Number   string `json:"number"`
+		// Subscribed adds the seller to the seller inquiry list in Brevo
+		Subscribed bool `json:"subscribed"`
+		leadAttribution
 	}
 
 	var formData reqParams
@@ -252,90 +178,44 @@ func (cfg *apiCfg) Estimate(w http.ResponseWriter, req *http.Request) {
 		return
 	}
 
-	fisrtName := strings.Split(formData.Name, " ")[0]
-	lastName := strings.Split(formData.Name, " ")[1]
-
-	url := "https://api.followupboss.com/v1/events"
-
-	payload := reqPayload{
-		Source: "Realtor Website",
-		Type:   "Seller Inquiry",
-		Person: Person{
-			FirstName: fisrtName,
-			LastName:  lastName,
-			Emails: []Email{
-				{
-					Value: formData.Email,
-					Type:  "personal",
-				},
-			},
-			Phones: []Phone{
-				{
-					Value: formData.Number,
-					Type:  "personal",
-				},
-			},
-			Addresses: []Address{
-				{
-					Type:   "home",
-					Street: formData.Address,
-					City:   formData.City,
-					State:  formData.State,
-				},
-			},
-		},
-	}
-
-	jsonPayload, err := json.Marshal(payload)
-	if err != nil {
-		respondWithError(w, http.StatusBadRequest, "Invalid request payload", err)
-		return
-	}
-
-	req, err = http.NewRequest("POST", url, bytes.NewBuffer(jsonPayload))
-	if err != nil {
-		respondWithError(w, http.StatusBadRequest, "Invalid request payload", err)
-		return
-	}
+	name := validation.ParseName(formData.Name)
 
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("X-System", cfg.System)
-	req.Header.Set("X-System-Key", cfg.SystemKey)
-	req.SetBasicAuth(cfg.FUBKey, "")
-
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	details, err := json.Marshal(sellerEstimateDetails{
+		Address:  formData.Address,
+		City:     formData.City,
+		State:    formData.State,
+		Zip:      formData.Zip,
+		Timeline: formData.Timeline,
+	})
 	if err != nil {
 		respondWithError(w, http.StatusBadRequest, "Invalid request payload", err)
 		return
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		respondWithError(w, http.StatusBadRequest, "Invalid request payload", err)
-		return
-	}
 
-	// Create contact in Brevo
-	contact := contact{
-		Email: formData.Email,
-		Attributes: attributes{
-			FirstName: fisrtName,
-			LastName:  lastName,
-			Sms:       formData.Number,
-		},
-		ListIDs: []int64{4},
-		UpdateEnabled: true,
-	}
+	_, err = cfg.submitLead(req.Context(), newSubmissionMeta(req), leadSubmission{
+		Kind:       leadTypeSellerEstimate,
+		FirstName:  name.First,
+		LastName:   name.LastWithSuffix(),
+		Email:      formData.Email,
+		Phone:      formData.Number,
+		Subscribed: formData.Subscribed,
+		Details:    details,
 
-	err = cfg.CreateContact(contact)
+		leadAttribution: formData.leadAttribution,
+	})
 	if err != nil {
-		log.Printf("Error creating contact in Brevo: %s", err)
-		respondWithError(w, http.StatusInternalServerError, "Could not create contact in Brevo", err)
+		respondWithLeadError(w, renameLeadFields(err, map[string]string{
+			"firstName":        "name",
+			"lastName":         "name",
+			"phone":            "number",
+			"details.address":  "address",
+			"details.city":     "city",
+			"details.state":    "state",
+			"details.timeline": "timeline",
+		}))
 		return
 	}
 
-
 	respondWithJSON(w, http.StatusOK, nil)
 }
 
@@ -347,29 +227,7 @@ func (cfg *apiCfg) SubmitForm(w http.ResponseWriter, req *http.Request) {
 		Number     string `json:"number"`
 		Message    string `json:"message"`
 		Subscribed bool   `json:"subscribed"`
-	}
-
-	// Person represents the lead’s details within the payload
-	type Email struct {
-		Value string `json:"value"`
-		Type  string `json:"type"`
-	}
-
-	type Phone struct {
-		Value string `json:"value"`
-		Type  string `json:"type"`
-	}
-	type Person struct {
-		FirstName string  `json:"firstName"`
-		LastName  string  `json:"lastName"`
-		Emails    []Email `json:"emails"`
-		Phones    []Phone `json:"phones"`
-	}
-	type reqPayload struct {
-		Source  string `json:"source"`
-		Type    string `json:"type"`
-		Message string `json:"message"`
-		Person  Person `json:"person"`
+		leadAttribution
 	}
 
 	formData := reqParams{}
@@ -379,81 +237,89 @@ func (cfg *apiCfg) SubmitForm(w http.ResponseWriter, req *http.Request) {
 		respondWithError(w, http.StatusInternalServerError, "Could not decode request", err)
 		return
 	}
-	url := "https://api.followupboss.com/v1/events"
-
-	payload := reqPayload{
-		Source:  cfg.System,
-		Type:    "General Inquiry",
-		Message: formData.Message,
-		Person: Person{
-			FirstName: formData.FirstName,
-			LastName:  formData.LastName,
-			Emails: []Email{
-				{
-					Value: formData.Email,
-					Type:  "Personal",
-				},
-			},
-			Phones: []Phone{
-				{
-					Value: formData.Number,
-					Type:  "Mobile",
-				},
-			},
-		},
-	}
-
-	payloadJSON, err := json.Marshal(payload)
-	if err != nil {
-		respondWithError(w, http.StatusInternalServerError, "Could not marshal JSON", err)
-		return
-	}
 
+	_, err = cfg.submitLead(req.Context(), newSubmissionMeta(req), leadSubmission{
+		Kind:       leadTypeContact,
+		FirstName:  formData.FirstName,
+		LastName:   formData.LastName,
+		Email:      formData.Email,
+		Phone:      formData.Number,
+		Message:    formData.Message,
+		Subscribed: formData.Subscribed,
 
-	r, err := http.NewRequest("POST", url, bytes.NewBuffer(payloadJSON))
+		leadAttribution: formData.leadAttribution,
+	})
 	if err != nil {
-		respondWithError(w, http.StatusInternalServerError, "Could not create request", err)
+		respondWithLeadError(w, renameLeadFields(err, map[string]string{
+			"phone": "number",
+		}))
 		return
 	}
 
-	r.Header.Set("Content-Type", "application/json")
-	r.Header.Set("X-System", cfg.System)
-	r.Header.Set("X-System-Key", cfg.SystemKey)
-	r.SetBasicAuth(cfg.FUBKey, "")
+	respondWithJSON(w, http.StatusNoContent, nil)
+}
 
-	client := &http.Client{}
-	resp, err := client.Do(r)
-	if err != nil {
-		respondWithError(w, http.StatusInternalServerError, "Could not send request", err)
-		return
+// RequestShowing takes a buyer's request to tour a specific property.
+func (cfg *apiCfg) RequestShowing(w http.ResponseWriter, req *http.Request) {
+	type reqParams struct {
+		FirstName   string          `json:"firstName"`
+		LastName    string          `json:"lastName"`
+		Email       string          `json:"email"`
+		Number      string          `json:"number"`
+		Message     string          `json:"message"`
+		Subscribed  bool            `json:"subscribed"`
+		Address     string          `json:"address"`
+		City        string          `json:"city"`
+		State       string          `json:"state"`
+		Zip         string          `json:"zip"`
+		MLSNumber   string          `json:"mlsNumber"`
+		Windows     []showingWindow `json:"windows"`
+		PreApproved bool            `json:"preApproved"`
+		leadAttribution
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		respondWithError(w, http.StatusInternalServerError, "Could not send request", err)
+	formData := reqParams{}
+	decoder := json.NewDecoder(req.Body)
+	if err := decoder.Decode(&formData); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload", err)
 		return
 	}
 
-
-	// Create contact in Brevo
-	contact := contact{
-		Email: formData.Email,
-		Attributes: attributes{
-			FirstName: formData.FirstName,
-			LastName:  formData.LastName,
-			Sms:       formData.Number,
-		},
-		ListIDs: []int64{6},
-		UpdateEnabled: true,
+	details, err := json.Marshal(showingDetails{
+		Address:     formData.Address,
+		City:        formData.City,
+		State:       formData.State,
+		Zip:         formData.Zip,
+		MLSNumber:   formData.MLSNumber,
+		Windows:     formData.Windows,
+		PreApproved: formData.PreApproved,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload", err)
+		return
 	}
 
+	_, err = cfg.submitLead(req.Context(), newSubmissionMeta(req), leadSubmission{
+		Kind:       leadTypeShowing,
+		FirstName:  formData.FirstName,
+		LastName:   formData.LastName,
+		Email:      formData.Email,
+		Phone:      formData.Number,
+		Message:    formData.Message,
+		Subscribed: formData.Subscribed,
+		Details:    details,
 
-	err = cfg.CreateContact(contact)
+		leadAttribution: formData.leadAttribution,
+	})
 	if err != nil {
-		log.Printf("Error creating contact in Brevo: %s", err)
-		respondWithError(w, http.StatusInternalServerError, "Could not create contact in Brevo", err)
+		respondWithLeadError(w, renameLeadFields(err, map[string]string{
+			"phone":             "number",
+			"details.address":   "address",
+			"details.mlsNumber": "mlsNumber",
+			"details.windows":   "windows",
+		}))
 		return
 	}
 
-	respondWithJSON(w, http.StatusNoContent, reqParams{})
+	respondWithJSON(w, http.StatusNoContent, nil)
 }
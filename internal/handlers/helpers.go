@@ -2,15 +2,14 @@ package handlers
 
 import (
 	"context"
-	"io"
 	"crypto/rand"
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"io"
 	"log"
-	"math"
 	"net/http"
 	"strings"
 )
@@ -111,24 +110,6 @@ func deleteFromS3(cfg *apiCfg, ctx context.Context, imageURL string) error {
 	return nil
 }
 
-func CalculateMortgagePayment(P float64, annualRate float64, loanTerm int) float64 {
-	// Convert annual interest rate to monthly
-	monthlyRate := (annualRate / 100) / 12
-
-	// Total number of payments (months)
-	n := loanTerm * 12
-
-	// Mortgage payment formula
-	if monthlyRate == 0 {
-		// In case of 0% interest rate (special case)
-		return P / float64(n)
-	}
-
-	// Mortgage payment formula
-	m := P * (monthlyRate * math.Pow(1+monthlyRate, float64(n))) / (math.Pow(1+monthlyRate, float64(n)) - 1)
-	return math.Round(m*100) / 100
-}
-
 func (cfg *apiCfg) SendMortgageCalculation(data data, to, password string) error {
 	// Request structures
 	type To struct {
@@ -139,31 +120,36 @@ func (cfg *apiCfg) SendMortgageCalculation(data data, to, password string) error
 		Name  string `json:"name"`
 	}
 	type params struct {
-		Price        int     `json:"Price"`
-		Interest     float64 `json:"Interest"`
-		Years        int     `json:"Years"`
-		DownPayment  float64 `json:"DownPayment"`
-		Payment      float64 `json:"Payment"`
-		TotalPayment float64 `json:"TotalPayment"`
-		MonthlyPMI   float64 `json:"MonthlyPMI"`
-		Taxes        float64 `json:"Taxes"`
-		Insurance    float64 `json:"Insurance"`
+		Price             int     `json:"Price"`
+		Interest          float64 `json:"Interest"`
+		Years             int     `json:"Years"`
+		DownPayment       float64 `json:"DownPayment"`
+		Payment           float64 `json:"Payment"`
+		TotalPayment      float64 `json:"TotalPayment"`
+		MonthlyPMI        float64 `json:"MonthlyPMI"`
+		Taxes             float64 `json:"Taxes"`
+		Insurance         float64 `json:"Insurance"`
+		Program           string  `json:"Program"`
+		UpfrontFee        float64 `json:"UpfrontFee"`
+		TaxRate           float64 `json:"TaxRate"`
+		AnnualInsurance   float64 `json:"AnnualInsurance"`
+		AssumptionsSource string  `json:"AssumptionsSource"`
 	}
 	type brevoRequest struct {
-		To        []To        `json:"to"`
-		Bcc       []bcc         `json:"bcc"`
-		TemplateID int          `json:"templateId"`
-		Params    params       `json:"params"`
+		To         []To   `json:"to"`
+		Bcc        []bcc  `json:"bcc"`
+		TemplateID int    `json:"templateId"`
+		Params     params `json:"params"`
 	}
 
-	// Prepare the request 
+	// Prepare the request
 	req := brevoRequest{
 		To: []To{
 			{
 				Email: to,
 			},
 		},
-		Bcc:       []bcc{
+		Bcc: []bcc{
 			{
 				Email: "diegogarcia51916@gmail.com",
 				Name:  "Diego Garcia",
@@ -171,15 +157,20 @@ func (cfg *apiCfg) SendMortgageCalculation(data data, to, password string) error
 		},
 		TemplateID: 1,
 		Params: params{
-			Price:        data.Price,
-			Interest:     data.Interest,
-			Years:        data.Years,
-			DownPayment:  data.DownPayment,
-			Payment:      data.Payment,
-			TotalPayment: data.TotalPayment,
-			MonthlyPMI:   data.MonthlyPMI,
-			Taxes:        data.Taxes,
-			Insurance:    data.Insurance,
+			Price:             data.Price,
+			Interest:          data.Interest,
+			Years:             data.Years,
+			DownPayment:       data.DownPayment,
+			Payment:           data.Payment,
+			TotalPayment:      data.TotalPayment,
+			MonthlyPMI:        data.MonthlyPMI,
+			Taxes:             data.Taxes,
+			Insurance:         data.Insurance,
+			Program:           data.Program,
+			UpfrontFee:        data.UpfrontFee,
+			TaxRate:           data.TaxRate,
+			AnnualInsurance:   data.AnnualInsurance,
+			AssumptionsSource: data.AssumptionsSource,
 		},
 	}
 
@@ -200,7 +191,6 @@ func (cfg *apiCfg) SendMortgageCalculation(data data, to, password string) error
 	reqEmail.Header.Set("Content-Type", "application/json")
 	reqEmail.Header.Set("api-key", cfg.BrevoAPIKey)
 
-	
 	resp, err := client.Do(reqEmail)
 	if err != nil {
 		log.Printf("Error sending email request: %v", err)
@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/DiegoGarciaCo/websitesAPI/internal/database"
+	"github.com/DiegoGarciaCo/websitesAPI/internal/idempotency"
+)
+
+// Idempotent runs a submission handler at most once per Idempotency-Key
+// header, or per identical payload within a short window when there is no
+// header, and replays the stored response for repeats.
+//
+// Each request holds a Postgres advisory lock on its key while the handler
+// runs, so concurrent duplicates on any replica wait for the first and then
+// get its response instead of creating a second lead. Server errors and
+// transient client errors such as 429 aren't stored, so they can be
+// retried. Keys are scoped to the client's address.
+func (cfg *apiCfg) Idempotent(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		body, err := io.ReadAll(http.MaxBytesReader(w, req.Body, maxFormBodyBytes))
+		if err != nil {
+			respondWithError(w, http.StatusRequestEntityTooLarge, "Request body too large", err)
+			return
+		}
+		req.Body = io.NopCloser(bytes.NewReader(body))
+
+		fingerprint := idempotency.Fingerprint(req.Method, req.URL.Path, body)
+		key, ttl, err := idempotency.Key(req.URL.Path, clientIP(req), req.Header.Get(idempotency.Header), fingerprint)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, "Idempotency-Key must be at most "+strconv.Itoa(idempotency.MaxKeyLength)+" characters", err)
+			return
+		}
+
+		// The transaction holds the lock and stores the response, so it
+		// outlives a client that disconnects once the handler has run.
+		ctx := req.Context()
+		saveCtx := context.WithoutCancel(ctx)
+		tx, err := cfg.SQLDB.BeginTx(saveCtx, nil)
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Something went wrong", err)
+			return
+		}
+		defer tx.Rollback()
+		qtx := cfg.DB.WithTx(tx)
+
+		if err := qtx.LockIdempotencyKey(ctx, key); err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Something went wrong", err)
+			return
+		}
+
+		stored, err := qtx.GetIdempotencyKey(ctx, key)
+		if err == nil {
+			if stored.RequestHash != fingerprint {
+				respondWithError(w, http.StatusUnprocessableEntity, "Idempotency-Key was already used for a different request", nil)
+				return
+			}
+			replayResponse(w, stored)
+			return
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusInternalServerError, "Something went wrong", err)
+			return
+		}
+
+		rec := idempotency.NewRecorder(w)
+		next(rec, req)
+		if !idempotency.Replayable(rec.Status()) {
+			return
+		}
+
+		// The response is already sent; failing to store it only means a
+		// repeat runs the handler again.
+		err = qtx.SaveIdempotencyKey(saveCtx, database.SaveIdempotencyKeyParams{
+			Key:            key,
+			RequestHash:    fingerprint,
+			ResponseStatus: int32(rec.Status()),
+			ContentType:    rec.Header().Get("Content-Type"),
+			ResponseBody:   rec.Body(),
+			ExpiresAt:      time.Now().Add(ttl),
+		})
+		if err == nil {
+			err = tx.Commit()
+		}
+		if err != nil {
+			log.Printf("Error storing idempotent response for %s: %s", req.URL.Path, err)
+		}
+	}
+}
+
+func replayResponse(w http.ResponseWriter, stored database.IdempotencyKey) {
+	if stored.ContentType != "" {
+		w.Header().Set("Content-Type", stored.ContentType)
+	}
+	w.Header().Set(idempotency.ReplayedHeader, "true")
+	w.WriteHeader(int(stored.ResponseStatus))
+	w.Write(stored.ResponseBody)
+}
+
+// deleteExpiredIdempotencyKeys drops responses past their replay window.
+func (cfg *apiCfg) deleteExpiredIdempotencyKeys(ctx context.Context) {
+	if _, err := cfg.DB.DeleteExpiredIdempotencyKeys(ctx); err != nil {
+		log.Printf("Error deleting expired idempotency keys: %s", err)
+	}
+}
@@ -0,0 +1,385 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/DiegoGarciaCo/websitesAPI/internal/database"
+	"github.com/google/uuid"
+)
+
+const (
+	defaultLeadPageSize = 25
+	maxLeadPageSize     = 100
+)
+
+var leadStatuses = map[string]bool{
+	"new":       true,
+	"contacted": true,
+	"qualified": true,
+	"closed":    true,
+}
+
+type leadResponse struct {
+	ID               string    `json:"id"`
+	Type             string    `json:"type"`
+	Status           string    `json:"status"`
+	FirstName        string    `json:"firstName"`
+	LastName         string    `json:"lastName"`
+	Email            string    `json:"email"`
+	Phone            string    `json:"phone"`
+	Message          string    `json:"message"`
+	Source           string    `json:"source"`
+	MarketingConsent bool      `json:"marketingConsent"`
+	AssignedAgent    string    `json:"assignedAgent"`
+	Score            int32     `json:"score"`
+	CRMStage         string    `json:"crmStage"`
+	CreatedAt        time.Time `json:"createdAt"`
+	UpdatedAt        time.Time `json:"updatedAt"`
+}
+
+func newLeadResponse(lead database.Lead) leadResponse {
+	return leadResponse{
+		ID:               lead.ID.String(),
+		Type:             lead.LeadType,
+		Status:           lead.Status,
+		FirstName:        lead.FirstName,
+		LastName:         lead.LastName,
+		Email:            lead.Email,
+		Phone:            lead.Phone,
+		Message:          lead.Message,
+		Source:           lead.Source,
+		MarketingConsent: lead.MarketingConsent,
+		AssignedAgent:    lead.AssignedAgent,
+		Score:            lead.Score,
+		CRMStage:         lead.CrmStage,
+		CreatedAt:        lead.CreatedAt,
+		UpdatedAt:        lead.UpdatedAt,
+	}
+}
+
+func (cfg *apiCfg) ListLeads(w http.ResponseWriter, req *http.Request) {
+	type resParams struct {
+		Leads    []leadResponse `json:"leads"`
+		Total    int64          `json:"total"`
+		Page     int            `json:"page"`
+		PageSize int            `json:"pageSize"`
+	}
+
+	query := req.URL.Query()
+
+	page, err := queryInt(query, "page", 1)
+	if err != nil || page < 1 {
+		respondWithError(w, http.StatusBadRequest, "Invalid page", err)
+		return
+	}
+	pageSize, err := queryInt(query, "pageSize", defaultLeadPageSize)
+	if err != nil || pageSize < 1 || pageSize > maxLeadPageSize {
+		respondWithError(w, http.StatusBadRequest, "Invalid pageSize", err)
+		return
+	}
+
+	filter, err := leadFilterFromQuery(query)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	// Newest first by default; ?sort=score puts the highest scores first.
+	sort := query.Get("sort")
+	if sort != "" && sort != "newest" && sort != "score" {
+		respondWithError(w, http.StatusBadRequest, "Invalid sort", nil)
+		return
+	}
+
+	leads, err := cfg.DB.ListLeads(req.Context(), database.ListLeadsParams{
+		LeadType:       filter.LeadType,
+		Status:         filter.Status,
+		CreatedFrom:    filter.CreatedFrom,
+		CreatedTo:      filter.CreatedTo,
+		DeliveryStatus: filter.DeliveryStatus,
+		Search:         filter.Search,
+		ByScore:        sort == "score",
+		Limit:          int32(pageSize),
+		Offset:         int32((page - 1) * pageSize),
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Something went wrong", err)
+		return
+	}
+
+	total, err := cfg.DB.CountLeads(req.Context(), filter)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Something went wrong", err)
+		return
+	}
+
+	res := resParams{
+		Leads:    make([]leadResponse, len(leads)),
+		Total:    total,
+		Page:     page,
+		PageSize: pageSize,
+	}
+	for i, lead := range leads {
+		res.Leads[i] = newLeadResponse(lead)
+	}
+
+	respondWithJSON(w, http.StatusOK, res)
+}
+
+func (cfg *apiCfg) GetLead(w http.ResponseWriter, req *http.Request) {
+	type deliveryResponse struct {
+		ID            string     `json:"id"`
+		Target        string     `json:"target"`
+		Status        string     `json:"status"`
+		Attempts      int32      `json:"attempts"`
+		LastError     string     `json:"lastError,omitempty"`
+		NextAttemptAt time.Time  `json:"nextAttemptAt"`
+		DeliveredAt   *time.Time `json:"deliveredAt,omitempty"`
+	}
+	type attemptResponse struct {
+		DeliveryID  string    `json:"deliveryId"`
+		Attempt     int32     `json:"attempt"`
+		Status      string    `json:"status"`
+		Error       string    `json:"error,omitempty"`
+		AttemptedAt time.Time `json:"attemptedAt"`
+	}
+	type noteResponse struct {
+		ID        string    `json:"id"`
+		Author    string    `json:"author"`
+		Body      string    `json:"body"`
+		CreatedAt time.Time `json:"createdAt"`
+	}
+	type resParams struct {
+		leadResponse
+		Attribution leadAttribution    `json:"attribution"`
+		Payload     json.RawMessage    `json:"payload"`
+		Deliveries  []deliveryResponse `json:"deliveries"`
+		Attempts    []attemptResponse  `json:"attempts"`
+		Notes       []noteResponse     `json:"notes"`
+	}
+
+	UUID, err := uuid.Parse(req.PathValue("id"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid UUID", err)
+		return
+	}
+
+	lead, err := cfg.DB.GetLeadByID(req.Context(), UUID)
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusNotFound, "Lead not found", err)
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Something went wrong", err)
+		return
+	}
+
+	deliveries, err := cfg.DB.ListLeadDeliveries(req.Context(), UUID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Something went wrong", err)
+		return
+	}
+	attempts, err := cfg.DB.ListLeadDeliveryAttempts(req.Context(), UUID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Something went wrong", err)
+		return
+	}
+	notes, err := cfg.DB.ListLeadNotes(req.Context(), UUID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Something went wrong", err)
+		return
+	}
+
+	res := resParams{
+		leadResponse: newLeadResponse(lead),
+		Attribution:  attributionForLead(lead),
+		Payload:      lead.Payload,
+		Deliveries:   make([]deliveryResponse, len(deliveries)),
+		Attempts:     make([]attemptResponse, len(attempts)),
+		Notes:        make([]noteResponse, len(notes)),
+	}
+	for i, d := range deliveries {
+		res.Deliveries[i] = deliveryResponse{
+			ID:            d.ID.String(),
+			Target:        d.Target,
+			Status:        d.Status,
+			Attempts:      d.Attempts,
+			LastError:     d.LastError.String,
+			NextAttemptAt: d.NextAttemptAt,
+		}
+		if d.DeliveredAt.Valid {
+			res.Deliveries[i].DeliveredAt = &d.DeliveredAt.Time
+		}
+	}
+	for i, a := range attempts {
+		res.Attempts[i] = attemptResponse{
+			DeliveryID:  a.DeliveryID.String(),
+			Attempt:     a.Attempt,
+			Status:      a.Status,
+			Error:       a.Error.String,
+			AttemptedAt: a.AttemptedAt,
+		}
+	}
+	for i, n := range notes {
+		res.Notes[i] = noteResponse{
+			ID:        n.ID.String(),
+			Author:    n.Username.String,
+			Body:      n.Body,
+			CreatedAt: n.CreatedAt,
+		}
+	}
+
+	respondWithJSON(w, http.StatusOK, res)
+}
+
+func (cfg *apiCfg) AddLeadNote(w http.ResponseWriter, req *http.Request) {
+	type reqParams struct {
+		Body string `json:"body"`
+	}
+
+	UUID, err := uuid.Parse(req.PathValue("id"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid UUID", err)
+		return
+	}
+
+	params := reqParams{}
+	decoder := json.NewDecoder(req.Body)
+	if err := decoder.Decode(&params); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload", err)
+		return
+	}
+	if strings.TrimSpace(params.Body) == "" {
+		respondWithError(w, http.StatusBadRequest, "Note body is required", nil)
+		return
+	}
+
+	var userID uuid.NullUUID
+	if user, ok := userFromContext(req.Context()); ok {
+		userID = uuid.NullUUID{UUID: user.ID, Valid: true}
+	}
+
+	note, err := cfg.DB.CreateLeadNote(req.Context(), database.CreateLeadNoteParams{
+		LeadID: UUID,
+		UserID: userID,
+		Body:   params.Body,
+	})
+	if isPQError(err, pqForeignKeyViolation) {
+		respondWithError(w, http.StatusNotFound, "Lead not found", err)
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Something went wrong", err)
+		return
+	}
+
+	type resParams struct {
+		ID        string    `json:"id"`
+		Body      string    `json:"body"`
+		CreatedAt time.Time `json:"createdAt"`
+	}
+	respondWithJSON(w, http.StatusCreated, resParams{
+		ID:        note.ID.String(),
+		Body:      note.Body,
+		CreatedAt: note.CreatedAt,
+	})
+}
+
+func (cfg *apiCfg) UpdateLeadStatus(w http.ResponseWriter, req *http.Request) {
+	type reqParams struct {
+		Status string `json:"status"`
+	}
+
+	UUID, err := uuid.Parse(req.PathValue("id"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid UUID", err)
+		return
+	}
+
+	params := reqParams{}
+	decoder := json.NewDecoder(req.Body)
+	if err := decoder.Decode(&params); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload", err)
+		return
+	}
+	if !leadStatuses[params.Status] {
+		respondWithError(w, http.StatusBadRequest, "Invalid status", nil)
+		return
+	}
+
+	lead, err := cfg.DB.UpdateLeadStatus(req.Context(), database.UpdateLeadStatusParams{
+		ID:     UUID,
+		Status: params.Status,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusNotFound, "Lead not found", err)
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Something went wrong", err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, newLeadResponse(lead))
+}
+
+// leadFilterFromQuery reads the list filters shared by the admin lead
+// endpoints: type, status, delivery, from, to and q.
+func leadFilterFromQuery(query url.Values) (database.CountLeadsParams, error) {
+	filter := database.CountLeadsParams{
+		LeadType:       queryNullString(query, "type"),
+		Status:         queryNullString(query, "status"),
+		DeliveryStatus: queryNullString(query, "delivery"),
+		Search:         queryNullString(query, "q"),
+	}
+
+	if v := query.Get("from"); v != "" {
+		from, _, err := parseDateParam(v)
+		if err != nil {
+			return filter, errors.New("Invalid from date")
+		}
+		filter.CreatedFrom = sql.NullTime{Time: from, Valid: true}
+	}
+	if v := query.Get("to"); v != "" {
+		to, dateOnly, err := parseDateParam(v)
+		if err != nil {
+			return filter, errors.New("Invalid to date")
+		}
+		// A bare date includes the whole day
+		if dateOnly {
+			to = to.AddDate(0, 0, 1)
+		}
+		filter.CreatedTo = sql.NullTime{Time: to, Valid: true}
+	}
+
+	return filter, nil
+}
+
+// parseDateParam accepts either YYYY-MM-DD or RFC 3339 timestamps.
+func parseDateParam(v string) (time.Time, bool, error) {
+	if t, err := time.Parse(time.DateOnly, v); err == nil {
+		return t, true, nil
+	}
+	t, err := time.Parse(time.RFC3339, v)
+	return t, false, err
+}
+
+func queryNullString(query url.Values, key string) sql.NullString {
+	v := strings.TrimSpace(query.Get(key))
+	return sql.NullString{String: v, Valid: v != ""}
+}
+
+func queryInt(query url.Values, key string, fallback int) (int, error) {
+	v := query.Get(key)
+	if v == "" {
+		return fallback, nil
+	}
+	return strconv.Atoi(v)
+}
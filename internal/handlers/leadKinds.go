@@ -0,0 +1,417 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/DiegoGarciaCo/websitesAPI/internal/crm"
+	"github.com/DiegoGarciaCo/websitesAPI/internal/database"
+	"github.com/DiegoGarciaCo/websitesAPI/internal/mortgage"
+	"github.com/DiegoGarciaCo/websitesAPI/internal/routing"
+	"github.com/DiegoGarciaCo/websitesAPI/internal/scoring"
+	"github.com/DiegoGarciaCo/websitesAPI/internal/validation"
+	"github.com/google/uuid"
+)
+
+// leadKind describes how one kind of lead is validated and where it goes.
+type leadKind struct {
+	EventType string // Follow Up Boss event type
+	Source    string // overrides the configured CRM source when set
+	ListID    int64  // Brevo list
+	// Internal kinds are created by their own endpoints only, never
+	// through POST /api/leads.
+	Internal bool
+	// newDetails returns an empty details value to decode into.
+	newDetails func() leadDetails
+}
+
+// leadDetails holds the kind-specific part of a lead submission.
+type leadDetails interface {
+	// validate adds an error for each invalid field, keyed as
+	// "details.<field>".
+	validate(errs validation.Errors)
+	applyCRM(lead *crm.Lead)
+}
+
+// leadRecorder is implemented by details that store extra rows with the
+// lead. record runs inside the lead's transaction; an error rolls the lead
+// back.
+type leadRecorder interface {
+	record(ctx context.Context, db *database.Queries, lead database.Lead) error
+}
+
+// leadFollowUp is implemented by details that trigger extra work once the
+// lead has been saved.
+type leadFollowUp interface {
+	followUp(cfg *apiCfg, sub leadSubmission)
+}
+
+// leadLocalizer is implemented by details holding times that agents read
+// in their own time zone.
+type leadLocalizer interface {
+	setLocation(loc *time.Location)
+}
+
+var leadKinds = map[string]leadKind{
+	leadTypeContact: {
+		EventType:  "General Inquiry",
+		ListID:     6,
+		newDetails: func() leadDetails { return &contactDetails{} },
+	},
+	leadTypeMortgage: {
+		EventType:  "Property Inquiry",
+		ListID:     3,
+		newDetails: func() leadDetails { return &mortgageDetails{} },
+	},
+	leadTypeSellerEstimate: {
+		EventType:  "Seller Inquiry",
+		Source:     "Realtor Website",
+		ListID:     4,
+		newDetails: func() leadDetails { return &sellerEstimateDetails{} },
+	},
+	leadTypeShowing: {
+		EventType: "Property Inquiry",
+		// The Brevo list is cfg.ShowingListID
+		newDetails: func() leadDetails { return &showingDetails{} },
+	},
+	leadTypeOpenHouse: {
+		EventType: "Visited Open House",
+		Source:    "Open House",
+		// The Brevo list is cfg.OpenHouseListID
+		Internal:   true,
+		newDetails: func() leadDetails { return &openHouseDetails{} },
+	},
+}
+
+type contactDetails struct{}
+
+func (d *contactDetails) validate(errs validation.Errors) {}
+
+func (d *contactDetails) applyCRM(lead *crm.Lead) {}
+
+type mortgageDetails struct {
+	Price       float64 `json:"price"`
+	Interest    float64 `json:"interest"`
+	Years       int     `json:"years"`
+	DownPayment float64 `json:"downPayment"`           // percent of price
+	Program     string  `json:"program,omitempty"`     // one of mortgage.Programs, conventional when empty
+	CreditScore int     `json:"creditScore,omitempty"` // optional
+	// VASubsequentUse is set by VA borrowers who used the benefit before.
+	VASubsequentUse bool `json:"vaSubsequentUse,omitempty"`
+	// Location picks the tax and insurance assumptions, optional.
+	mortgage.Location
+}
+
+func (d *mortgageDetails) validate(errs validation.Errors) {
+	if d.Price <= 0 {
+		errs.Add("details.price", "Price must be greater than zero")
+	}
+	if d.Interest < 0 || d.Interest > maxInterestRate {
+		errs.Add("details.interest", fmt.Sprintf("Interest must be between 0 and %d%%", maxInterestRate))
+	}
+	if d.Years <= 0 {
+		errs.Add("details.years", "Years must be greater than zero")
+	}
+	if d.DownPayment < 0 || d.DownPayment > 100 {
+		errs.Add("details.downPayment", "Down payment must be a percentage between 0 and 100")
+	}
+	validateLocation(errs, "details.", d.Location)
+
+	if program, ok := validateLoanProgram(errs, "details.", d.Program, d.CreditScore); ok {
+		if min := mortgage.MinDownPayment(program, d.CreditScore); d.DownPayment >= 0 && d.DownPayment/100 < min {
+			errs.Add("details.downPayment", fmt.Sprintf("%s loans need at least %g%% down", programNames[program], math.Round(min*1000)/10))
+		}
+	}
+}
+
+func (d *mortgageDetails) applyCRM(lead *crm.Lead) {}
+
+func (d *mortgageDetails) applyRouting(lead *routing.Lead) {
+	lead.Price = d.Price
+}
+
+func (d *mortgageDetails) applyScoring(facts *scoring.Facts) {
+	facts.Price = d.Price
+}
+
+// followUp emails the visitor their payment breakdown, unless their address
+// is suppressed.
+func (d *mortgageDetails) followUp(cfg *apiCfg, sub leadSubmission) {
+	go func() {
+		reason, err := cfg.emailSuppression(context.Background(), sub.Email)
+		if err != nil {
+			log.Printf("Error sending mortgage calculation email: %v", err)
+			return
+		}
+		if reason != "" {
+			return
+		}
+
+		assumptions, err := cfg.mortgageAssumptions(context.Background(), d.Location)
+		if err != nil {
+			log.Printf("Error resolving mortgage assumptions, using the defaults: %v", err)
+			assumptions = mortgage.Resolve(nil, mortgage.Location{}, cfg.MortgageDefaults)
+		}
+
+		b := mortgage.Calculate(d.loan(), assumptions)
+		if err := cfg.SendMortgageCalculation(data{
+			Price:        int(b.Price),
+			Interest:     d.Interest * 100,
+			Years:        b.Years,
+			DownPayment:  b.DownPayment,
+			Payment:      b.Payment,
+			TotalPayment: b.TotalPayment,
+			MonthlyPMI:   b.MonthlyPMI,
+			Taxes:        b.Taxes,
+			Insurance:    b.Insurance,
+			Program:      programNames[b.Program],
+			UpfrontFee:   b.UpfrontFee,
+
+			TaxRate:           assumptions.TaxRate,
+			AnnualInsurance:   assumptions.AnnualInsurance,
+			AssumptionsSource: assumptions.Source(),
+		}, sub.Email, cfg.AppPassword); err != nil {
+			log.Printf("Error sending mortgage calculation email: %v", err)
+		}
+	}()
+}
+
+// sellerTimelines are the answers to "When are you thinking of selling?".
+var sellerTimelines = []string{"0-3 months", "3-6 months", "6-12 months", "12+ months", "just curious"}
+
+type sellerEstimateDetails struct {
+	Address  string `json:"address"`
+	City     string `json:"city"`
+	State    string `json:"state"`
+	Zip      string `json:"zip"`
+	Timeline string `json:"timeline"` // one of sellerTimelines, optional
+}
+
+func (d *sellerEstimateDetails) validate(errs validation.Errors) {
+	if strings.TrimSpace(d.Address) == "" {
+		errs.Add("details.address", "Address is required")
+	}
+	if strings.TrimSpace(d.City) == "" {
+		errs.Add("details.city", "City is required")
+	}
+	if strings.TrimSpace(d.State) == "" {
+		errs.Add("details.state", "State is required")
+	}
+	if d.Timeline != "" && !slices.Contains(sellerTimelines, d.Timeline) {
+		errs.Add("details.timeline", "Choose one of the listed timelines")
+	}
+}
+
+func (d *sellerEstimateDetails) applyCRM(lead *crm.Lead) {
+	lead.Address = &crm.Address{
+		Street: d.Address,
+		City:   d.City,
+		State:  d.State,
+		Zip:    d.Zip,
+	}
+}
+
+func (d *sellerEstimateDetails) applyRouting(lead *routing.Lead) {
+	lead.Zip = d.Zip
+	lead.City = d.City
+}
+
+func (d *sellerEstimateDetails) applyScoring(facts *scoring.Facts) {
+	facts.Timeline = d.Timeline
+}
+
+// leadKindListID returns the Brevo list leads of a kind join, or 0 for
+// none. Some kinds' lists are configured per deployment.
+func (cfg *apiCfg) leadKindListID(kind string) int64 {
+	switch kind {
+	case leadTypeShowing:
+		return cfg.ShowingListID
+	case leadTypeOpenHouse:
+		return cfg.OpenHouseListID
+	}
+	return leadKinds[kind].ListID
+}
+
+// showingWindow is a span of time the buyer could tour the property.
+type showingWindow struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+// showingDetails is a request to tour a property, identified by its
+// address, its MLS number or both.
+type showingDetails struct {
+	Address     string          `json:"address"`
+	City        string          `json:"city"`
+	State       string          `json:"state"`
+	Zip         string          `json:"zip"`
+	MLSNumber   string          `json:"mlsNumber"`
+	Windows     []showingWindow `json:"windows"`
+	PreApproved bool            `json:"preApproved"`
+
+	// location is the agents' time zone the windows are shown in.
+	location *time.Location
+}
+
+func (d *showingDetails) validate(errs validation.Errors) {
+	if strings.TrimSpace(d.Address) == "" && strings.TrimSpace(d.MLSNumber) == "" {
+		errs.Add("details.address", "Enter the property address or MLS number")
+	}
+	if len(d.MLSNumber) > 50 {
+		errs.Add("details.mlsNumber", "MLS number is too long")
+	}
+	if len(d.Windows) > maxShowingWindows {
+		errs.Add("details.windows", fmt.Sprintf("Choose up to %d preferred times", maxShowingWindows))
+	}
+	for _, window := range d.Windows {
+		if window.Start.IsZero() || !window.End.After(window.Start) {
+			errs.Add("details.windows", "Each preferred time needs a start before its end")
+		}
+	}
+}
+
+func (d *showingDetails) setLocation(loc *time.Location) {
+	d.location = loc
+}
+
+func (d *showingDetails) applyCRM(lead *crm.Lead) {
+	lead.Property = &crm.Property{
+		Street:    d.Address,
+		City:      d.City,
+		State:     d.State,
+		Zip:       d.Zip,
+		MLSNumber: d.MLSNumber,
+	}
+
+	// Follow Up Boss has no fields for these, so agents read them in the
+	// event message.
+	lines := []string{}
+	if len(d.Windows) > 0 {
+		lines = append(lines, "Preferred showing times:")
+		for _, window := range d.Windows {
+			start, end := window.Start, window.End
+			if d.location != nil {
+				start, end = start.In(d.location), end.In(d.location)
+			}
+			lines = append(lines, "- "+start.Format("Mon Jan 2, 3:04 PM")+" to "+end.Format("3:04 PM (MST)"))
+		}
+	}
+	lines = append(lines, "Pre-approved: "+yesNo(d.PreApproved))
+	lead.Message = appendMessage(lead.Message, lines...)
+}
+
+func (d *showingDetails) applyRouting(lead *routing.Lead) {
+	lead.Zip = d.Zip
+	lead.City = d.City
+}
+
+// openHouseDetails is a visitor's sign-in at an open house. The property is
+// copied from the open house when the sign-in is uploaded.
+type openHouseDetails struct {
+	OpenHouseID string    `json:"openHouseId"`
+	ClientID    string    `json:"clientId"`
+	SignedInAt  time.Time `json:"signedInAt"`
+	HasAgent    bool      `json:"hasAgent"`
+	PreApproved bool      `json:"preApproved"`
+	Address     string    `json:"address"`
+	City        string    `json:"city"`
+	State       string    `json:"state"`
+	Zip         string    `json:"zip"`
+	MLSNumber   string    `json:"mlsNumber"`
+}
+
+func (d *openHouseDetails) validate(errs validation.Errors) {
+	if _, err := uuid.Parse(d.OpenHouseID); err != nil {
+		errs.Add("details.openHouseId", "Invalid open house")
+	}
+	switch {
+	case strings.TrimSpace(d.ClientID) == "":
+		errs.Add("details.clientId", "Client ID is required")
+	case len(d.ClientID) > 100:
+		errs.Add("details.clientId", "Client ID is too long")
+	}
+}
+
+func (d *openHouseDetails) applyCRM(lead *crm.Lead) {
+	lead.Property = &crm.Property{
+		Street:    d.Address,
+		City:      d.City,
+		State:     d.State,
+		Zip:       d.Zip,
+		MLSNumber: d.MLSNumber,
+	}
+	lead.Message = appendMessage(lead.Message,
+		"Signed in: "+d.SignedInAt.Format("Mon Jan 2, 3:04 PM (MST)"),
+		"Working with an agent: "+yesNo(d.HasAgent),
+		"Pre-approved: "+yesNo(d.PreApproved),
+	)
+}
+
+func (d *openHouseDetails) applyRouting(lead *routing.Lead) {
+	lead.Zip = d.Zip
+	lead.City = d.City
+}
+
+// record links the lead to its open house. A client ID that was already
+// uploaded returns errDuplicateSignIn.
+func (d *openHouseDetails) record(ctx context.Context, db *database.Queries, lead database.Lead) error {
+	openHouseID, err := uuid.Parse(d.OpenHouseID)
+	if err != nil {
+		return err
+	}
+
+	n, err := db.CreateOpenHouseSignIn(ctx, database.CreateOpenHouseSignInParams{
+		OpenHouseID: openHouseID,
+		ClientID:    d.ClientID,
+		LeadID:      lead.ID,
+		HasAgent:    d.HasAgent,
+		PreApproved: d.PreApproved,
+		SignedInAt:  d.SignedInAt,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to record sign-in: %w", err)
+	}
+	if n == 0 {
+		return errDuplicateSignIn
+	}
+	return nil
+}
+
+// appendMessage adds lines to a lead message, separated from what the
+// visitor wrote by a blank line.
+func appendMessage(message string, lines ...string) string {
+	if message != "" {
+		lines = append([]string{message, ""}, lines...)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "Yes"
+	}
+	return "No"
+}
+
+// decodeLeadDetails decodes the details of a submission according to its kind.
+func decodeLeadDetails(sub leadSubmission) (leadKind, leadDetails, error) {
+	kind, ok := leadKinds[sub.Kind]
+	if !ok {
+		return leadKind{}, nil, fmt.Errorf("unknown lead kind: %q", sub.Kind)
+	}
+
+	details := kind.newDetails()
+	if len(sub.Details) > 0 && string(sub.Details) != "null" {
+		if err := json.Unmarshal(sub.Details, details); err != nil {
+			return leadKind{}, nil, fmt.Errorf("invalid %s details: %w", sub.Kind, err)
+		}
+	}
+
+	return kind, details, nil
+}
@@ -0,0 +1,165 @@
+package handlers
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/DiegoGarciaCo/websitesAPI/internal/crm"
+	"github.com/DiegoGarciaCo/websitesAPI/internal/database"
+	"github.com/DiegoGarciaCo/websitesAPI/internal/validation"
+)
+
+func TestShowingDetailsValidate(t *testing.T) {
+	start := time.Date(2026, 5, 2, 15, 0, 0, 0, time.UTC)
+	window := showingWindow{Start: start, End: start.Add(time.Hour)}
+
+	tests := []struct {
+		name    string
+		details showingDetails
+		want    []string
+	}{
+		{"Address", showingDetails{Address: "123 Main St", Windows: []showingWindow{window}}, nil},
+		{"MLS number only", showingDetails{MLSNumber: "1234567"}, nil},
+		{"No property", showingDetails{Windows: []showingWindow{window}}, []string{"details.address"}},
+		{"Blank address", showingDetails{Address: "  "}, []string{"details.address"}},
+		{"Long MLS number", showingDetails{MLSNumber: string(make([]byte, 51))}, []string{"details.mlsNumber"}},
+		{"Too many windows", showingDetails{Address: "123 Main St", Windows: []showingWindow{window, window, window, window}}, []string{"details.windows"}},
+		{"Window ends before it starts", showingDetails{Address: "123 Main St", Windows: []showingWindow{{Start: start, End: start}}}, []string{"details.windows"}},
+		{"Window without a start", showingDetails{Address: "123 Main St", Windows: []showingWindow{{End: start}}}, []string{"details.windows"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := validation.Errors{}
+			tt.details.validate(errs)
+
+			if len(errs) != len(tt.want) {
+				t.Fatalf("validate() = %v, want errors for %v", errs, tt.want)
+			}
+			for _, field := range tt.want {
+				if _, ok := errs[field]; !ok {
+					t.Errorf("validate() = %v, want an error for %s", errs, field)
+				}
+			}
+		})
+	}
+}
+
+func TestShowingDetailsApplyCRM(t *testing.T) {
+	denver, err := time.LoadLocation("America/Denver")
+	if err != nil {
+		t.Skipf("time zone data unavailable: %s", err)
+	}
+	start := time.Date(2026, 5, 2, 15, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name         string
+		details      showingDetails
+		location     *time.Location
+		message      string
+		wantProperty *crm.Property
+		wantMessage  string
+	}{
+		{
+			"Property and windows in the agents' zone",
+			showingDetails{
+				Address:     "123 Main St",
+				City:        "Denver",
+				State:       "CO",
+				Zip:         "80202",
+				MLSNumber:   "1234567",
+				Windows:     []showingWindow{{Start: start, End: start.Add(time.Hour)}},
+				PreApproved: true,
+			},
+			denver,
+			"",
+			&crm.Property{Street: "123 Main St", City: "Denver", State: "CO", Zip: "80202", MLSNumber: "1234567"},
+			"Preferred showing times:\n- Sat May 2, 9:00 AM to 10:00 AM (MDT)\nPre-approved: Yes",
+		},
+		{
+			"Client zone is ignored",
+			showingDetails{
+				MLSNumber: "1234567",
+				Windows:   []showingWindow{{Start: start.In(time.FixedZone("EST", -5*60*60)), End: start.Add(90 * time.Minute)}},
+			},
+			denver,
+			"Can we see it this weekend?",
+			&crm.Property{MLSNumber: "1234567"},
+			"Can we see it this weekend?\n\nPreferred showing times:\n- Sat May 2, 9:00 AM to 10:30 AM (MDT)\nPre-approved: No",
+		},
+		{
+			"No windows",
+			showingDetails{Address: "123 Main St"},
+			denver,
+			"",
+			&crm.Property{Street: "123 Main St"},
+			"Pre-approved: No",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lead := crm.Lead{Message: tt.message}
+			tt.details.setLocation(tt.location)
+			tt.details.applyCRM(&lead)
+
+			if !reflect.DeepEqual(lead.Property, tt.wantProperty) {
+				t.Errorf("Property = %+v, want %+v", lead.Property, tt.wantProperty)
+			}
+			if lead.Message != tt.wantMessage {
+				t.Errorf("Message = %q, want %q", lead.Message, tt.wantMessage)
+			}
+		})
+	}
+}
+
+func TestContactForLeadList(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  *apiCfg
+		kind string
+		want []int64
+	}{
+		{"Static list", &apiCfg{}, leadTypeContact, []int64{6}},
+		{"Configured list", &apiCfg{ShowingListID: 12}, leadTypeShowing, []int64{12}},
+		{"No list configured", &apiCfg{}, leadTypeShowing, nil},
+		{"Open house list", &apiCfg{ShowingListID: 12, OpenHouseListID: 13}, leadTypeOpenHouse, []int64{13}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.cfg.contactForLead(database.Lead{LeadType: tt.kind}).ListIDs
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ListIDs = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMortgageDetailsValidateInterest(t *testing.T) {
+	tests := []struct {
+		name     string
+		interest float64
+		wantErr  bool
+	}{
+		{"Zero", 0, false},
+		{"Typical", 6.5, false},
+		{"Maximum", maxInterestRate, false},
+		{"Negative", -1, true},
+		{"Above the maximum", maxInterestRate + 0.1, true},
+		{"Absurd", 1e6, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := mortgageDetails{Price: 400_000, Interest: tt.interest, Years: 30, DownPayment: 20}
+			errs := validation.Errors{}
+			d.validate(errs)
+
+			if _, got := errs["details.interest"]; got != tt.wantErr {
+				t.Errorf("validate() = %v, want interest error %v", errs, tt.wantErr)
+			}
+		})
+	}
+}
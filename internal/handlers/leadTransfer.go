@@ -0,0 +1,450 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/DiegoGarciaCo/websitesAPI/internal/database"
+	"github.com/DiegoGarciaCo/websitesAPI/internal/validation"
+)
+
+const (
+	exportBatchSize = 500
+	maxImportRows   = 5000
+	maxImportBytes  = 10 << 20
+)
+
+// leadExport is one exported lead, as a CSV row or an NDJSON line.
+type leadExport struct {
+	leadResponse
+	Attribution leadAttribution `json:"attribution"`
+}
+
+var leadExportHeader = []string{
+	"id", "type", "status", "crm_stage", "first_name", "last_name", "email", "phone", "message",
+	"source", "utm_source", "utm_medium", "utm_campaign", "referrer", "landing_page",
+	"assigned_agent", "score", "marketing_consent", "created_at",
+}
+
+// record is the lead as a CSV row. Cells visitors typed are escaped with
+// csvSafe; the phone is already normalized to E.164 and written as is, so
+// the file can be imported again.
+func (e leadExport) record() []string {
+	return []string{
+		e.ID,
+		e.Type,
+		e.Status,
+		csvSafe(e.CRMStage),
+		csvSafe(e.FirstName),
+		csvSafe(e.LastName),
+		csvSafe(e.Email),
+		e.Phone,
+		csvSafe(e.Message),
+		csvSafe(e.Source),
+		csvSafe(e.Attribution.UTMSource),
+		csvSafe(e.Attribution.UTMMedium),
+		csvSafe(e.Attribution.UTMCampaign),
+		csvSafe(e.Attribution.Referrer),
+		csvSafe(e.Attribution.LandingPage),
+		csvSafe(e.AssignedAgent),
+		strconv.Itoa(int(e.Score)),
+		strconv.FormatBool(e.MarketingConsent),
+		e.CreatedAt.Format(time.RFC3339),
+	}
+}
+
+// csvSafe prefixes a free-text cell that a spreadsheet would read as a
+// formula with a quote, so a visitor can't smuggle one into an export
+// through their name or message.
+func csvSafe(v string) string {
+	if v != "" && strings.ContainsRune("=+-@\t\r", rune(v[0])) {
+		return "'" + v
+	}
+	return v
+}
+
+// ExportLeads streams every lead matching ?type=, ?status=, ?from= and ?to=
+// as CSV, or as NDJSON with ?format=ndjson. Leads are read in batches, so
+// exports of any size use constant memory.
+func (cfg *apiCfg) ExportLeads(w http.ResponseWriter, req *http.Request) {
+	query := req.URL.Query()
+
+	format := query.Get("format")
+	if format == "" {
+		format = "csv"
+	}
+	if format != "csv" && format != "ndjson" {
+		respondWithError(w, http.StatusBadRequest, "Invalid format", nil)
+		return
+	}
+
+	filter, err := leadFilterFromQuery(query)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	ctx := req.Context()
+	params := database.ListLeadsForExportParams{
+		LeadType:    filter.LeadType,
+		Status:      filter.Status,
+		CreatedFrom: filter.CreatedFrom,
+		CreatedTo:   filter.CreatedTo,
+		Limit:       exportBatchSize,
+	}
+
+	// Fetch the first batch before writing anything, so a bad query can
+	// still get a proper error response.
+	leads, err := cfg.DB.ListLeadsForExport(ctx, params)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Something went wrong", err)
+		return
+	}
+
+	filename := "leads-" + time.Now().Format("2006-01-02") + "." + format
+	if format == "csv" {
+		w.Header().Set("Content-Type", "text/csv")
+	} else {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+	}
+	w.Header().Set("Content-Disposition", `attachment; filename="`+filename+`"`)
+	w.WriteHeader(http.StatusOK)
+
+	flusher, _ := w.(http.Flusher)
+	writer := csv.NewWriter(w)
+	encoder := json.NewEncoder(w)
+
+	if format == "csv" {
+		writer.Write(leadExportHeader)
+	}
+
+	for len(leads) > 0 {
+		for _, lead := range leads {
+			e := leadExport{
+				leadResponse: newLeadResponse(lead),
+				Attribution:  attributionForLead(lead),
+			}
+			if format == "csv" {
+				writer.Write(e.record())
+			} else if err := encoder.Encode(e); err != nil {
+				log.Printf("Error writing lead export: %s", err)
+				return
+			}
+		}
+		writer.Flush()
+		if err := writer.Error(); err != nil {
+			log.Printf("Error writing lead export: %s", err)
+			return
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+
+		if len(leads) < exportBatchSize {
+			return
+		}
+
+		last := leads[len(leads)-1]
+		params.AfterCreatedAt, params.AfterID = last.CreatedAt, last.ID
+		leads, err = cfg.DB.ListLeadsForExport(ctx, params)
+		if err != nil {
+			// The status line is already sent; the client sees a truncated file.
+			log.Printf("Error reading leads for export: %s", err)
+			return
+		}
+	}
+}
+
+// importColumns maps the accepted CSV headers, compared case-insensitively
+// and ignoring spaces, underscores and dashes, to lead fields.
+var importColumns = map[string]string{
+	"firstname":    "firstName",
+	"first":        "firstName",
+	"lastname":     "lastName",
+	"last":         "lastName",
+	"name":         "name",
+	"fullname":     "name",
+	"email":        "email",
+	"emailaddress": "email",
+	"phone":        "phone",
+	"phonenumber":  "phone",
+	"number":       "phone",
+	"type":         "kind",
+	"kind":         "kind",
+	"message":      "message",
+	"notes":        "message",
+	"subscribed":   "subscribed",
+	"utmsource":    "utmSource",
+	"utmmedium":    "utmMedium",
+	"utmcampaign":  "utmCampaign",
+	"referrer":     "referrer",
+	"landingpage":  "landingPage",
+	"createdat":    "createdAt",
+	"created":      "createdAt",
+	"datecreated":  "createdAt",
+	"date":         "createdAt",
+}
+
+func importColumn(header string) string {
+	h := strings.ToLower(strings.TrimSpace(header))
+	h = strings.NewReplacer(" ", "", "_", "", "-", "").Replace(h)
+	return importColumns[h]
+}
+
+func parseImportBool(v string) bool {
+	switch strings.ToLower(strings.TrimSpace(v)) {
+	case "1", "true", "yes", "y", "x":
+		return true
+	}
+	return false
+}
+
+// importTimeLayouts are the accepted created_at formats. Dates without a
+// zone are read in the agents' time zone.
+var importTimeLayouts = []string{
+	time.RFC3339,
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+	"1/2/2006 15:04",
+	"1/2/2006",
+}
+
+func parseImportTime(v string, loc *time.Location) (time.Time, error) {
+	if loc == nil {
+		loc = time.UTC
+	}
+	v = strings.TrimSpace(v)
+	for _, layout := range importTimeLayouts {
+		if t, err := time.ParseInLocation(layout, v, loc); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid date %q", v)
+}
+
+type importRowResult struct {
+	Row         int               `json:"row"`    // line in the file, counting the header as 1
+	Status      string            `json:"status"` // created, duplicate, invalid or error
+	LeadID      string            `json:"leadId,omitempty"`
+	DuplicateOf string            `json:"duplicateOf,omitempty"`
+	Error       string            `json:"error,omitempty"`
+	Fields      validation.Errors `json:"fields,omitempty"`
+}
+
+// importReport counts the outcome of every row of an import.
+type importReport struct {
+	Total      int               `json:"total"`
+	Created    int               `json:"created"`
+	Duplicates int               `json:"duplicates"`
+	Invalid    int               `json:"invalid"`
+	Failed     int               `json:"failed"`
+	Rows       []importRowResult `json:"rows"`
+}
+
+func (r *importReport) add(row importRowResult) {
+	r.Total++
+	switch row.Status {
+	case "created":
+		r.Created++
+	case "duplicate":
+		r.Duplicates++
+	case "invalid":
+		r.Invalid++
+	default:
+		r.Failed++
+	}
+	r.Rows = append(r.Rows, row)
+}
+
+// importRecord is one row read from the uploaded CSV, or the error that
+// kept it from being read.
+type importRecord struct {
+	line   int
+	fields []string
+	err    error
+}
+
+// ImportLeads creates leads from an uploaded CSV with a header row. Each
+// row is validated like a form submission and skipped when its email or
+// phone matches an existing lead or an earlier row. Imported leads are only
+// stored unless ?deliver=true, which sends them to the CRM, Brevo (with
+// consent), agents and webhook subscribers like any new lead. The whole file
+// is read before any lead is saved, so a file that is too large or can't be
+// read is rejected without importing part of it.
+func (cfg *apiCfg) ImportLeads(w http.ResponseWriter, req *http.Request) {
+	deliver := parseImportBool(req.URL.Query().Get("deliver"))
+
+	reader := csv.NewReader(http.MaxBytesReader(w, req.Body, maxImportBytes))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Could not read the CSV header", err)
+		return
+	}
+	columns := make([]string, len(header))
+	hasEmail := false
+	for i, h := range header {
+		columns[i] = importColumn(strings.TrimPrefix(h, "\ufeff"))
+		hasEmail = hasEmail || columns[i] == "email"
+	}
+	if !hasEmail {
+		respondWithError(w, http.StatusBadRequest, "The CSV needs an email column", nil)
+		return
+	}
+
+	var records []importRecord
+	for {
+		fields, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			records = append(records, importRecord{line: parseErr.StartLine, err: parseErr.Err})
+		} else if err != nil {
+			respondWithError(w, http.StatusBadRequest, "Could not read the CSV", err)
+			return
+		} else {
+			line, _ := reader.FieldPos(0)
+			records = append(records, importRecord{line: line, fields: fields})
+		}
+		if len(records) > maxImportRows {
+			respondWithError(w, http.StatusRequestEntityTooLarge, "Import at most "+strconv.Itoa(maxImportRows)+" rows at a time", nil)
+			return
+		}
+	}
+
+	ctx := req.Context()
+	meta := newSubmissionMeta(req)
+	meta.Imported = true
+	meta.SkipDelivery = !deliver
+
+	seen := map[string]bool{}
+	res := importReport{Rows: []importRowResult{}}
+
+	for _, rec := range records {
+		var r importRowResult
+		if rec.err != nil {
+			r = importRowResult{Status: "invalid", Error: rec.err.Error()}
+		} else {
+			r = cfg.importLead(ctx, meta, columns, rec.fields, seen)
+		}
+		r.Row = rec.line
+		res.add(r)
+	}
+
+	respondWithJSON(w, http.StatusOK, res)
+}
+
+// importLead validates, deduplicates and saves one CSV row. seen holds the
+// emails and phones of earlier rows in the file.
+func (cfg *apiCfg) importLead(ctx context.Context, meta submissionMeta, columns, record []string, seen map[string]bool) importRowResult {
+	sub := leadSubmission{Kind: leadTypeContact, ConsentVersion: "import"}
+	var name, createdAt string
+	for i, v := range record {
+		if i >= len(columns) {
+			break
+		}
+		v = strings.TrimSpace(v)
+		switch columns[i] {
+		case "firstName":
+			sub.FirstName = v
+		case "lastName":
+			sub.LastName = v
+		case "name":
+			name = v
+		case "email":
+			sub.Email = v
+		case "phone":
+			sub.Phone = v
+		case "kind":
+			if v != "" {
+				sub.Kind = v
+			}
+		case "message":
+			sub.Message = v
+		case "subscribed":
+			sub.Subscribed = parseImportBool(v)
+		case "utmSource":
+			sub.UTMSource = v
+		case "utmMedium":
+			sub.UTMMedium = v
+		case "utmCampaign":
+			sub.UTMCampaign = v
+		case "referrer":
+			sub.Referrer = v
+		case "landingPage":
+			sub.LandingPage = v
+		case "createdAt":
+			createdAt = v
+		}
+	}
+	if sub.FirstName == "" && name != "" {
+		n := validation.ParseName(name)
+		sub.FirstName, sub.LastName = n.First, n.LastWithSuffix()
+	}
+
+	if leadKinds[sub.Kind].Internal {
+		return importRowResult{Status: "invalid", Fields: validation.Errors{"kind": "Unknown lead kind"}}
+	}
+
+	normalized, _, err := cfg.validateLead(sub)
+	var errs validation.Errors
+	if errors.As(err, &errs) {
+		return importRowResult{Status: "invalid", Fields: errs}
+	}
+
+	// Keep the date the lead came in from the source, when it has one.
+	if createdAt != "" {
+		t, err := parseImportTime(createdAt, cfg.Location)
+		if err != nil {
+			return importRowResult{Status: "invalid", Fields: validation.Errors{"createdAt": "Enter a date like 2024-01-31"}}
+		}
+		if t.After(time.Now()) {
+			return importRowResult{Status: "invalid", Fields: validation.Errors{"createdAt": "Date can't be in the future"}}
+		}
+		meta.CreatedAt = t
+	}
+
+	email := strings.ToLower(normalized.Email)
+	if seen["email:"+email] || (normalized.Phone != "" && seen["phone:"+normalized.Phone]) {
+		return importRowResult{Status: "duplicate", Error: "Matches an earlier row"}
+	}
+	seen["email:"+email] = true
+	if normalized.Phone != "" {
+		seen["phone:"+normalized.Phone] = true
+	}
+
+	existing, err := cfg.DB.FindDuplicateLead(ctx, database.FindDuplicateLeadParams{
+		Email: normalized.Email,
+		Phone: normalized.Phone,
+	})
+	if err == nil {
+		return importRowResult{Status: "duplicate", DuplicateOf: existing.String()}
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		log.Printf("Error checking for duplicate lead: %s", err)
+		return importRowResult{Status: "error", Error: "Could not check for duplicates"}
+	}
+
+	lead, err := cfg.submitLead(ctx, meta, sub)
+	if err != nil {
+		log.Printf("Error importing lead: %s", err)
+		return importRowResult{Status: "error", Error: "Could not save lead"}
+	}
+
+	return importRowResult{Status: "created", LeadID: lead.ID.String()}
+}
@@ -0,0 +1,183 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCSVSafe(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"", ""},
+		{"Jane", "Jane"},
+		{"=HYPERLINK(\"http://example.com\")", "'=HYPERLINK(\"http://example.com\")"},
+		{"+SUM(A1:A9)", "'+SUM(A1:A9)"},
+		{"-2+3", "'-2+3"},
+		{"@SUM(A1)", "'@SUM(A1)"},
+		{"\t=1", "'\t=1"},
+		{"a=b", "a=b"},
+	}
+
+	for _, tt := range tests {
+		if got := csvSafe(tt.in); got != tt.want {
+			t.Errorf("csvSafe(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestLeadExportRecord(t *testing.T) {
+	e := leadExport{
+		leadResponse: leadResponse{
+			ID:        "7c9e6679-7425-40de-944b-e07fc1f90ae7",
+			Type:      leadTypeContact,
+			FirstName: "=cmd",
+			Email:     "jane@example.com",
+			Phone:     "+15555550100",
+			Message:   "@mention",
+			Score:     40,
+			CreatedAt: time.Date(2026, 5, 2, 15, 0, 0, 0, time.UTC),
+		},
+	}
+	e.Attribution.UTMSource = "+ads"
+
+	record := e.record()
+	if len(record) != len(leadExportHeader) {
+		t.Fatalf("record() has %d cells, header has %d", len(record), len(leadExportHeader))
+	}
+
+	want := map[string]string{
+		"first_name": "'=cmd",
+		"email":      "jane@example.com",
+		"phone":      "+15555550100",
+		"message":    "'@mention",
+		"utm_source": "'+ads",
+		"score":      "40",
+		"created_at": "2026-05-02T15:00:00Z",
+	}
+	for i, column := range leadExportHeader {
+		if v, ok := want[column]; ok && record[i] != v {
+			t.Errorf("%s = %q, want %q", column, record[i], v)
+		}
+	}
+
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	writer.Write(record)
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		t.Fatal(err)
+	}
+	read, err := csv.NewReader(&buf).Read()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(read, ",") != strings.Join(record, ",") {
+		t.Errorf("record did not survive a CSV round trip: %q", read)
+	}
+}
+
+func TestImportColumn(t *testing.T) {
+	tests := map[string]string{
+		"First Name":    "firstName",
+		"first_name":    "firstName",
+		"EMAIL":         "email",
+		"Email-Address": "email",
+		"Phone Number":  "phone",
+		"created_at":    "createdAt",
+		"Date Created":  "createdAt",
+		"Favorite":      "",
+	}
+
+	for header, want := range tests {
+		if got := importColumn(header); got != want {
+			t.Errorf("importColumn(%q) = %q, want %q", header, got, want)
+		}
+	}
+}
+
+func TestParseImportTime(t *testing.T) {
+	denver, err := time.LoadLocation("America/Denver")
+	if err != nil {
+		t.Skip(err)
+	}
+
+	tests := []struct {
+		in   string
+		want time.Time
+	}{
+		{"2024-01-31T10:30:00Z", time.Date(2024, 1, 31, 10, 30, 0, 0, time.UTC)},
+		{"2024-01-31 10:30:00", time.Date(2024, 1, 31, 10, 30, 0, 0, denver)},
+		{"2024-01-31", time.Date(2024, 1, 31, 0, 0, 0, 0, denver)},
+		{"1/31/2024", time.Date(2024, 1, 31, 0, 0, 0, 0, denver)},
+	}
+
+	for _, tt := range tests {
+		got, err := parseImportTime(tt.in, denver)
+		if err != nil {
+			t.Errorf("parseImportTime(%q) failed: %s", tt.in, err)
+			continue
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("parseImportTime(%q) = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+
+	if _, err := parseImportTime("last Tuesday", denver); err == nil {
+		t.Error("parseImportTime accepted an invalid date")
+	}
+}
+
+func TestImportReportAdd(t *testing.T) {
+	var r importReport
+	for _, status := range []string{"created", "created", "duplicate", "invalid", "error"} {
+		r.add(importRowResult{Status: status})
+	}
+
+	want := importReport{Total: 5, Created: 2, Duplicates: 1, Invalid: 1, Failed: 1}
+	if r.Total != want.Total || r.Created != want.Created || r.Duplicates != want.Duplicates ||
+		r.Invalid != want.Invalid || r.Failed != want.Failed {
+		t.Errorf("report = %+v, want %+v", r, want)
+	}
+	if len(r.Rows) != 5 {
+		t.Errorf("report has %d rows, want 5", len(r.Rows))
+	}
+}
+
+func TestImportLeadsRejectsBeforeSaving(t *testing.T) {
+	var tooMany strings.Builder
+	tooMany.WriteString("email\n")
+	for i := 0; i <= maxImportRows; i++ {
+		fmt.Fprintf(&tooMany, "lead%d@example.com\n", i)
+	}
+
+	tests := []struct {
+		name string
+		body string
+		want int
+	}{
+		{"No email column", "name,phone\nJane,5555550100\n", http.StatusBadRequest},
+		{"Too many rows", tooMany.String(), http.StatusRequestEntityTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// cfg has no database, so reaching a save would panic.
+			cfg := &apiCfg{}
+			req := httptest.NewRequest(http.MethodPost, "/api/leads/import", strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+
+			cfg.ImportLeads(w, req)
+
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d", w.Code, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,414 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/DiegoGarciaCo/websitesAPI/internal/crm"
+	"github.com/DiegoGarciaCo/websitesAPI/internal/database"
+	"github.com/DiegoGarciaCo/websitesAPI/internal/routing"
+	"github.com/DiegoGarciaCo/websitesAPI/internal/scoring"
+	"github.com/DiegoGarciaCo/websitesAPI/internal/validation"
+	"github.com/google/uuid"
+)
+
+const (
+	leadTypeContact        = "contact"
+	leadTypeMortgage       = "mortgage"
+	leadTypeSellerEstimate = "seller-estimate"
+	leadTypeShowing        = "showing-request"
+	leadTypeOpenHouse      = "open-house"
+)
+
+const (
+	maxNameLength    = 100
+	maxMessageLength = 5000
+	// maxShowingWindows is how many preferred times a showing request may
+	// list.
+	maxShowingWindows = 3
+)
+
+const (
+	deliveryTargetCRM   = "crm"
+	deliveryTargetBrevo = "brevo"
+	// deliveryTargetNotify emails the agents in the lead type's
+	// notification rule.
+	deliveryTargetNotify = "notify"
+	// deliveryTargetCRMTags updates the score band tag of a lead already
+	// in the CRM after rescoring changed its score.
+	deliveryTargetCRMTags = "crm_tags"
+)
+
+// saveLead stores a lead with its consent record and queues it for delivery
+// to the CRM, agent notifications, lead.created webhook subscribers and,
+// with marketing consent, Brevo. Everything happens in one transaction, so a
+// submission is never lost when any of them is down. Without deliver the lead
+// is only stored. A lead with a route is assigned to an agent by the routing
+// rules in the same transaction; without one it stays unassigned.
+func (cfg *apiCfg) saveLead(ctx context.Context, params database.CreateLeadParams, consent database.CreateConsentRecordParams, details leadDetails, route *routing.Lead, deliver bool) (database.Lead, error) {
+	tx, err := cfg.SQLDB.BeginTx(ctx, nil)
+	if err != nil {
+		return database.Lead{}, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	qtx := cfg.DB.WithTx(tx)
+
+	if route != nil {
+		// An unassigned lead is better than a lost one.
+		params.AssignedAgent, params.RoutingRuleID, err = cfg.assignAgent(ctx, qtx, *route)
+		if err != nil {
+			log.Printf("Error assigning lead: %s", err)
+		}
+	}
+
+	lead, err := qtx.CreateLead(ctx, params)
+	if err != nil {
+		return database.Lead{}, fmt.Errorf("failed to create lead: %w", err)
+	}
+
+	consent.LeadID = uuid.NullUUID{UUID: lead.ID, Valid: true}
+	if _, err := qtx.CreateConsentRecord(ctx, consent); err != nil {
+		return database.Lead{}, fmt.Errorf("failed to record consent: %w", err)
+	}
+
+	if r, ok := details.(leadRecorder); ok {
+		if err := r.record(ctx, qtx, lead); err != nil {
+			return database.Lead{}, err
+		}
+	}
+
+	if deliver {
+		targets := []string{deliveryTargetCRM, deliveryTargetNotify}
+		if params.MarketingConsent {
+			targets = append(targets, deliveryTargetBrevo)
+		}
+
+		for _, target := range targets {
+			err = qtx.CreateLeadDelivery(ctx, database.CreateLeadDeliveryParams{
+				LeadID: lead.ID,
+				Target: target,
+			})
+			if err != nil {
+				return database.Lead{}, fmt.Errorf("failed to queue %s delivery: %w", target, err)
+			}
+		}
+
+		err = emitWebhookEvent(ctx, qtx, eventLeadCreated, leadEventData{
+			leadResponse: newLeadResponse(lead),
+			Attribution:  attributionForLead(lead),
+		})
+		if err != nil {
+			return database.Lead{}, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return database.Lead{}, fmt.Errorf("failed to commit lead: %w", err)
+	}
+
+	return lead, nil
+}
+
+// leadSubmission is the body accepted by POST /api/leads. Kind selects how
+// Details is decoded and validated.
+type leadSubmission struct {
+	Kind       string          `json:"kind"`
+	FirstName  string          `json:"firstName"`
+	LastName   string          `json:"lastName"`
+	Email      string          `json:"email"`
+	Phone      string          `json:"phone"`
+	Message    string          `json:"message"`
+	Subscribed bool            `json:"subscribed"`
+	Details    json.RawMessage `json:"details"`
+	// ConsentVersion identifies the consent wording shown next to the
+	// subscribe checkbox. The configured version is recorded when empty.
+	ConsentVersion string `json:"consentVersion,omitempty"`
+	leadAttribution
+}
+
+// submissionMeta describes the request a submission arrived in, for the
+// consent record.
+type submissionMeta struct {
+	IPAddress string
+	UserAgent string
+	Route     string
+	// Imported leads come from a bulk import rather than a visitor, so the
+	// kind's follow-up, such as emailing the visitor, is skipped.
+	Imported bool
+	// SkipDelivery stores the lead without sending it to the CRM, Brevo,
+	// agents or webhook subscribers.
+	SkipDelivery bool
+	// CreatedAt, when set, is stored as the lead's creation time instead of
+	// now, so imported leads keep the date from the source file.
+	CreatedAt time.Time
+}
+
+func newSubmissionMeta(req *http.Request) submissionMeta {
+	return submissionMeta{
+		IPAddress: clientIP(req),
+		UserAgent: req.UserAgent(),
+		Route:     req.URL.Path,
+	}
+}
+
+func (cfg *apiCfg) CreateLead(w http.ResponseWriter, req *http.Request) {
+	sub := leadSubmission{}
+	decoder := json.NewDecoder(req.Body)
+	if err := decoder.Decode(&sub); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload", err)
+		return
+	}
+	if leadKinds[sub.Kind].Internal {
+		respondWithValidationErrors(w, validation.Errors{"kind": "Unknown lead kind"})
+		return
+	}
+
+	lead, err := cfg.submitLead(req.Context(), newSubmissionMeta(req), sub)
+	if err != nil {
+		respondWithLeadError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, leadCreatedResponse{ID: lead.ID.String()})
+}
+
+// leadCreatedResponse is the body of a successful CreateLead.
+type leadCreatedResponse struct {
+	ID string `json:"id"`
+}
+
+// submitLead validates a submission of any kind, scores it, stores it
+// assigned to an agent and runs the kind's follow-up. Validation failures
+// are returned as validation.Errors.
+func (cfg *apiCfg) submitLead(ctx context.Context, meta submissionMeta, sub leadSubmission) (database.Lead, error) {
+	sub, details, err := cfg.validateLead(sub)
+	if err != nil {
+		return database.Lead{}, err
+	}
+
+	payload, err := json.Marshal(sub)
+	if err != nil {
+		return database.Lead{}, fmt.Errorf("failed to marshal lead: %w", err)
+	}
+
+	// Imported leads are stored unassigned, so an import doesn't move
+	// round-robin rules along.
+	var route *routing.Lead
+	if !meta.Imported {
+		r := routingLeadForSubmission(sub, details)
+		route = &r
+	}
+
+	createdAt := meta.CreatedAt
+	if createdAt.IsZero() {
+		createdAt = time.Now()
+	}
+
+	// The same goes for an unscored one.
+	var score int32
+	rules, err := cfg.loadScoringRules(ctx)
+	if err == nil {
+		score, err = cfg.scoreLead(ctx, rules, sub, details, createdAt)
+	}
+	if err != nil {
+		log.Printf("Error scoring lead: %s", err)
+	}
+
+	lead, err := cfg.saveLead(ctx, database.CreateLeadParams{
+		LeadType:    sub.Kind,
+		FirstName:   sub.FirstName,
+		LastName:    sub.LastName,
+		Email:       sub.Email,
+		Phone:       sub.Phone,
+		Message:     sub.Message,
+		Payload:     payload,
+		Source:      sub.source(),
+		UtmSource:   sub.UTMSource,
+		UtmMedium:   sub.UTMMedium,
+		UtmCampaign: sub.UTMCampaign,
+		Gclid:       sub.GCLID,
+		Fbclid:      sub.FBCLID,
+		Referrer:    sub.Referrer,
+		LandingPage: sub.LandingPage,
+
+		MarketingConsent: sub.Subscribed,
+		Score:            score,
+		CreatedAt:        createdAt,
+	}, database.CreateConsentRecordParams{
+		Email:       sub.Email,
+		Purpose:     consentPurposeMarketing,
+		Consented:   sub.Subscribed,
+		TextVersion: sub.ConsentVersion,
+		IpAddress:   meta.IPAddress,
+		UserAgent:   meta.UserAgent,
+		Route:       meta.Route,
+	}, details, route, !meta.SkipDelivery)
+	if err != nil {
+		return database.Lead{}, err
+	}
+
+	if f, ok := details.(leadFollowUp); ok && !meta.Imported {
+		f.followUp(cfg, sub)
+	}
+
+	return lead, nil
+}
+
+// validateLead checks the contact fields and details of a submission and
+// returns it with the name, email and phone normalized.
+func (cfg *apiCfg) validateLead(sub leadSubmission) (leadSubmission, leadDetails, error) {
+	errs := validation.Errors{}
+
+	sub.FirstName = strings.TrimSpace(sub.FirstName)
+	sub.LastName = strings.TrimSpace(sub.LastName)
+	switch {
+	case sub.FirstName == "":
+		errs.Add("firstName", "First name is required")
+	case len(sub.FirstName) > maxNameLength:
+		errs.Add("firstName", "First name is too long")
+	}
+	if len(sub.LastName) > maxNameLength {
+		errs.Add("lastName", "Last name is too long")
+	}
+
+	email, err := validation.Email(sub.Email)
+	if err != nil {
+		errs.Add("email", "Enter a valid email address")
+	}
+	sub.Email = email
+
+	if phone := strings.TrimSpace(sub.Phone); phone != "" {
+		sub.Phone, err = validation.Phone(phone, cfg.PhoneRegion)
+		if err != nil {
+			errs.Add("phone", "Enter a valid phone number")
+		}
+	}
+
+	sub.leadAttribution = sub.leadAttribution.normalize()
+
+	sub.ConsentVersion = truncate(strings.TrimSpace(sub.ConsentVersion), 50)
+	if sub.ConsentVersion == "" {
+		sub.ConsentVersion = cfg.ConsentVersion
+	}
+
+	if len(sub.Message) > maxMessageLength {
+		errs.Add("message", "Message is too long")
+	}
+
+	_, details, err := decodeLeadDetails(sub)
+	if err != nil {
+		if _, ok := leadKinds[sub.Kind]; !ok {
+			errs.Add("kind", "Unknown lead kind")
+		} else {
+			errs.Add("details", "Invalid details")
+		}
+		return sub, nil, errs
+	}
+	details.validate(errs)
+
+	return sub, details, errs.Err()
+}
+
+// respondWithLeadError sends field errors as a 422 the frontend can show
+// next to each input, and anything else as a 500.
+func respondWithLeadError(w http.ResponseWriter, err error) {
+	var errs validation.Errors
+	if errors.As(err, &errs) {
+		respondWithValidationErrors(w, errs)
+		return
+	}
+	log.Printf("Error saving lead: %s", err)
+	respondWithError(w, http.StatusInternalServerError, "Could not save lead", err)
+}
+
+// crmLeadForLead builds the CRM lead for a stored lead.
+func (cfg *apiCfg) crmLeadForLead(lead database.Lead) (crm.Lead, error) {
+	sub := leadSubmission{}
+	if err := json.Unmarshal(lead.Payload, &sub); err != nil {
+		return crm.Lead{}, fmt.Errorf("failed to decode lead payload: %w", err)
+	}
+
+	kind, details, err := decodeLeadDetails(sub)
+	if err != nil {
+		return crm.Lead{}, err
+	}
+
+	attribution := attributionForLead(lead)
+
+	source := lead.Source
+	if source == "" {
+		source = kind.Source
+	}
+	if source == "" {
+		source = cfg.System
+	}
+	if source == "" {
+		source = "Realtor Website"
+	}
+
+	crmLead := crm.Lead{
+		ID:         lead.ID.String(),
+		Kind:       lead.LeadType,
+		EventType:  kind.EventType,
+		Source:     source,
+		Message:    lead.Message,
+		FirstName:  lead.FirstName,
+		LastName:   lead.LastName,
+		Email:      lead.Email,
+		Phone:      lead.Phone,
+		Campaign:   attribution.campaign(),
+		PageURL:    lead.LandingPage,
+		Referrer:   lead.Referrer,
+		AssignedTo: lead.AssignedAgent,
+		Tags:       []string{scoring.Tag(int(lead.Score))},
+	}
+	if l, ok := details.(leadLocalizer); ok && cfg.Location != nil {
+		l.setLocation(cfg.Location)
+	}
+	details.applyCRM(&crmLead)
+
+	return crmLead, nil
+}
+
+func respondWithValidationErrors(w http.ResponseWriter, errs validation.Errors) {
+	type errorResponse struct {
+		Error  string            `json:"error"`
+		Fields validation.Errors `json:"fields"`
+	}
+	respondWithJSON(w, http.StatusUnprocessableEntity, errorResponse{
+		Error:  "Please correct the highlighted fields",
+		Fields: errs,
+	})
+}
+
+// contactForLead builds the Brevo contact for a stored lead.
+func (cfg *apiCfg) contactForLead(lead database.Lead) contact {
+	listID := cfg.leadKindListID(lead.LeadType)
+
+	var listIDs []int64
+	if listID != 0 {
+		listIDs = []int64{listID}
+	}
+
+	return contact{
+		Email: lead.Email,
+		Attributes: attributes{
+			FirstName:   lead.FirstName,
+			LastName:    lead.LastName,
+			Sms:         lead.Phone,
+			Source:      lead.Source,
+			UTMSource:   lead.UtmSource,
+			UTMMedium:   lead.UtmMedium,
+			UTMCampaign: lead.UtmCampaign,
+		},
+		ListIDs:       listIDs,
+		UpdateEnabled: true,
+	}
+}
@@ -3,11 +3,14 @@ package handlers
 import (
 	"context"
 	"log"
+	"net"
 	"net/http"
 	"runtime/debug"
+	"strings"
 	"time"
 
 	"github.com/DiegoGarciaCo/websitesAPI/internal/auth"
+	"github.com/DiegoGarciaCo/websitesAPI/internal/database"
 	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
 )
@@ -43,15 +46,6 @@ func LoggerMiddleware(next http.Handler) http.Handler {
 
 		duration := time.Since(start)
 
-		// Handle client IP with load balancer
-		clientIP := r.Header.Get("X-Forwarded-For")
-		if clientIP == "" {
-			clientIP = r.Header.Get("X-Real-IP")
-		}
-		if clientIP == "" {
-			clientIP = r.RemoteAddr
-		}
-
 		// Build log fields
 		fields := logrus.Fields{
 			"request_id": requestID,
@@ -59,7 +53,7 @@ func LoggerMiddleware(next http.Handler) http.Handler {
 			"path":       r.URL.Path, // or r.URL.String() for full URL
 			"status":     wrappedWriter.statusCode,
 			"duration":   duration.Milliseconds(),
-			"client_ip":  clientIP,
+			"client_ip":  clientIP(r),
 			"timestamp":  time.Now().UTC().Format(time.RFC3339),
 		}
 
@@ -72,6 +66,24 @@ func LoggerMiddleware(next http.Handler) http.Handler {
 	})
 }
 
+// clientIP handles the client IP behind the load balancer. The load
+// balancer appends the address it saw to X-Forwarded-For, so only the last
+// hop is trusted; anything before it was sent by the client.
+func clientIP(r *http.Request) string {
+	if forwarded := r.Header.Values("X-Forwarded-For"); len(forwarded) > 0 {
+		hops := strings.Split(forwarded[len(forwarded)-1], ",")
+		if ip := strings.TrimSpace(hops[len(hops)-1]); net.ParseIP(ip) != nil {
+			return ip
+		}
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
 func RecoveryMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		defer func() {
@@ -103,13 +115,19 @@ func (cfg *apiCfg) AuthMiddleware(next http.HandlerFunc) http.HandlerFunc {
 			return
 		}
 
-		_, err = cfg.DB.GetUserByID(req.Context(), userID)
+		user, err := cfg.DB.GetUserByID(req.Context(), userID)
 		if err != nil {
 			log.Print("Error getting user: ", err)
 			respondWithError(w, http.StatusUnauthorized, "User not found", err)
 			return
 		}
 
-		next(w, req)
+		next(w, req.WithContext(context.WithValue(req.Context(), UserKey, user)))
 	}
 }
+
+// userFromContext returns the user stored by AuthMiddleware.
+func userFromContext(ctx context.Context) (database.User, bool) {
+	user, ok := ctx.Value(UserKey).(database.User)
+	return user, ok
+}
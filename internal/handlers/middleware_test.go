@@ -0,0 +1,40 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClientIP(t *testing.T) {
+	tests := []struct {
+		name      string
+		forwarded []string
+		realIP    string
+		want      string
+	}{
+		{"Direct", nil, "", "192.0.2.1"},
+		{"One hop", []string{"203.0.113.7"}, "", "203.0.113.7"},
+		{"Spoofed hops", []string{"10.0.0.1, 198.51.100.2,203.0.113.7"}, "", "203.0.113.7"},
+		{"Repeated header", []string{"10.0.0.1", "203.0.113.7"}, "", "203.0.113.7"},
+		{"Not an address", []string{"unknown"}, "", "192.0.2.1"},
+		{"Real IP ignored", nil, "10.0.0.1", "192.0.2.1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/leads", nil)
+			req.RemoteAddr = "192.0.2.1:4312"
+			for _, v := range tt.forwarded {
+				req.Header.Add("X-Forwarded-For", v)
+			}
+			if tt.realIP != "" {
+				req.Header.Set("X-Real-IP", tt.realIP)
+			}
+
+			if got := clientIP(req); got != tt.want {
+				t.Errorf("clientIP() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
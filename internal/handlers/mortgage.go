@@ -0,0 +1,244 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/DiegoGarciaCo/websitesAPI/internal/mortgage"
+	"github.com/DiegoGarciaCo/websitesAPI/internal/validation"
+)
+
+// maxAmortizationYears bounds the size of a schedule.
+const maxAmortizationYears = 50
+
+// maxInterestRate is the highest annual rate, in percent, a calculation
+// accepts. Far higher rates overflow the payment formula.
+const maxInterestRate = 30
+
+// mortgageFields maps mortgageDetails errors to the names of a bare
+// calculation request.
+var mortgageFields = map[string]string{
+	"details.price":       "price",
+	"details.interest":    "interest",
+	"details.years":       "years",
+	"details.downPayment": "downPayment",
+	"details.program":     "program",
+	"details.creditScore": "creditScore",
+	"details.state":       "state",
+	"details.county":      "county",
+	"details.zip":         "zip",
+}
+
+// programNames are the loan programs as forms show them.
+var programNames = map[string]string{
+	mortgage.ProgramConventional: "Conventional",
+	mortgage.ProgramFHA:          "FHA",
+	mortgage.ProgramVA:           "VA",
+	mortgage.ProgramUSDA:         "USDA",
+}
+
+// validateLoanProgram checks a loan program and optional credit score,
+// adding errors under prefix. It returns the program, conventional when
+// empty, and whether it is known.
+func validateLoanProgram(errs validation.Errors, prefix, program string, creditScore int) (string, bool) {
+	if program == "" {
+		program = mortgage.ProgramConventional
+	}
+	if !mortgage.ValidProgram(program) {
+		errs.Add(prefix+"program", "Program must be one of "+strings.Join(mortgage.Programs, ", "))
+		return program, false
+	}
+
+	if creditScore != 0 && (creditScore < 300 || creditScore > 850) {
+		errs.Add(prefix+"creditScore", "Credit score must be between 300 and 850")
+	} else if min := mortgage.MinCreditScore(program); creditScore != 0 && creditScore < min {
+		errs.Add(prefix+"creditScore", fmt.Sprintf("%s loans need a credit score of at least %d", programNames[program], min))
+	}
+	return program, true
+}
+
+// loan converts the calculator inputs, where the down payment is a percent
+// of the price.
+func (d *mortgageDetails) loan() mortgage.Loan {
+	return mortgage.Loan{
+		Price:       d.Price,
+		DownPayment: d.Price * (d.DownPayment / 100),
+		AnnualRate:  d.Interest,
+		Years:       d.Years,
+		Program:     d.Program,
+		CreditScore: d.CreditScore,
+
+		VASubsequentUse: d.VASubsequentUse,
+	}
+}
+
+// decodeMortgageDetails decodes and validates a calculation request, which
+// takes the same fields as mortgage lead details.
+func decodeMortgageDetails(w http.ResponseWriter, req *http.Request) (mortgageDetails, bool) {
+	details := mortgageDetails{}
+	decoder := json.NewDecoder(http.MaxBytesReader(w, req.Body, maxFormBodyBytes))
+	if err := decoder.Decode(&details); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Could not decode request", err)
+		return details, false
+	}
+
+	errs := validation.Errors{}
+	details.validate(errs)
+	errs = errs.Rename(mortgageFields)
+	if details.Years > maxAmortizationYears {
+		errs.Add("years", "Years must be at most "+strconv.Itoa(maxAmortizationYears))
+	}
+	if len(errs) > 0 {
+		respondWithValidationErrors(w, errs)
+		return details, false
+	}
+
+	if details.Program == "" {
+		details.Program = mortgage.ProgramConventional
+	}
+	return details, true
+}
+
+// MortgageBreakdown returns the monthly cost of a loan, with the tax and
+// insurance assumptions of its location. It doesn't create a lead, so the
+// calculator can call it as the inputs change.
+func (cfg *apiCfg) MortgageBreakdown(w http.ResponseWriter, req *http.Request) {
+	details, ok := decodeMortgageDetails(w, req)
+	if !ok {
+		return
+	}
+
+	assumptions, err := cfg.mortgageAssumptions(req.Context(), details.Location)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Something went wrong", err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, mortgage.Calculate(details.loan(), assumptions))
+}
+
+// Amortization returns the month-by-month schedule of a loan, as CSV with
+// ?format=csv. It doesn't create a lead.
+func (cfg *apiCfg) Amortization(w http.ResponseWriter, req *http.Request) {
+	details, ok := decodeMortgageDetails(w, req)
+	if !ok {
+		return
+	}
+
+	loan := details.loan()
+	schedule := mortgage.Amortize(loan)
+
+	if req.URL.Query().Get("format") != "csv" {
+		type response struct {
+			Price       float64 `json:"price"`
+			DownPayment float64 `json:"downPayment"`
+			Program     string  `json:"program"`
+			UpfrontFee  float64 `json:"upfrontFee"`
+			LoanAmount  float64 `json:"loanAmount"`
+			Interest    float64 `json:"interest"`
+			Years       int     `json:"years"`
+			mortgage.Schedule
+		}
+		respondWithJSON(w, http.StatusOK, response{
+			Price:       loan.Price,
+			DownPayment: loan.DownPayment,
+			Program:     loan.Program,
+			UpfrontFee:  loan.UpfrontFee(),
+			LoanAmount:  loan.Principal(),
+			Interest:    loan.AnnualRate,
+			Years:       loan.Years,
+			Schedule:    schedule,
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set("Content-Disposition", `attachment; filename="amortization.csv"`)
+	w.WriteHeader(http.StatusOK)
+
+	money := func(v float64) string { return strconv.FormatFloat(v, 'f', 2, 64) }
+	writer := csv.NewWriter(w)
+	writer.Write([]string{"month", "payment", "principal", "interest", "pmi", "balance", "cumulative_interest"})
+	for _, m := range schedule.Months {
+		writer.Write([]string{
+			strconv.Itoa(m.Number),
+			money(m.Payment),
+			money(m.Principal),
+			money(m.Interest),
+			money(m.PMI),
+			money(m.Balance),
+			money(m.CumulativeInterest),
+		})
+	}
+	writer.Flush()
+}
+
+// Affordability solves for the highest price a buyer can afford under their
+// loan program's DTI limits, with the tax and insurance assumptions of the
+// location. It doesn't create a lead.
+func (cfg *apiCfg) Affordability(w http.ResponseWriter, req *http.Request) {
+	type reqParams struct {
+		AnnualIncome float64 `json:"annualIncome"` // gross
+		MonthlyDebts float64 `json:"monthlyDebts"`
+		DownPayment  float64 `json:"downPayment"` // amount available
+		Interest     float64 `json:"interest"`
+		Years        int     `json:"years"`
+		Program      string  `json:"program"`
+		CreditScore  int     `json:"creditScore"`
+
+		VASubsequentUse bool `json:"vaSubsequentUse"`
+		mortgage.Location
+	}
+
+	params := reqParams{}
+	decoder := json.NewDecoder(http.MaxBytesReader(w, req.Body, maxFormBodyBytes))
+	if err := decoder.Decode(&params); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Could not decode request", err)
+		return
+	}
+
+	errs := validation.Errors{}
+	if params.AnnualIncome <= 0 {
+		errs.Add("annualIncome", "Income must be greater than zero")
+	}
+	if params.MonthlyDebts < 0 {
+		errs.Add("monthlyDebts", "Debts must not be negative")
+	}
+	if params.DownPayment < 0 {
+		errs.Add("downPayment", "Down payment must not be negative")
+	}
+	if params.Interest < 0 || params.Interest > maxInterestRate {
+		errs.Add("interest", "Interest must be between 0 and "+strconv.Itoa(maxInterestRate)+"%")
+	}
+	if params.Years <= 0 || params.Years > maxAmortizationYears {
+		errs.Add("years", "Years must be between 1 and "+strconv.Itoa(maxAmortizationYears))
+	}
+	program, _ := validateLoanProgram(errs, "", params.Program, params.CreditScore)
+	validateLocation(errs, "", params.Location)
+	if len(errs) > 0 {
+		respondWithValidationErrors(w, errs)
+		return
+	}
+
+	assumptions, err := cfg.mortgageAssumptions(req.Context(), params.Location)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Something went wrong", err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, mortgage.Afford(mortgage.Buyer{
+		MonthlyIncome: params.AnnualIncome / 12,
+		MonthlyDebts:  params.MonthlyDebts,
+		DownPayment:   params.DownPayment,
+		AnnualRate:    params.Interest,
+		Years:         params.Years,
+		Program:       program,
+		CreditScore:   params.CreditScore,
+
+		VASubsequentUse: params.VASubsequentUse,
+	}, assumptions))
+}
@@ -0,0 +1,251 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+	"unicode"
+
+	"github.com/DiegoGarciaCo/websitesAPI/internal/database"
+	"github.com/DiegoGarciaCo/websitesAPI/internal/mortgage"
+	"github.com/DiegoGarciaCo/websitesAPI/internal/validation"
+	"github.com/google/uuid"
+)
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if !unicode.IsDigit(r) {
+			return false
+		}
+	}
+	return true
+}
+
+func isLetters(s string) bool {
+	for _, r := range s {
+		if !unicode.IsLetter(r) {
+			return false
+		}
+	}
+	return true
+}
+
+func mortgageRuleFromRow(row database.MortgageAssumption) mortgage.Rule {
+	return mortgage.Rule{
+		Location: mortgage.Location{
+			State:  row.State,
+			County: row.County,
+			Zip:    row.Zip,
+		},
+		TaxRate:         row.TaxRate,
+		AnnualInsurance: row.AnnualInsurance,
+	}
+}
+
+// validateLocation checks the normalized form of loc, adding errors under
+// prefix.
+func validateLocation(errs validation.Errors, prefix string, loc mortgage.Location) {
+	loc = loc.Normalize()
+	if loc.State != "" && (len(loc.State) != 2 || !isLetters(loc.State)) {
+		errs.Add(prefix+"state", "State must be a two-letter code")
+	}
+	if loc.Zip != "" && (len(loc.Zip) != 5 || !isDigits(loc.Zip)) {
+		errs.Add(prefix+"zip", "ZIP code must have five digits")
+	}
+	if len(loc.County) > 100 {
+		errs.Add(prefix+"county", "County must be at most 100 characters")
+	}
+}
+
+// mortgageAssumptions resolves the tax and insurance assumptions of loc,
+// falling back to the configured defaults.
+func (cfg *apiCfg) mortgageAssumptions(ctx context.Context, loc mortgage.Location) (mortgage.Assumptions, error) {
+	if loc == (mortgage.Location{}) {
+		return mortgage.Resolve(nil, loc, cfg.MortgageDefaults), nil
+	}
+
+	rows, err := cfg.DB.ListMortgageAssumptions(ctx)
+	if err != nil {
+		return mortgage.Assumptions{}, fmt.Errorf("failed to load mortgage assumptions: %w", err)
+	}
+
+	rules := make([]mortgage.Rule, len(rows))
+	for i, row := range rows {
+		rules[i] = mortgageRuleFromRow(row)
+	}
+	return mortgage.Resolve(rules, loc, cfg.MortgageDefaults), nil
+}
+
+type mortgageAssumptionParams struct {
+	mortgage.Location
+	TaxRate         float64 `json:"taxRate"`
+	AnnualInsurance float64 `json:"annualInsurance"`
+}
+
+func (p mortgageAssumptionParams) validate() validation.Errors {
+	errs := validation.Errors{}
+	validateLocation(errs, "", p.Location)
+	if p.Zip == "" && p.State == "" {
+		errs.Add("state", "Set a state, a ZIP code or both")
+	}
+	if p.TaxRate < 0 || p.TaxRate >= 1 {
+		errs.Add("taxRate", "Tax rate must be a share of the price between 0 and 1")
+	}
+	if p.AnnualInsurance < 0 {
+		errs.Add("annualInsurance", "Insurance must not be negative")
+	}
+	return errs
+}
+
+// decodeMortgageAssumption reads, normalizes and validates an assumption
+// body. It writes the error response and returns false when the body is
+// unusable.
+func decodeMortgageAssumption(w http.ResponseWriter, req *http.Request) (mortgageAssumptionParams, bool) {
+	params := mortgageAssumptionParams{}
+	decoder := json.NewDecoder(req.Body)
+	if err := decoder.Decode(&params); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload", err)
+		return params, false
+	}
+
+	params.Location = params.Location.Normalize()
+	if errs := params.validate(); len(errs) > 0 {
+		respondWithValidationErrors(w, errs)
+		return params, false
+	}
+	return params, true
+}
+
+type mortgageAssumptionResponse struct {
+	ID              string    `json:"id"`
+	Scope           string    `json:"scope"`
+	State           string    `json:"state,omitempty"`
+	County          string    `json:"county,omitempty"`
+	Zip             string    `json:"zip,omitempty"`
+	TaxRate         float64   `json:"taxRate"`
+	AnnualInsurance float64   `json:"annualInsurance"`
+	CreatedAt       time.Time `json:"createdAt"`
+	UpdatedAt       time.Time `json:"updatedAt"`
+}
+
+func newMortgageAssumptionResponse(row database.MortgageAssumption) mortgageAssumptionResponse {
+	return mortgageAssumptionResponse{
+		ID:              row.ID.String(),
+		Scope:           mortgageRuleFromRow(row).Scope(),
+		State:           row.State,
+		County:          row.County,
+		Zip:             row.Zip,
+		TaxRate:         row.TaxRate,
+		AnnualInsurance: row.AnnualInsurance,
+		CreatedAt:       row.CreatedAt,
+		UpdatedAt:       row.UpdatedAt,
+	}
+}
+
+// ListMortgageAssumptions returns the tax and insurance assumptions along
+// with the defaults used where none match.
+func (cfg *apiCfg) ListMortgageAssumptions(w http.ResponseWriter, req *http.Request) {
+	rows, err := cfg.DB.ListMortgageAssumptions(req.Context())
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Something went wrong", err)
+		return
+	}
+
+	type response struct {
+		Defaults    mortgage.Assumptions         `json:"defaults"`
+		Assumptions []mortgageAssumptionResponse `json:"assumptions"`
+	}
+	res := response{
+		Defaults:    mortgage.Resolve(nil, mortgage.Location{}, cfg.MortgageDefaults),
+		Assumptions: make([]mortgageAssumptionResponse, len(rows)),
+	}
+	for i, row := range rows {
+		res.Assumptions[i] = newMortgageAssumptionResponse(row)
+	}
+	respondWithJSON(w, http.StatusOK, res)
+}
+
+func (cfg *apiCfg) CreateMortgageAssumption(w http.ResponseWriter, req *http.Request) {
+	params, ok := decodeMortgageAssumption(w, req)
+	if !ok {
+		return
+	}
+
+	row, err := cfg.DB.CreateMortgageAssumption(req.Context(), database.CreateMortgageAssumptionParams{
+		State:           params.State,
+		County:          params.County,
+		Zip:             params.Zip,
+		TaxRate:         params.TaxRate,
+		AnnualInsurance: params.AnnualInsurance,
+	})
+	if isPQError(err, pqUniqueViolation) {
+		respondWithError(w, http.StatusConflict, "Assumptions for this location already exist", err)
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Something went wrong", err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, newMortgageAssumptionResponse(row))
+}
+
+func (cfg *apiCfg) UpdateMortgageAssumption(w http.ResponseWriter, req *http.Request) {
+	UUID, err := uuid.Parse(req.PathValue("id"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid UUID", err)
+		return
+	}
+
+	params, ok := decodeMortgageAssumption(w, req)
+	if !ok {
+		return
+	}
+
+	row, err := cfg.DB.UpdateMortgageAssumption(req.Context(), database.UpdateMortgageAssumptionParams{
+		ID:              UUID,
+		State:           params.State,
+		County:          params.County,
+		Zip:             params.Zip,
+		TaxRate:         params.TaxRate,
+		AnnualInsurance: params.AnnualInsurance,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusNotFound, "Mortgage assumption not found", err)
+		return
+	}
+	if isPQError(err, pqUniqueViolation) {
+		respondWithError(w, http.StatusConflict, "Assumptions for this location already exist", err)
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Something went wrong", err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, newMortgageAssumptionResponse(row))
+}
+
+func (cfg *apiCfg) DeleteMortgageAssumption(w http.ResponseWriter, req *http.Request) {
+	UUID, err := uuid.Parse(req.PathValue("id"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid UUID", err)
+		return
+	}
+
+	deleted, err := cfg.DB.DeleteMortgageAssumption(req.Context(), UUID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Something went wrong", err)
+		return
+	}
+	if deleted == 0 {
+		respondWithError(w, http.StatusNotFound, "Mortgage assumption not found", nil)
+		return
+	}
+
+	respondWithJSON(w, http.StatusNoContent, nil)
+}
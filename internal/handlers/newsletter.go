@@ -0,0 +1,447 @@
+package handlers
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/DiegoGarciaCo/websitesAPI/internal/database"
+	"github.com/DiegoGarciaCo/websitesAPI/internal/emailevents"
+	"github.com/DiegoGarciaCo/websitesAPI/internal/validation"
+	"github.com/google/uuid"
+)
+
+const (
+	consentPurposeNewsletter = "newsletter"
+	// newsletterConfirmTTL is how long a confirmation link stays valid.
+	newsletterConfirmTTL = 48 * time.Hour
+)
+
+// NewsletterConfig configures double opt-in newsletter subscriptions.
+type NewsletterConfig struct {
+	ListID     int64  // Brevo list confirmed subscribers join
+	TemplateID int64  // Brevo template for the confirmation email
+	ConfirmURL string // page that posts the token to /api/newsletter/confirm
+	TokenKey   string // signs confirmation links
+}
+
+func (c NewsletterConfig) enabled() bool {
+	return c.ListID != 0 && c.TemplateID != 0 && c.ConfirmURL != "" && c.TokenKey != ""
+}
+
+var (
+	errInvalidNewsletterToken = errors.New("invalid newsletter token")
+	errExpiredNewsletterToken = errors.New("newsletter token expired")
+)
+
+// newsletterToken signs a subscription ID and the link's expiry for its
+// confirmation link. The expiry is part of the signed token, so a link
+// stops working when it expires even if resubscribing later extends the
+// subscription; that sends a new link.
+func (cfg *apiCfg) newsletterToken(id uuid.UUID, expires time.Time) string {
+	payload := id.String() + "." + strconv.FormatInt(expires.Unix(), 10)
+	return payload + "." + cfg.newsletterSignature(payload)
+}
+
+func (cfg *apiCfg) newsletterSignature(payload string) string {
+	mac := hmac.New(sha256.New, []byte(cfg.Newsletter.TokenKey))
+	mac.Write([]byte("newsletter-confirm:" + payload))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// parseNewsletterToken returns the subscription ID of a validly signed
+// token that hasn't expired by now.
+func (cfg *apiCfg) parseNewsletterToken(token string, now time.Time) (uuid.UUID, error) {
+	i := strings.LastIndex(token, ".")
+	if i < 0 {
+		return uuid.Nil, errInvalidNewsletterToken
+	}
+	payload, signature := token[:i], token[i+1:]
+	if !hmac.Equal([]byte(signature), []byte(cfg.newsletterSignature(payload))) {
+		return uuid.Nil, errInvalidNewsletterToken
+	}
+
+	id, expires, ok := strings.Cut(payload, ".")
+	if !ok {
+		return uuid.Nil, errInvalidNewsletterToken
+	}
+	UUID, err := uuid.Parse(id)
+	if err != nil {
+		return uuid.Nil, errInvalidNewsletterToken
+	}
+	unix, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil {
+		return uuid.Nil, errInvalidNewsletterToken
+	}
+	if now.After(time.Unix(unix, 0)) {
+		return uuid.Nil, errExpiredNewsletterToken
+	}
+	return UUID, nil
+}
+
+func (cfg *apiCfg) newsletterConfirmLink(s database.NewsletterSubscription) (string, error) {
+	u, err := url.Parse(cfg.Newsletter.ConfirmURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid newsletter confirm URL: %w", err)
+	}
+	query := u.Query()
+	query.Set("token", cfg.newsletterToken(s.ID, s.ExpiresAt))
+	u.RawQuery = query.Encode()
+	return u.String(), nil
+}
+
+// newsletterSignupPolicy decides a subscribe request by the address's
+// suppression reason. Bounced and complaining addresses get no email. An
+// address that unsubscribed may join again: its subscription goes back to
+// pending with a new link, and confirming clears the unsubscribe.
+func newsletterSignupPolicy(reason string) (allowed, reopen bool) {
+	switch reason {
+	case emailevents.HardBounce, emailevents.Spam:
+		return false, false
+	case emailevents.Unsubscribe:
+		return true, true
+	}
+	return true, false
+}
+
+func newsletterStatus(s database.NewsletterSubscription, now time.Time) string {
+	if s.Status == "pending" && now.After(s.ExpiresAt) {
+		return "expired"
+	}
+	return s.Status
+}
+
+// sendNewsletterConfirmation emails the confirmation link of a pending
+// subscription.
+func (cfg *apiCfg) sendNewsletterConfirmation(ctx context.Context, s database.NewsletterSubscription) error {
+	link, err := cfg.newsletterConfirmLink(s)
+	if err != nil {
+		return err
+	}
+
+	// Sent even after an unsubscribe: the visitor just asked to join again.
+	return cfg.sendTemplateEmail(ctx, []string{s.Email}, cfg.Newsletter.TemplateID, map[string]any{
+		"firstName":  s.FirstName,
+		"confirmUrl": link,
+		"expiresAt":  s.ExpiresAt.In(cfg.Location).Format("January 2, 2006 3:04 PM MST"),
+	})
+}
+
+// SubscribeNewsletter starts a double opt-in subscription: it stores a
+// pending subscription and emails a signed confirmation link. Subscribing
+// again while pending sends a fresh link; confirmed addresses get the same
+// response without an email, so the endpoint doesn't reveal who is
+// subscribed. A confirmed address that unsubscribed since goes back to
+// pending and gets a new link.
+func (cfg *apiCfg) SubscribeNewsletter(w http.ResponseWriter, req *http.Request) {
+	type reqParams struct {
+		Email     string `json:"email"`
+		FirstName string `json:"firstName"`
+		// ConsentVersion identifies the wording shown next to the form. The
+		// configured version is recorded when empty.
+		ConsentVersion string `json:"consentVersion,omitempty"`
+	}
+
+	if !cfg.Newsletter.enabled() {
+		respondWithError(w, http.StatusServiceUnavailable, "Newsletter signup is not available", nil)
+		return
+	}
+
+	params := reqParams{}
+	decoder := json.NewDecoder(req.Body)
+	if err := decoder.Decode(&params); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload", err)
+		return
+	}
+
+	errs := validation.Errors{}
+	email, err := validation.Email(params.Email)
+	if err != nil {
+		errs.Add("email", "Enter a valid email address")
+	}
+	params.FirstName = strings.TrimSpace(params.FirstName)
+	if len(params.FirstName) > maxNameLength {
+		errs.Add("firstName", "First name is too long")
+	}
+	if len(errs) > 0 {
+		respondWithValidationErrors(w, errs)
+		return
+	}
+
+	consentVersion := truncate(strings.TrimSpace(params.ConsentVersion), 50)
+	if consentVersion == "" {
+		consentVersion = cfg.ConsentVersion
+	}
+
+	ctx := req.Context()
+	reason, err := cfg.emailSuppression(ctx, email)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Something went wrong", err)
+		return
+	}
+	allowed, reopen := newsletterSignupPolicy(reason)
+	if !allowed {
+		// Same response, so the endpoint doesn't reveal suppressed addresses
+		respondWithJSON(w, http.StatusAccepted, nil)
+		return
+	}
+
+	subscription, err := cfg.DB.UpsertNewsletterSubscription(ctx, database.UpsertNewsletterSubscriptionParams{
+		Email:          email,
+		FirstName:      params.FirstName,
+		ConsentVersion: consentVersion,
+		IpAddress:      clientIP(req),
+		UserAgent:      req.UserAgent(),
+		ExpiresAt:      time.Now().Add(newsletterConfirmTTL),
+		Reopen:         reopen,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		// Already confirmed
+		respondWithJSON(w, http.StatusAccepted, nil)
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Something went wrong", err)
+		return
+	}
+
+	if err := cfg.sendNewsletterConfirmation(ctx, subscription); err != nil {
+		respondWithError(w, http.StatusBadGateway, "Could not send the confirmation email", err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusAccepted, nil)
+}
+
+// ConfirmNewsletter confirms a subscription from the token in its
+// confirmation link and adds the contact to the newsletter list. The
+// frontend page the link opens posts the token, so link scanners that
+// prefetch the URL don't confirm on the visitor's behalf. Confirming twice
+// succeeds.
+func (cfg *apiCfg) ConfirmNewsletter(w http.ResponseWriter, req *http.Request) {
+	type reqParams struct {
+		Token string `json:"token"`
+	}
+	type resParams struct {
+		Email  string `json:"email"`
+		Status string `json:"status"`
+	}
+
+	if !cfg.Newsletter.enabled() {
+		respondWithError(w, http.StatusServiceUnavailable, "Newsletter signup is not available", nil)
+		return
+	}
+
+	params := reqParams{}
+	decoder := json.NewDecoder(req.Body)
+	if err := decoder.Decode(&params); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload", err)
+		return
+	}
+
+	id, err := cfg.parseNewsletterToken(strings.TrimSpace(params.Token), time.Now())
+	if errors.Is(err, errExpiredNewsletterToken) {
+		respondWithError(w, http.StatusGone, "This confirmation link has expired. Please subscribe again.", nil)
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid confirmation link", err)
+		return
+	}
+
+	ctx := req.Context()
+	subscription, err := cfg.DB.GetNewsletterSubscription(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusNotFound, "Subscription not found", err)
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Something went wrong", err)
+		return
+	}
+
+	switch newsletterStatus(subscription, time.Now()) {
+	case "confirmed":
+		respondWithJSON(w, http.StatusOK, resParams{Email: subscription.Email, Status: subscription.Status})
+		return
+	case "expired":
+		respondWithError(w, http.StatusGone, "This confirmation link has expired. Please subscribe again.", nil)
+		return
+	}
+
+	reason, err := cfg.emailSuppression(ctx, subscription.Email)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Something went wrong", err)
+		return
+	}
+	if reason == emailevents.HardBounce || reason == emailevents.Spam {
+		respondWithError(w, http.StatusConflict, "This address can't receive the newsletter", nil)
+		return
+	}
+
+	// Add the contact before marking the subscription confirmed, so a Brevo
+	// failure leaves it pending for another click. Confirming is a fresh
+	// opt-in, so it clears an earlier unsubscribe in Brevo and here.
+	resubscribed := false
+	err = cfg.CreateContact(contact{
+		Email: subscription.Email,
+		Attributes: attributes{
+			FirstName: subscription.FirstName,
+		},
+		ListIDs:          []int64{cfg.Newsletter.ListID},
+		UpdateEnabled:    true,
+		EmailBlacklisted: &resubscribed,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusBadGateway, "Could not complete the subscription", err)
+		return
+	}
+
+	tx, err := cfg.SQLDB.BeginTx(ctx, nil)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Something went wrong", err)
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.DB.WithTx(tx)
+
+	confirmed, err := qtx.ConfirmNewsletterSubscription(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		// Confirmed by a concurrent request
+		respondWithJSON(w, http.StatusOK, resParams{Email: subscription.Email, Status: "confirmed"})
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Something went wrong", err)
+		return
+	}
+
+	_, err = qtx.DeleteEmailSuppressionByEmail(ctx, database.DeleteEmailSuppressionByEmailParams{
+		Email:  confirmed.Email,
+		Erased: cfg.erasedEmail(confirmed.Email),
+		Reason: emailevents.Unsubscribe,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Something went wrong", err)
+		return
+	}
+
+	_, err = qtx.CreateConsentRecord(ctx, database.CreateConsentRecordParams{
+		Email:       confirmed.Email,
+		Purpose:     consentPurposeNewsletter,
+		Consented:   true,
+		TextVersion: confirmed.ConsentVersion,
+		IpAddress:   clientIP(req),
+		UserAgent:   req.UserAgent(),
+		Route:       req.URL.Path,
+	})
+	if err == nil {
+		err = tx.Commit()
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Something went wrong", err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, resParams{Email: confirmed.Email, Status: confirmed.Status})
+}
+
+type newsletterSubscriptionResponse struct {
+	ID             string     `json:"id"`
+	Email          string     `json:"email"`
+	FirstName      string     `json:"firstName"`
+	Status         string     `json:"status"` // pending, confirmed or expired
+	ConsentVersion string     `json:"consentVersion"`
+	ExpiresAt      time.Time  `json:"expiresAt"`
+	ConfirmedAt    *time.Time `json:"confirmedAt,omitempty"`
+	CreatedAt      time.Time  `json:"createdAt"`
+}
+
+func newNewsletterSubscriptionResponse(s database.NewsletterSubscription, now time.Time) newsletterSubscriptionResponse {
+	res := newsletterSubscriptionResponse{
+		ID:             s.ID.String(),
+		Email:          s.Email,
+		FirstName:      s.FirstName,
+		Status:         newsletterStatus(s, now),
+		ConsentVersion: s.ConsentVersion,
+		ExpiresAt:      s.ExpiresAt,
+		CreatedAt:      s.CreatedAt,
+	}
+	if s.ConfirmedAt.Valid {
+		res.ConfirmedAt = &s.ConfirmedAt.Time
+	}
+	return res
+}
+
+// ListNewsletterSubscriptions pages through subscriptions, newest first,
+// optionally filtered by ?status=pending, confirmed or expired.
+func (cfg *apiCfg) ListNewsletterSubscriptions(w http.ResponseWriter, req *http.Request) {
+	type resParams struct {
+		Subscriptions []newsletterSubscriptionResponse `json:"subscriptions"`
+		Total         int64                            `json:"total"`
+		Page          int                              `json:"page"`
+		PageSize      int                              `json:"pageSize"`
+	}
+
+	query := req.URL.Query()
+
+	page, err := queryInt(query, "page", 1)
+	if err != nil || page < 1 {
+		respondWithError(w, http.StatusBadRequest, "Invalid page", err)
+		return
+	}
+	pageSize, err := queryInt(query, "pageSize", defaultLeadPageSize)
+	if err != nil || pageSize < 1 || pageSize > maxLeadPageSize {
+		respondWithError(w, http.StatusBadRequest, "Invalid pageSize", err)
+		return
+	}
+
+	var status sql.NullString
+	switch v := query.Get("status"); v {
+	case "":
+	case "pending", "confirmed", "expired":
+		status = sql.NullString{String: v, Valid: true}
+	default:
+		respondWithError(w, http.StatusBadRequest, "Invalid status", nil)
+		return
+	}
+
+	subscriptions, err := cfg.DB.ListNewsletterSubscriptions(req.Context(), database.ListNewsletterSubscriptionsParams{
+		Status: status,
+		Limit:  int32(pageSize),
+		Offset: int32((page - 1) * pageSize),
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Something went wrong", err)
+		return
+	}
+
+	total, err := cfg.DB.CountNewsletterSubscriptions(req.Context(), status)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Something went wrong", err)
+		return
+	}
+
+	now := time.Now()
+	res := resParams{
+		Subscriptions: make([]newsletterSubscriptionResponse, len(subscriptions)),
+		Total:         total,
+		Page:          page,
+		PageSize:      pageSize,
+	}
+	for i, s := range subscriptions {
+		res.Subscriptions[i] = newNewsletterSubscriptionResponse(s, now)
+	}
+
+	respondWithJSON(w, http.StatusOK, res)
+}
@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DiegoGarciaCo/websitesAPI/internal/emailevents"
+	"github.com/google/uuid"
+)
+
+func TestParseNewsletterToken(t *testing.T) {
+	cfg := &apiCfg{Newsletter: NewsletterConfig{TokenKey: "newsletter-key"}}
+	id := uuid.New()
+	now := time.Date(2026, 5, 2, 15, 0, 0, 0, time.UTC)
+	token := cfg.newsletterToken(id, now.Add(newsletterConfirmTTL))
+
+	other := &apiCfg{Newsletter: NewsletterConfig{TokenKey: "other-key"}}
+	forged := uuid.NewString() + token[len(id.String()):]
+
+	tests := []struct {
+		name  string
+		cfg   *apiCfg
+		token string
+		now   time.Time
+		want  error
+	}{
+		{"Valid", cfg, token, now, nil},
+		{"Expired", cfg, token, now.Add(newsletterConfirmTTL + time.Second), errExpiredNewsletterToken},
+		{"Other key", other, token, now, errInvalidNewsletterToken},
+		{"Other subscription", cfg, forged, now, errInvalidNewsletterToken},
+		{"Extended expiry", cfg, id.String() + ".9999999999" + token[len(token)-65:], now, errInvalidNewsletterToken},
+		{"Malformed", cfg, "not-a-token", now, errInvalidNewsletterToken},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.cfg.parseNewsletterToken(tt.token, tt.now)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("parseNewsletterToken() error = %v, want %v", err, tt.want)
+			}
+			if err == nil && got != id {
+				t.Errorf("parseNewsletterToken() = %s, want %s", got, id)
+			}
+		})
+	}
+}
+
+func TestNewsletterSignupPolicy(t *testing.T) {
+	tests := []struct {
+		reason      string
+		wantAllowed bool
+		wantReopen  bool
+	}{
+		{"", true, false},
+		{emailevents.Unsubscribe, true, true},
+		{emailevents.HardBounce, false, false},
+		{emailevents.Spam, false, false},
+	}
+
+	for _, tt := range tests {
+		allowed, reopen := newsletterSignupPolicy(tt.reason)
+		if allowed != tt.wantAllowed || reopen != tt.wantReopen {
+			t.Errorf("newsletterSignupPolicy(%q) = %v, %v, want %v, %v", tt.reason, allowed, reopen, tt.wantAllowed, tt.wantReopen)
+		}
+	}
+}
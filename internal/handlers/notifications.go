@@ -0,0 +1,312 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/DiegoGarciaCo/websitesAPI/internal/database"
+	"github.com/DiegoGarciaCo/websitesAPI/internal/validation"
+	"github.com/google/uuid"
+)
+
+const (
+	notificationRuleDefault = "*"
+	digestBatchSize         = 100
+)
+
+// leadNotification is the summary of a lead passed to notification
+// templates as params.
+type leadNotification struct {
+	ID        string         `json:"ID"`
+	Type      string         `json:"Type"`
+	EventType string         `json:"EventType"`
+	FirstName string         `json:"FirstName"`
+	LastName  string         `json:"LastName"`
+	Email     string         `json:"Email"`
+	Phone     string         `json:"Phone"`
+	Message   string         `json:"Message"`
+	Source    string         `json:"Source"`
+	Address   string         `json:"Address,omitempty"`
+	Details   map[string]any `json:"Details,omitempty"`
+	CreatedAt string         `json:"CreatedAt"`
+}
+
+func (cfg *apiCfg) leadNotificationForLead(lead database.Lead) (leadNotification, error) {
+	crmLead, err := cfg.crmLeadForLead(lead)
+	if err != nil {
+		return leadNotification{}, err
+	}
+
+	n := leadNotification{
+		ID:        lead.ID.String(),
+		Type:      lead.LeadType,
+		EventType: crmLead.EventType,
+		FirstName: lead.FirstName,
+		LastName:  lead.LastName,
+		Email:     lead.Email,
+		Phone:     lead.Phone,
+		Message:   lead.Message,
+		Source:    crmLead.Source,
+		CreatedAt: lead.CreatedAt.Format(time.RFC1123),
+	}
+	if a := crmLead.Address; a != nil {
+		n.Address = strings.TrimSpace(fmt.Sprintf("%s, %s, %s %s", a.Street, a.City, a.State, a.Zip))
+	}
+	if p := crmLead.Property; p != nil {
+		n.Address = strings.Trim(strings.TrimSpace(fmt.Sprintf("%s, %s, %s %s", p.Street, p.City, p.State, p.Zip)), ", ")
+		if p.MLSNumber != "" {
+			n.Address = strings.TrimSpace(n.Address + " (MLS " + p.MLSNumber + ")")
+		}
+	}
+
+	sub := leadSubmission{}
+	if err := json.Unmarshal(lead.Payload, &sub); err == nil && len(sub.Details) > 0 {
+		_ = json.Unmarshal(sub.Details, &n.Details)
+	}
+
+	return n, nil
+}
+
+// notifyAgents emails the recipients of the lead's notification rule, or
+// holds the notification for the digest during quiet hours. Leads without
+// an enabled rule are skipped.
+func (cfg *apiCfg) notifyAgents(ctx context.Context, lead database.Lead) error {
+	rule, err := cfg.DB.GetNotificationRuleForLeadType(ctx, lead.LeadType)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to load notification rule: %w", err)
+	}
+	if !rule.Enabled || len(rule.Recipients) == 0 {
+		return nil
+	}
+
+	if rule.RespectQuietHours && cfg.Notify.QuietHours.Active(time.Now()) {
+		return cfg.DB.CreateNotificationDigestItem(ctx, database.CreateNotificationDigestItemParams{
+			LeadID:     lead.ID,
+			Recipients: rule.Recipients,
+		})
+	}
+
+	notification, err := cfg.leadNotificationForLead(lead)
+	if err != nil {
+		return err
+	}
+	return cfg.SendTemplateEmail(ctx, rule.Recipients, int64(rule.TemplateID), notification)
+}
+
+// processNotificationDigest sends the notifications held during quiet
+// hours, one email per recipient list, once quiet hours are over. Items are
+// locked for the duration so replicas never send the same digest twice.
+func (cfg *apiCfg) processNotificationDigest(ctx context.Context) {
+	if cfg.Notify.QuietHours.Active(time.Now()) {
+		return
+	}
+
+	tx, err := cfg.SQLDB.BeginTx(ctx, nil)
+	if err != nil {
+		log.Printf("Error starting digest transaction: %s", err)
+		return
+	}
+	defer tx.Rollback()
+
+	qtx := cfg.DB.WithTx(tx)
+
+	items, err := qtx.ClaimNotificationDigestItems(ctx, digestBatchSize)
+	if err != nil {
+		log.Printf("Error claiming digest items: %s", err)
+		return
+	}
+	if len(items) == 0 {
+		return
+	}
+
+	groups := map[string][]database.NotificationDigestItem{}
+	for _, item := range items {
+		key := strings.Join(item.Recipients, ",")
+		groups[key] = append(groups[key], item)
+	}
+
+	for _, group := range groups {
+		ids := make([]uuid.UUID, len(group))
+		var attempts int32
+		for i, item := range group {
+			ids[i] = item.ID
+			attempts = max(attempts, item.Attempts)
+		}
+
+		if err := cfg.sendDigest(ctx, group); err != nil {
+			// Failed digests back off like lead deliveries and give up
+			// after outboxMaxAttempts.
+			attempts++
+			log.Printf("Error sending notification digest to %s (attempt %d): %s", strings.Join(group[0].Recipients, ", "), attempts, err)
+			err = qtx.MarkNotificationDigestItemsFailed(ctx, database.MarkNotificationDigestItemsFailedParams{
+				LastError:     sql.NullString{String: err.Error(), Valid: true},
+				NextAttemptAt: time.Now().Add(outboxBackoff(attempts)),
+				MaxAttempts:   outboxMaxAttempts,
+				Ids:           ids,
+			})
+			if err != nil {
+				log.Printf("Error rescheduling digest items: %s", err)
+			}
+			continue
+		}
+
+		if err := qtx.MarkNotificationDigestItemsSent(ctx, ids); err != nil {
+			log.Printf("Error marking digest items sent: %s", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("Error committing digest: %s", err)
+	}
+}
+
+func (cfg *apiCfg) sendDigest(ctx context.Context, items []database.NotificationDigestItem) error {
+	type digestParams struct {
+		Count int                `json:"Count"`
+		Leads []leadNotification `json:"Leads"`
+	}
+
+	params := digestParams{}
+	for _, item := range items {
+		lead, err := cfg.DB.GetLeadByID(ctx, item.LeadID)
+		if err != nil {
+			return fmt.Errorf("failed to load lead %s: %w", item.LeadID, err)
+		}
+		notification, err := cfg.leadNotificationForLead(lead)
+		if err != nil {
+			return err
+		}
+		params.Leads = append(params.Leads, notification)
+	}
+	params.Count = len(params.Leads)
+
+	return cfg.SendTemplateEmail(ctx, items[0].Recipients, cfg.Notify.DigestTemplateID, params)
+}
+
+type notificationRuleResponse struct {
+	LeadType          string    `json:"leadType"`
+	Recipients        []string  `json:"recipients"`
+	TemplateID        int32     `json:"templateId"`
+	Enabled           bool      `json:"enabled"`
+	RespectQuietHours bool      `json:"respectQuietHours"`
+	UpdatedAt         time.Time `json:"updatedAt"`
+}
+
+func newNotificationRuleResponse(rule database.NotificationRule) notificationRuleResponse {
+	return notificationRuleResponse{
+		LeadType:          rule.LeadType,
+		Recipients:        rule.Recipients,
+		TemplateID:        rule.TemplateID,
+		Enabled:           rule.Enabled,
+		RespectQuietHours: rule.RespectQuietHours,
+		UpdatedAt:         rule.UpdatedAt,
+	}
+}
+
+func (cfg *apiCfg) ListNotificationRules(w http.ResponseWriter, req *http.Request) {
+	rules, err := cfg.DB.ListNotificationRules(req.Context())
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Something went wrong", err)
+		return
+	}
+
+	res := make([]notificationRuleResponse, len(rules))
+	for i, rule := range rules {
+		res[i] = newNotificationRuleResponse(rule)
+	}
+	respondWithJSON(w, http.StatusOK, res)
+}
+
+// PutNotificationRule creates or replaces the rule for a lead type, or for
+// "*" to cover every type without its own rule.
+func (cfg *apiCfg) PutNotificationRule(w http.ResponseWriter, req *http.Request) {
+	type reqParams struct {
+		Recipients        []string `json:"recipients"`
+		TemplateID        int32    `json:"templateId"`
+		Enabled           *bool    `json:"enabled"`
+		RespectQuietHours *bool    `json:"respectQuietHours"`
+	}
+
+	leadType := req.PathValue("leadType")
+	if _, ok := leadKinds[leadType]; !ok && leadType != notificationRuleDefault {
+		respondWithError(w, http.StatusNotFound, "Unknown lead type", nil)
+		return
+	}
+
+	params := reqParams{}
+	decoder := json.NewDecoder(req.Body)
+	if err := decoder.Decode(&params); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload", err)
+		return
+	}
+
+	errs := validation.Errors{}
+	recipients := []string{}
+	for _, r := range params.Recipients {
+		email, err := validation.Email(r)
+		if err != nil {
+			errs.Add("recipients", fmt.Sprintf("%q is not a valid email address", r))
+			continue
+		}
+		if !slices.Contains(recipients, email) {
+			recipients = append(recipients, email)
+		}
+	}
+	if len(params.Recipients) == 0 {
+		errs.Add("recipients", "Add at least one recipient")
+	}
+	if params.TemplateID <= 0 {
+		errs.Add("templateId", "Template ID is required")
+	}
+	if len(errs) > 0 {
+		respondWithValidationErrors(w, errs)
+		return
+	}
+
+	enabled, respectQuietHours := true, true
+	if params.Enabled != nil {
+		enabled = *params.Enabled
+	}
+	if params.RespectQuietHours != nil {
+		respectQuietHours = *params.RespectQuietHours
+	}
+
+	rule, err := cfg.DB.UpsertNotificationRule(req.Context(), database.UpsertNotificationRuleParams{
+		LeadType:          leadType,
+		Recipients:        recipients,
+		TemplateID:        params.TemplateID,
+		Enabled:           enabled,
+		RespectQuietHours: respectQuietHours,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Something went wrong", err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, newNotificationRuleResponse(rule))
+}
+
+func (cfg *apiCfg) DeleteNotificationRule(w http.ResponseWriter, req *http.Request) {
+	deleted, err := cfg.DB.DeleteNotificationRule(req.Context(), req.PathValue("leadType"))
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Something went wrong", err)
+		return
+	}
+	if deleted == 0 {
+		respondWithError(w, http.StatusNotFound, "Notification rule not found", nil)
+		return
+	}
+
+	respondWithJSON(w, http.StatusNoContent, nil)
+}
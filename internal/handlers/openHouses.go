@@ -0,0 +1,507 @@
+package handlers
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/DiegoGarciaCo/websitesAPI/internal/auth"
+	"github.com/DiegoGarciaCo/websitesAPI/internal/database"
+	"github.com/DiegoGarciaCo/websitesAPI/internal/validation"
+	"github.com/google/uuid"
+)
+
+const (
+	// maxSignInBatch caps how many sign-ins one upload may carry.
+	maxSignInBatch = 200
+	// signInRateLimit caps the sign-ins an open house accepts per
+	// signInRateWindow, across uploads and replicas.
+	signInRateLimit  = 300
+	signInRateWindow = time.Hour
+	// signInGrace lets visitors sign in a little before an open house
+	// starts and after it ends.
+	signInGrace = time.Hour
+	// deviceTokenHeader carries the token issued to an open house's
+	// sign-in devices.
+	deviceTokenHeader = "X-Device-Token"
+)
+
+var errDuplicateSignIn = errors.New("sign-in already recorded")
+
+type openHouseResponse struct {
+	ID        string    `json:"id"`
+	Address   string    `json:"address"`
+	City      string    `json:"city"`
+	State     string    `json:"state"`
+	Zip       string    `json:"zip"`
+	MLSNumber string    `json:"mlsNumber"`
+	StartsAt  time.Time `json:"startsAt"`
+	EndsAt    time.Time `json:"endsAt"`
+	CreatedAt time.Time `json:"createdAt"`
+	// DeviceToken is only returned when it is issued.
+	DeviceToken string `json:"deviceToken,omitempty"`
+}
+
+func newOpenHouseResponse(oh database.OpenHouse) openHouseResponse {
+	return openHouseResponse{
+		ID:        oh.ID.String(),
+		Address:   oh.Address,
+		City:      oh.City,
+		State:     oh.State,
+		Zip:       oh.Zip,
+		MLSNumber: oh.MlsNumber,
+		StartsAt:  oh.StartsAt,
+		EndsAt:    oh.EndsAt,
+		CreatedAt: oh.CreatedAt,
+	}
+}
+
+func (cfg *apiCfg) CreateOpenHouse(w http.ResponseWriter, req *http.Request) {
+	type reqParams struct {
+		Address   string    `json:"address"`
+		City      string    `json:"city"`
+		State     string    `json:"state"`
+		Zip       string    `json:"zip"`
+		MLSNumber string    `json:"mlsNumber"`
+		StartsAt  time.Time `json:"startsAt"`
+		EndsAt    time.Time `json:"endsAt"`
+	}
+
+	params := reqParams{}
+	decoder := json.NewDecoder(req.Body)
+	if err := decoder.Decode(&params); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload", err)
+		return
+	}
+
+	params.Address = strings.TrimSpace(params.Address)
+	params.MLSNumber = strings.TrimSpace(params.MLSNumber)
+
+	errs := validation.Errors{}
+	switch {
+	case params.Address == "":
+		errs.Add("address", "Address is required")
+	case len(params.Address) > 255:
+		errs.Add("address", "Address is too long")
+	}
+	if len(params.City) > 100 {
+		errs.Add("city", "City is too long")
+	}
+	if len(params.State) > 50 {
+		errs.Add("state", "State is too long")
+	}
+	if len(params.Zip) > 20 {
+		errs.Add("zip", "Zip is too long")
+	}
+	if len(params.MLSNumber) > 50 {
+		errs.Add("mlsNumber", "MLS number is too long")
+	}
+	if params.StartsAt.IsZero() {
+		errs.Add("startsAt", "Start time is required")
+	}
+	if !params.EndsAt.After(params.StartsAt) {
+		errs.Add("endsAt", "End time must be after the start time")
+	}
+	if len(errs) > 0 {
+		respondWithValidationErrors(w, errs)
+		return
+	}
+
+	oh, err := cfg.DB.CreateOpenHouse(req.Context(), database.CreateOpenHouseParams{
+		Address:   params.Address,
+		City:      strings.TrimSpace(params.City),
+		State:     strings.TrimSpace(params.State),
+		Zip:       strings.TrimSpace(params.Zip),
+		MlsNumber: params.MLSNumber,
+		StartsAt:  params.StartsAt,
+		EndsAt:    params.EndsAt,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Something went wrong", err)
+		return
+	}
+
+	res := newOpenHouseResponse(oh)
+	res.DeviceToken, err = cfg.issueDeviceToken(req.Context(), oh.ID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Something went wrong", err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, res)
+}
+
+// issueDeviceToken replaces the token sign-in devices send for an open
+// house. Only its hash is stored.
+func (cfg *apiCfg) issueDeviceToken(ctx context.Context, openHouseID uuid.UUID) (string, error) {
+	token, err := auth.MakeToken()
+	if err != nil {
+		return "", fmt.Errorf("failed to make device token: %w", err)
+	}
+
+	hash := sha256.Sum256([]byte(token))
+	n, err := cfg.DB.SetOpenHouseDeviceToken(ctx, database.SetOpenHouseDeviceTokenParams{
+		ID:              openHouseID,
+		DeviceTokenHash: hash[:],
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to store device token: %w", err)
+	}
+	if n == 0 {
+		return "", sql.ErrNoRows
+	}
+	return token, nil
+}
+
+// validDeviceToken reports whether token was issued for oh.
+func validDeviceToken(oh database.OpenHouse, token string) bool {
+	if len(oh.DeviceTokenHash) == 0 || token == "" {
+		return false
+	}
+	hash := sha256.Sum256([]byte(token))
+	return subtle.ConstantTimeCompare(hash[:], oh.DeviceTokenHash) == 1
+}
+
+// IssueOpenHouseDeviceToken issues a new device token for an open house,
+// revoking the previous one.
+func (cfg *apiCfg) IssueOpenHouseDeviceToken(w http.ResponseWriter, req *http.Request) {
+	type resParams struct {
+		DeviceToken string `json:"deviceToken"`
+	}
+
+	oh, ok := cfg.openHouseFromPath(w, req)
+	if !ok {
+		return
+	}
+
+	token, err := cfg.issueDeviceToken(req.Context(), oh.ID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Something went wrong", err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, resParams{DeviceToken: token})
+}
+
+func (cfg *apiCfg) ListOpenHouses(w http.ResponseWriter, req *http.Request) {
+	type resParams struct {
+		OpenHouses []openHouseResponse `json:"openHouses"`
+		Total      int64               `json:"total"`
+		Page       int                 `json:"page"`
+		PageSize   int                 `json:"pageSize"`
+	}
+
+	query := req.URL.Query()
+
+	page, err := queryInt(query, "page", 1)
+	if err != nil || page < 1 {
+		respondWithError(w, http.StatusBadRequest, "Invalid page", err)
+		return
+	}
+	pageSize, err := queryInt(query, "pageSize", defaultLeadPageSize)
+	if err != nil || pageSize < 1 || pageSize > maxLeadPageSize {
+		respondWithError(w, http.StatusBadRequest, "Invalid pageSize", err)
+		return
+	}
+
+	openHouses, err := cfg.DB.ListOpenHouses(req.Context(), database.ListOpenHousesParams{
+		Limit:  int32(pageSize),
+		Offset: int32((page - 1) * pageSize),
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Something went wrong", err)
+		return
+	}
+
+	total, err := cfg.DB.CountOpenHouses(req.Context())
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Something went wrong", err)
+		return
+	}
+
+	res := resParams{
+		OpenHouses: make([]openHouseResponse, len(openHouses)),
+		Total:      total,
+		Page:       page,
+		PageSize:   pageSize,
+	}
+	for i, oh := range openHouses {
+		res.OpenHouses[i] = newOpenHouseResponse(oh)
+	}
+
+	respondWithJSON(w, http.StatusOK, res)
+}
+
+// GetOpenHouse is public so sign-in devices can show the property before
+// going offline.
+func (cfg *apiCfg) GetOpenHouse(w http.ResponseWriter, req *http.Request) {
+	oh, ok := cfg.openHouseFromPath(w, req)
+	if !ok {
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, newOpenHouseResponse(oh))
+}
+
+func (cfg *apiCfg) openHouseFromPath(w http.ResponseWriter, req *http.Request) (database.OpenHouse, bool) {
+	UUID, err := uuid.Parse(req.PathValue("id"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid UUID", err)
+		return database.OpenHouse{}, false
+	}
+
+	oh, err := cfg.DB.GetOpenHouse(req.Context(), UUID)
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusNotFound, "Open house not found", err)
+		return database.OpenHouse{}, false
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Something went wrong", err)
+		return database.OpenHouse{}, false
+	}
+
+	return oh, true
+}
+
+// SignInOpenHouse accepts a batch of sign-ins collected by a device that
+// may have been offline. Each sign-in carries a client-generated ID, so a
+// device can upload the same batch again after a lost response without
+// creating duplicate leads. Results are reported per sign-in; the device
+// should keep and retry only those with status "error".
+//
+// Devices authenticate with the open house's token in X-Device-Token, and
+// sign-ins must fall within the open house's hours, give or take
+// signInGrace.
+func (cfg *apiCfg) SignInOpenHouse(w http.ResponseWriter, req *http.Request) {
+	type signIn struct {
+		ClientID    string    `json:"clientId"`
+		FirstName   string    `json:"firstName"`
+		LastName    string    `json:"lastName"`
+		Email       string    `json:"email"`
+		Phone       string    `json:"phone"`
+		Message     string    `json:"message"`
+		HasAgent    bool      `json:"hasAgent"`
+		PreApproved bool      `json:"preApproved"`
+		Subscribed  bool      `json:"subscribed"`
+		SignedInAt  time.Time `json:"signedInAt"`
+	}
+	type reqParams struct {
+		SignIns []signIn `json:"signIns"`
+	}
+	type result struct {
+		ClientID string            `json:"clientId"`
+		Status   string            `json:"status"` // created, duplicate, invalid or error
+		LeadID   string            `json:"leadId,omitempty"`
+		Fields   validation.Errors `json:"fields,omitempty"`
+	}
+	type resParams struct {
+		Results []result `json:"results"`
+	}
+
+	oh, ok := cfg.openHouseFromPath(w, req)
+	if !ok {
+		return
+	}
+
+	if !validDeviceToken(oh, req.Header.Get(deviceTokenHeader)) {
+		respondWithError(w, http.StatusUnauthorized, "Invalid device token", nil)
+		return
+	}
+
+	params := reqParams{}
+	decoder := json.NewDecoder(http.MaxBytesReader(w, req.Body, maxFormBodyBytes))
+	if err := decoder.Decode(&params); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload", err)
+		return
+	}
+	if len(params.SignIns) == 0 || len(params.SignIns) > maxSignInBatch {
+		respondWithError(w, http.StatusBadRequest, "Send between 1 and "+strconv.Itoa(maxSignInBatch)+" sign-ins", nil)
+		return
+	}
+
+	ctx := req.Context()
+	meta := newSubmissionMeta(req)
+	now := time.Now()
+
+	recent, err := cfg.DB.CountRecentOpenHouseSignIns(ctx, database.CountRecentOpenHouseSignInsParams{
+		OpenHouseID: oh.ID,
+		CreatedAt:   now.Add(-signInRateWindow),
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Something went wrong", err)
+		return
+	}
+	if recent+int64(len(params.SignIns)) > signInRateLimit {
+		w.Header().Set("Retry-After", strconv.Itoa(int(signInRateWindow.Seconds())))
+		respondWithError(w, http.StatusTooManyRequests, "Too many sign-ins for this open house, try again later", nil)
+		return
+	}
+
+	res := resParams{Results: make([]result, len(params.SignIns))}
+	for i, s := range params.SignIns {
+		s.ClientID = strings.TrimSpace(s.ClientID)
+		r := result{ClientID: s.ClientID}
+
+		// Skip the work, including round-robin assignment, for sign-ins
+		// that are already stored. The unique constraint still catches
+		// concurrent uploads of the same batch.
+		existing, err := cfg.DB.GetOpenHouseSignIn(ctx, database.GetOpenHouseSignInParams{
+			OpenHouseID: oh.ID,
+			ClientID:    s.ClientID,
+		})
+		if err == nil {
+			r.Status, r.LeadID = "duplicate", existing.LeadID.String()
+			res.Results[i] = r
+			continue
+		}
+
+		signedInAt := s.SignedInAt
+		if signedInAt.IsZero() || signedInAt.After(now) {
+			signedInAt = now
+		}
+		if signedInAt.Before(oh.StartsAt.Add(-signInGrace)) || signedInAt.After(oh.EndsAt.Add(signInGrace)) {
+			r.Status, r.Fields = "invalid", validation.Errors{"signedInAt": "Sign-in is outside the open house's hours"}
+			res.Results[i] = r
+			continue
+		}
+
+		details, err := json.Marshal(openHouseDetails{
+			OpenHouseID: oh.ID.String(),
+			ClientID:    s.ClientID,
+			SignedInAt:  signedInAt,
+			HasAgent:    s.HasAgent,
+			PreApproved: s.PreApproved,
+			Address:     oh.Address,
+			City:        oh.City,
+			State:       oh.State,
+			Zip:         oh.Zip,
+			MLSNumber:   oh.MlsNumber,
+		})
+		if err != nil {
+			r.Status = "error"
+			res.Results[i] = r
+			continue
+		}
+
+		lead, err := cfg.submitLead(ctx, meta, leadSubmission{
+			Kind:       leadTypeOpenHouse,
+			FirstName:  s.FirstName,
+			LastName:   s.LastName,
+			Email:      s.Email,
+			Phone:      s.Phone,
+			Message:    s.Message,
+			Subscribed: s.Subscribed,
+			Details:    details,
+		})
+
+		var errs validation.Errors
+		switch {
+		case err == nil:
+			r.Status, r.LeadID = "created", lead.ID.String()
+		case errors.Is(err, errDuplicateSignIn):
+			// A concurrent upload stored it first
+			r.Status = "duplicate"
+			existing, err := cfg.DB.GetOpenHouseSignIn(ctx, database.GetOpenHouseSignInParams{
+				OpenHouseID: oh.ID,
+				ClientID:    s.ClientID,
+			})
+			if err != nil {
+				log.Printf("Error loading open house sign-in %q: %s", s.ClientID, err)
+			} else {
+				r.LeadID = existing.LeadID.String()
+			}
+		case errors.As(err, &errs):
+			r.Status, r.Fields = "invalid", errs.Rename(map[string]string{
+				"details.clientId": "clientId",
+			})
+		default:
+			log.Printf("Error saving open house sign-in %q: %s", s.ClientID, err)
+			r.Status = "error"
+		}
+		res.Results[i] = r
+	}
+
+	respondWithJSON(w, http.StatusOK, res)
+}
+
+// ExportOpenHouseAttendees returns everyone who signed in at an open house
+// as JSON, or as a CSV download with ?format=csv. Visitor-typed cells are
+// escaped against formula injection; the phone is E.164 and written as is.
+func (cfg *apiCfg) ExportOpenHouseAttendees(w http.ResponseWriter, req *http.Request) {
+	type attendeeResponse struct {
+		LeadID           string    `json:"leadId"`
+		FirstName        string    `json:"firstName"`
+		LastName         string    `json:"lastName"`
+		Email            string    `json:"email"`
+		Phone            string    `json:"phone"`
+		HasAgent         bool      `json:"hasAgent"`
+		PreApproved      bool      `json:"preApproved"`
+		MarketingConsent bool      `json:"marketingConsent"`
+		SignedInAt       time.Time `json:"signedInAt"`
+	}
+
+	oh, ok := cfg.openHouseFromPath(w, req)
+	if !ok {
+		return
+	}
+
+	attendees, err := cfg.DB.ListOpenHouseAttendees(req.Context(), oh.ID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Something went wrong", err)
+		return
+	}
+
+	res := make([]attendeeResponse, len(attendees))
+	for i, a := range attendees {
+		res[i] = attendeeResponse{
+			LeadID:           a.LeadID.String(),
+			FirstName:        a.FirstName,
+			LastName:         a.LastName,
+			Email:            a.Email,
+			Phone:            a.Phone,
+			HasAgent:         a.HasAgent,
+			PreApproved:      a.PreApproved,
+			MarketingConsent: a.MarketingConsent,
+			SignedInAt:       a.SignedInAt,
+		}
+	}
+
+	if req.URL.Query().Get("format") != "csv" {
+		respondWithJSON(w, http.StatusOK, res)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set("Content-Disposition", `attachment; filename="open-house-`+oh.ID.String()+`-attendees.csv"`)
+	w.WriteHeader(http.StatusOK)
+
+	writer := csv.NewWriter(w)
+	writer.Write([]string{"lead_id", "first_name", "last_name", "email", "phone", "has_agent", "pre_approved", "marketing_consent", "signed_in_at"})
+	for _, a := range res {
+		writer.Write([]string{
+			a.LeadID,
+			csvSafe(a.FirstName),
+			csvSafe(a.LastName),
+			csvSafe(a.Email),
+			a.Phone,
+			strconv.FormatBool(a.HasAgent),
+			strconv.FormatBool(a.PreApproved),
+			strconv.FormatBool(a.MarketingConsent),
+			a.SignedInAt.Format(time.RFC3339),
+		})
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		log.Printf("Error writing attendees of open house %s: %s", oh.ID, err)
+	}
+}
@@ -0,0 +1,160 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/DiegoGarciaCo/websitesAPI/internal/crm"
+	"github.com/DiegoGarciaCo/websitesAPI/internal/database"
+	"github.com/DiegoGarciaCo/websitesAPI/internal/scoring"
+)
+
+const (
+	outboxPollInterval = 10 * time.Second
+	outboxBatchSize    = 20
+	outboxMaxAttempts  = 8
+	outboxBaseBackoff  = 30 * time.Second
+	outboxMaxBackoff   = 6 * time.Hour
+	// outboxDeliveryTimeout bounds one delivery, so a hung downstream
+	// doesn't hold up the rest of the batch.
+	outboxDeliveryTimeout = time.Minute
+)
+
+// RunOutbox delivers queued leads to downstream systems, queued webhook
+// events to their subscribers and held agent notifications until ctx is
+// done. Deliveries are claimed with SKIP LOCKED so every replica can run it.
+func (cfg *apiCfg) RunOutbox(ctx context.Context) {
+	ticker := time.NewTicker(outboxPollInterval)
+	defer ticker.Stop()
+
+	for {
+		cfg.processLeadDeliveries(ctx)
+		cfg.processWebhookDeliveries(ctx)
+		cfg.processNotificationDigest(ctx)
+		cfg.deleteExpiredIdempotencyKeys(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (cfg *apiCfg) processLeadDeliveries(ctx context.Context) {
+	deliveries, err := cfg.DB.ClaimLeadDeliveries(ctx, outboxBatchSize)
+	if err != nil {
+		log.Printf("Error claiming lead deliveries: %s", err)
+		return
+	}
+
+	for _, delivery := range deliveries {
+		deliverCtx, cancel := context.WithTimeout(ctx, outboxDeliveryTimeout)
+		err := cfg.deliverLead(deliverCtx, delivery)
+		cancel()
+		cfg.recordLeadDelivery(ctx, delivery, err)
+	}
+}
+
+func (cfg *apiCfg) deliverLead(ctx context.Context, delivery database.LeadDelivery) error {
+	lead, err := cfg.DB.GetLeadByID(ctx, delivery.LeadID)
+	if err != nil {
+		return fmt.Errorf("failed to load lead: %w", err)
+	}
+
+	switch delivery.Target {
+	case deliveryTargetCRM:
+		crmLead, err := cfg.crmLeadForLead(lead)
+		if err != nil {
+			return err
+		}
+		return cfg.CRM.SendLead(ctx, crmLead)
+	case deliveryTargetBrevo:
+		// Deliveries queued before consent was recorded are dropped
+		// rather than adding the contact to a list without it.
+		if !lead.MarketingConsent {
+			return nil
+		}
+		// So are contacts who unsubscribed, bounced or complained since.
+		reason, err := cfg.emailSuppression(ctx, lead.Email)
+		if err != nil {
+			return err
+		}
+		if reason != "" {
+			log.Printf("Skipping Brevo list addition for lead %s: address suppressed after %s", lead.ID, reason)
+			return nil
+		}
+		return cfg.CreateContact(cfg.contactForLead(lead))
+	case deliveryTargetNotify:
+		return cfg.notifyAgents(ctx, lead)
+	case deliveryTargetCRMTags:
+		updater, ok := cfg.CRM.(crm.TagUpdater)
+		if !ok {
+			return nil
+		}
+		crmLead, err := cfg.crmLeadForLead(lead)
+		if err != nil {
+			return err
+		}
+		return updater.UpdateTags(ctx, crmLead, scoring.TagPrefix)
+	default:
+		return fmt.Errorf("unknown delivery target: %s", delivery.Target)
+	}
+}
+
+// recordLeadDelivery logs the attempt and either marks the delivery done or
+// schedules the next retry.
+func (cfg *apiCfg) recordLeadDelivery(ctx context.Context, delivery database.LeadDelivery, deliveryErr error) {
+	attempt := database.CreateLeadDeliveryAttemptParams{
+		DeliveryID: delivery.ID,
+		Attempt:    delivery.Attempts,
+		Status:     "succeeded",
+	}
+	if deliveryErr != nil {
+		attempt.Status = "failed"
+		attempt.Error = sql.NullString{String: deliveryErr.Error(), Valid: true}
+	}
+	if err := cfg.DB.CreateLeadDeliveryAttempt(ctx, attempt); err != nil {
+		log.Printf("Error recording attempt for delivery %s: %s", delivery.ID, err)
+	}
+
+	if deliveryErr == nil {
+		if err := cfg.DB.MarkLeadDeliveryDelivered(ctx, delivery.ID); err != nil {
+			log.Printf("Error marking delivery %s delivered: %s", delivery.ID, err)
+		}
+		return
+	}
+
+	log.Printf("Error delivering lead %s to %s (attempt %d): %s", delivery.LeadID, delivery.Target, delivery.Attempts, deliveryErr)
+
+	status := "pending"
+	if delivery.Attempts >= outboxMaxAttempts {
+		status = "failed"
+	}
+
+	err := cfg.DB.MarkLeadDeliveryFailed(ctx, database.MarkLeadDeliveryFailedParams{
+		ID:            delivery.ID,
+		Status:        status,
+		LastError:     sql.NullString{String: deliveryErr.Error(), Valid: true},
+		NextAttemptAt: time.Now().Add(outboxBackoff(delivery.Attempts)),
+	})
+	if err != nil {
+		log.Printf("Error rescheduling delivery %s: %s", delivery.ID, err)
+	}
+}
+
+// outboxBackoff doubles the wait after every failed attempt, capped at
+// outboxMaxBackoff.
+func outboxBackoff(attempt int32) time.Duration {
+	backoff := outboxBaseBackoff
+	for i := int32(1); i < attempt; i++ {
+		backoff *= 2
+		if backoff >= outboxMaxBackoff {
+			return outboxMaxBackoff
+		}
+	}
+	return backoff
+}
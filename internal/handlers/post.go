@@ -4,6 +4,7 @@ import (
 	"database/sql"
 	"net/url"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/DiegoGarciaCo/websitesAPI/internal/database"
 	"github.com/aws/aws-sdk-go-v2/aws"
@@ -113,6 +114,7 @@ func (cfg *apiCfg) DeletePost(w http.ResponseWriter, req *http.Request) {
 		respondWithError(w, http.StatusInternalServerError, "Something went wrong", err)
 		return
 	}
+	cfg.emitPostEvent(req.Context(), eventPostDeleted, postEventData{ID: post.ID.String()})
 
 	if !post.Thumbnail.Valid {
 		respondWithJSON(w, http.StatusNoContent, nil)
@@ -150,19 +152,24 @@ func (cfg *apiCfg) PublishPost(w http.ResponseWriter, req *http.Request) {
 			respondWithError(w, http.StatusBadRequest, "Invalid UUID", err)
 			return
 		}
-		err = cfg.DB.PublishPost(req.Context(), database.PublishPostParams{
+		post, err := cfg.DB.PublishPost(req.Context(), database.PublishPostParams{
 			ID: UUID,
 			Status: sql.NullString{
 				String: "published",
 				Valid:  true,
 			},
 		})
-
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusNotFound, "Post not found", err)
+			return
+		}
 		if err != nil {
 			respondWithError(w, http.StatusInternalServerError, "Something went wrong", err)
 			return
 		}
+		cfg.emitPostEvent(req.Context(), eventPostPublished, newPostEventData(post.ID, post.Title, post.Slug, post.Excerpt))
 		respondWithJSON(w, http.StatusOK, "Post published")
+		return
 	}
 
 	type reqParams struct {
@@ -197,6 +204,7 @@ func (cfg *apiCfg) PublishPost(w http.ResponseWriter, req *http.Request) {
 		respondWithError(w, http.StatusInternalServerError, "Something went wrong", err)
 		return
 	}
+	cfg.emitPostEvent(req.Context(), eventPostPublished, newPostEventData(post.ID, post.Title, post.Slug, post.Excerpt))
 
 	respondWithJSON(w, http.StatusOK, post)
 }
@@ -226,7 +234,7 @@ func (cfg *apiCfg) SaveAndPublishPost(w http.ResponseWriter, req *http.Request)
 		return
 	}
 
-	_, err = cfg.DB.SaveAndPublishPost(req.Context(), database.SaveAndPublishPostParams{
+	post, err := cfg.DB.SaveAndPublishPost(req.Context(), database.SaveAndPublishPostParams{
 		ID:      UUID,
 		Title:   params.Title,
 		Slug:    params.Slug,
@@ -240,6 +248,7 @@ func (cfg *apiCfg) SaveAndPublishPost(w http.ResponseWriter, req *http.Request)
 		respondWithError(w, http.StatusInternalServerError, "Something went wrong", err)
 		return
 	}
+	cfg.emitPostEvent(req.Context(), eventPostPublished, newPostEventData(post.ID, post.Title, post.Slug, post.Excerpt))
 
 	respondWithJSON(w, http.StatusNoContent, nil)
 }
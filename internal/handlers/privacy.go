@@ -0,0 +1,581 @@
+package handlers
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/DiegoGarciaCo/websitesAPI/internal/auditlog"
+	"github.com/DiegoGarciaCo/websitesAPI/internal/database"
+	"github.com/DiegoGarciaCo/websitesAPI/internal/validation"
+	"github.com/google/uuid"
+)
+
+const (
+	privacyActionAccess  = "access"
+	privacyActionErasure = "erasure"
+)
+
+// privacySubject is the person a data-subject request is about.
+type privacySubject struct {
+	Email string `json:"email"`
+	Phone string `json:"phone"`
+}
+
+// decodePrivacySubject reads and normalizes the subject of a request. It
+// writes the error response and returns false when the body is unusable.
+func (cfg *apiCfg) decodePrivacySubject(w http.ResponseWriter, req *http.Request) (privacySubject, bool) {
+	subject := privacySubject{}
+	decoder := json.NewDecoder(req.Body)
+	if err := decoder.Decode(&subject); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload", err)
+		return subject, false
+	}
+
+	errs := validation.Errors{}
+	var err error
+	if email := strings.TrimSpace(subject.Email); email != "" {
+		subject.Email, err = validation.Email(email)
+		if err != nil {
+			errs.Add("email", "Enter a valid email address")
+		}
+	}
+	if phone := strings.TrimSpace(subject.Phone); phone != "" {
+		subject.Phone, err = validation.Phone(phone, cfg.PhoneRegion)
+		if err != nil {
+			errs.Add("phone", "Enter a valid phone number")
+		}
+	}
+	if subject.Email == "" && subject.Phone == "" && len(errs) == 0 {
+		errs.Add("email", "Enter an email address or phone number")
+	}
+	if len(errs) > 0 {
+		respondWithValidationErrors(w, errs)
+		return subject, false
+	}
+
+	return subject, true
+}
+
+// subjectHash identifies a subject in the request log without storing their
+// email or phone. It is keyed, so it can't be reversed by hashing guesses
+// without the subject hash key.
+func (cfg *apiCfg) subjectHash(value string) string {
+	mac := hmac.New(sha256.New, []byte(cfg.SubjectHashKey))
+	mac.Write([]byte(strings.ToLower(value)))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// erasedEmail replaces an erased person's email where a record of them is
+// kept, such as their consent records and email suppression.
+func (cfg *apiCfg) erasedEmail(email string) string {
+	return "erased:" + cfg.subjectHash(email)
+}
+
+func (cfg *apiCfg) privacySubjectHash(s privacySubject) string {
+	return cfg.subjectHash("email=" + s.Email + ";phone=" + s.Phone)
+}
+
+// privacyRecords is everything stored locally about a subject.
+type privacyRecords struct {
+	Leads  []database.Lead
+	Emails []string // the subject's email and any other email on their leads
+}
+
+func (cfg *apiCfg) findPrivacyRecords(ctx context.Context, s privacySubject) (privacyRecords, error) {
+	leads, err := cfg.DB.ListLeadsBySubject(ctx, database.ListLeadsBySubjectParams{
+		Email: s.Email,
+		Phone: s.Phone,
+	})
+	if err != nil {
+		return privacyRecords{}, fmt.Errorf("failed to find leads: %w", err)
+	}
+
+	records := privacyRecords{Leads: leads}
+	if s.Email != "" {
+		records.Emails = append(records.Emails, strings.ToLower(s.Email))
+	}
+	for _, lead := range leads {
+		email := strings.ToLower(lead.Email)
+		if email != "" && !slices.Contains(records.Emails, email) {
+			records.Emails = append(records.Emails, email)
+		}
+	}
+	return records, nil
+}
+
+// appendPrivacyRequest adds a fulfilled request to the hash-chained log.
+// Appends are serialized with an advisory lock, so replicas can't fork the
+// chain. db should be inside a transaction.
+func (cfg *apiCfg) appendPrivacyRequest(ctx context.Context, db *database.Queries, action, subjectHash, actor string, details any) error {
+	body, err := json.Marshal(details)
+	if err != nil {
+		return fmt.Errorf("failed to marshal privacy request details: %w", err)
+	}
+
+	if err := db.LockPrivacyRequests(ctx); err != nil {
+		return fmt.Errorf("failed to lock privacy request log: %w", err)
+	}
+
+	prev := ""
+	last, err := db.GetLastPrivacyRequest(ctx)
+	if err == nil {
+		prev = last.Hash
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("failed to read privacy request log: %w", err)
+	}
+
+	entry := auditlog.Seal([]byte(cfg.AuditLogKey), prev, auditlog.Entry{
+		ID:        uuid.NewString(),
+		Action:    action,
+		Subject:   subjectHash,
+		Details:   string(body),
+		Actor:     actor,
+		CreatedAt: time.Now(),
+	})
+
+	_, err = db.CreatePrivacyRequest(ctx, database.CreatePrivacyRequestParams{
+		ID:          uuid.MustParse(entry.ID),
+		Action:      entry.Action,
+		SubjectHash: entry.Subject,
+		Details:     entry.Details,
+		PerformedBy: entry.Actor,
+		CreatedAt:   entry.CreatedAt,
+		PrevHash:    entry.PrevHash,
+		Hash:        entry.Hash,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to record privacy request: %w", err)
+	}
+	return nil
+}
+
+func privacyActor(ctx context.Context) string {
+	if user, ok := userFromContext(ctx); ok {
+		return user.Username
+	}
+	return ""
+}
+
+// PrivacyAccess builds a report of everything held about a person, keyed
+// by email or phone: their leads with notes, the mortgage calculations they
+// ran, consent records, quarantined submissions, their newsletter
+// subscription, email events and suppression, and their Brevo contact.
+// Each report is recorded in the privacy request log.
+func (cfg *apiCfg) PrivacyAccess(w http.ResponseWriter, req *http.Request) {
+	type noteResponse struct {
+		Body      string    `json:"body"`
+		Author    string    `json:"author,omitempty"`
+		CreatedAt time.Time `json:"createdAt"`
+	}
+	type leadReport struct {
+		leadExport
+		Details json.RawMessage `json:"details,omitempty"`
+		Notes   []noteResponse  `json:"notes"`
+	}
+	type calculationResponse struct {
+		LeadID    string    `json:"leadId"`
+		CreatedAt time.Time `json:"createdAt"`
+		mortgageDetails
+	}
+	type quarantineResponse struct {
+		Route     string          `json:"route"`
+		Reason    string          `json:"reason"`
+		IPAddress string          `json:"ipAddress"`
+		UserAgent string          `json:"userAgent"`
+		Payload   json.RawMessage `json:"payload"`
+		CreatedAt time.Time       `json:"createdAt"`
+	}
+	type emailEventResponse struct {
+		Event      string    `json:"event"`
+		MessageID  string    `json:"messageId,omitempty"`
+		OccurredAt time.Time `json:"occurredAt"`
+	}
+	type resParams struct {
+		Subject        privacySubject                   `json:"subject"`
+		Leads          []leadReport                     `json:"leads"`
+		Calculations   []calculationResponse            `json:"calculations"`
+		ConsentRecords []consentRecordResponse          `json:"consentRecords"`
+		SpamQuarantine []quarantineResponse             `json:"spamQuarantine"`
+		BrevoContacts  []ContactResponse                `json:"brevoContacts"`
+		Newsletter     []newsletterSubscriptionResponse `json:"newsletter"`
+		EmailEvents    []emailEventResponse             `json:"emailEvents"`
+		Suppressions   []emailSuppressionResponse       `json:"suppressions"`
+		GeneratedAt    time.Time                        `json:"generatedAt"`
+	}
+
+	subject, ok := cfg.decodePrivacySubject(w, req)
+	if !ok {
+		return
+	}
+	ctx := req.Context()
+
+	records, err := cfg.findPrivacyRecords(ctx, subject)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Something went wrong", err)
+		return
+	}
+
+	res := resParams{
+		Subject:        subject,
+		Leads:          []leadReport{},
+		Calculations:   []calculationResponse{},
+		ConsentRecords: []consentRecordResponse{},
+		SpamQuarantine: []quarantineResponse{},
+		BrevoContacts:  []ContactResponse{},
+		Newsletter:     []newsletterSubscriptionResponse{},
+		EmailEvents:    []emailEventResponse{},
+		Suppressions:   []emailSuppressionResponse{},
+		GeneratedAt:    time.Now(),
+	}
+
+	for _, lead := range records.Leads {
+		report := leadReport{
+			leadExport: leadExport{
+				leadResponse: newLeadResponse(lead),
+				Attribution:  attributionForLead(lead),
+			},
+			Notes: []noteResponse{},
+		}
+
+		sub := leadSubmission{}
+		if err := json.Unmarshal(lead.Payload, &sub); err == nil && len(sub.Details) > 0 && string(sub.Details) != "null" {
+			report.Details = sub.Details
+		}
+
+		// The calculator doesn't keep a separate history; each calculation
+		// is stored as a mortgage lead.
+		if lead.LeadType == leadTypeMortgage {
+			calc := calculationResponse{LeadID: lead.ID.String(), CreatedAt: lead.CreatedAt}
+			if err := json.Unmarshal(report.Details, &calc.mortgageDetails); err == nil {
+				res.Calculations = append(res.Calculations, calc)
+			}
+		}
+
+		notes, err := cfg.DB.ListLeadNotes(ctx, lead.ID)
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Something went wrong", err)
+			return
+		}
+		for _, n := range notes {
+			report.Notes = append(report.Notes, noteResponse{
+				Body:      n.Body,
+				Author:    n.Username.String,
+				CreatedAt: n.CreatedAt,
+			})
+		}
+
+		res.Leads = append(res.Leads, report)
+	}
+
+	for _, email := range records.Emails {
+		consent, err := cfg.DB.ListConsentRecordsByEmail(ctx, email)
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Something went wrong", err)
+			return
+		}
+		for _, r := range consent {
+			res.ConsentRecords = append(res.ConsentRecords, newConsentRecordResponse(r))
+		}
+
+		quarantined, err := cfg.DB.ListSpamQuarantineByEmail(ctx, email)
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Something went wrong", err)
+			return
+		}
+		for _, q := range quarantined {
+			res.SpamQuarantine = append(res.SpamQuarantine, quarantineResponse{
+				Route:     q.Route,
+				Reason:    q.Reason,
+				IPAddress: q.IpAddress,
+				UserAgent: q.UserAgent,
+				Payload:   q.Payload,
+				CreatedAt: q.CreatedAt,
+			})
+		}
+
+		subscription, err := cfg.DB.GetNewsletterSubscriptionByEmail(ctx, email)
+		if err == nil {
+			res.Newsletter = append(res.Newsletter, newNewsletterSubscriptionResponse(subscription, res.GeneratedAt))
+		} else if !errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusInternalServerError, "Something went wrong", err)
+			return
+		}
+
+		events, err := cfg.DB.ListEmailEventsByEmail(ctx, email)
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Something went wrong", err)
+			return
+		}
+		for _, e := range events {
+			res.EmailEvents = append(res.EmailEvents, emailEventResponse{
+				Event:      e.Event,
+				MessageID:  e.MessageID,
+				OccurredAt: e.OccurredAt,
+			})
+		}
+
+		suppression, err := cfg.DB.GetEmailSuppression(ctx, database.GetEmailSuppressionParams{
+			Email:  email,
+			Erased: cfg.erasedEmail(email),
+		})
+		if err == nil {
+			res.Suppressions = append(res.Suppressions, newEmailSuppressionResponse(suppression))
+		} else if !errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusInternalServerError, "Something went wrong", err)
+			return
+		}
+
+		contact, err := cfg.GetContact(ctx, email)
+		if err != nil {
+			respondWithError(w, http.StatusBadGateway, "Could not fetch the Brevo contact", err)
+			return
+		}
+		if contact != nil {
+			res.BrevoContacts = append(res.BrevoContacts, *contact)
+		}
+	}
+
+	tx, err := cfg.SQLDB.BeginTx(ctx, nil)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Something went wrong", err)
+		return
+	}
+	defer tx.Rollback()
+
+	err = cfg.appendPrivacyRequest(ctx, cfg.DB.WithTx(tx), privacyActionAccess, cfg.privacySubjectHash(subject), privacyActor(ctx), map[string]int{
+		"leads":          len(res.Leads),
+		"consentRecords": len(res.ConsentRecords),
+		"spamQuarantine": len(res.SpamQuarantine),
+		"brevoContacts":  len(res.BrevoContacts),
+		"newsletter":     len(res.Newsletter),
+		"emailEvents":    len(res.EmailEvents),
+	})
+	if err == nil {
+		err = tx.Commit()
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Something went wrong", err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, res)
+}
+
+// PrivacyErasure deletes everything held about a person, keyed by email or
+// phone. Brevo contacts are deleted first, so a Brevo failure leaves the
+// local records in place for a retry. Leads (with their notes, deliveries
+// and sign-ins), quarantined submissions, newsletter subscriptions, email
+// events and queued webhook payloads are deleted; consent records are kept
+// as evidence with the email replaced by a keyed hash and the IP address
+// and user agent cleared. Email suppressions are kept under the same hash,
+// so the person is never emailed again.
+func (cfg *apiCfg) PrivacyErasure(w http.ResponseWriter, req *http.Request) {
+	type resParams struct {
+		Leads             int64    `json:"leads"`
+		ConsentRecords    int64    `json:"consentRecords"`
+		SpamQuarantine    int64    `json:"spamQuarantine"`
+		WebhookDeliveries int64    `json:"webhookDeliveries"`
+		Newsletter        int64    `json:"newsletter"`
+		EmailEvents       int64    `json:"emailEvents"`
+		EmailSuppressions int64    `json:"emailSuppressions"`
+		BrevoContacts     int      `json:"brevoContacts"`
+		ManualSteps       []string `json:"manualSteps"`
+	}
+
+	subject, ok := cfg.decodePrivacySubject(w, req)
+	if !ok {
+		return
+	}
+	ctx := req.Context()
+
+	records, err := cfg.findPrivacyRecords(ctx, subject)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Something went wrong", err)
+		return
+	}
+
+	res := resParams{ManualSteps: []string{}}
+
+	for _, email := range records.Emails {
+		contact, err := cfg.GetContact(ctx, email)
+		if err != nil {
+			respondWithError(w, http.StatusBadGateway, "Could not fetch the Brevo contact", err)
+			return
+		}
+		if contact == nil {
+			continue
+		}
+		if err := cfg.DeleteContact(ctx, email); err != nil {
+			respondWithError(w, http.StatusBadGateway, "Could not delete the Brevo contact", err)
+			return
+		}
+		res.BrevoContacts++
+	}
+
+	tx, err := cfg.SQLDB.BeginTx(ctx, nil)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Something went wrong", err)
+		return
+	}
+	defer tx.Rollback()
+
+	qtx := cfg.DB.WithTx(tx)
+
+	ids := make([]uuid.UUID, len(records.Leads))
+	for i, lead := range records.Leads {
+		ids[i] = lead.ID
+	}
+	res.Leads, err = qtx.DeleteLeadsByID(ctx, ids)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Something went wrong", err)
+		return
+	}
+
+	for _, email := range records.Emails {
+		n, err := qtx.AnonymizeConsentRecords(ctx, database.AnonymizeConsentRecordsParams{
+			Replacement: cfg.erasedEmail(email),
+			Email:       email,
+		})
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Something went wrong", err)
+			return
+		}
+		res.ConsentRecords += n
+
+		// An erased suppression from an earlier erasure gives way to the
+		// current one.
+		err = qtx.DeleteSupersededEmailSuppression(ctx, database.DeleteSupersededEmailSuppressionParams{
+			Replacement: cfg.erasedEmail(email),
+			Email:       email,
+		})
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Something went wrong", err)
+			return
+		}
+		n, err = qtx.AnonymizeEmailSuppression(ctx, database.AnonymizeEmailSuppressionParams{
+			Replacement: cfg.erasedEmail(email),
+			Email:       email,
+		})
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Something went wrong", err)
+			return
+		}
+		res.EmailSuppressions += n
+
+		n, err = qtx.DeleteSpamQuarantineByEmail(ctx, email)
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Something went wrong", err)
+			return
+		}
+		res.SpamQuarantine += n
+
+		n, err = qtx.DeleteWebhookDeliveriesByEmail(ctx, email)
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Something went wrong", err)
+			return
+		}
+		res.WebhookDeliveries += n
+
+		n, err = qtx.DeleteNewsletterSubscriptionByEmail(ctx, email)
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Something went wrong", err)
+			return
+		}
+		res.Newsletter += n
+
+		n, err = qtx.DeleteEmailEventsByEmail(ctx, email)
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Something went wrong", err)
+			return
+		}
+		res.EmailEvents += n
+	}
+
+	if res.Leads > 0 {
+		res.ManualSteps = append(res.ManualSteps,
+			"Delete the person in the CRM; leads already sent there are not erased by this API",
+			"Ask webhook subscribers to delete lead.created events they already received",
+		)
+	}
+
+	err = cfg.appendPrivacyRequest(ctx, qtx, privacyActionErasure, cfg.privacySubjectHash(subject), privacyActor(ctx), res)
+	if err == nil {
+		err = tx.Commit()
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Something went wrong", err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, res)
+}
+
+// ListPrivacyRequests returns the request log and whether its hash chain is
+// intact. brokenAt is the position of the first entry that fails
+// verification.
+func (cfg *apiCfg) ListPrivacyRequests(w http.ResponseWriter, req *http.Request) {
+	type requestResponse struct {
+		ID          string          `json:"id"`
+		Action      string          `json:"action"`
+		SubjectHash string          `json:"subjectHash"`
+		Details     json.RawMessage `json:"details"`
+		PerformedBy string          `json:"performedBy"`
+		CreatedAt   time.Time       `json:"createdAt"`
+		Hash        string          `json:"hash"`
+	}
+	type resParams struct {
+		Valid    bool              `json:"valid"`
+		BrokenAt *int              `json:"brokenAt,omitempty"`
+		Requests []requestResponse `json:"requests"`
+	}
+
+	rows, err := cfg.DB.ListPrivacyRequests(req.Context())
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Something went wrong", err)
+		return
+	}
+
+	entries := make([]auditlog.Entry, len(rows))
+	res := resParams{Requests: make([]requestResponse, len(rows))}
+	for i, r := range rows {
+		entries[i] = auditlog.Entry{
+			ID:        r.ID.String(),
+			Action:    r.Action,
+			Subject:   r.SubjectHash,
+			Details:   r.Details,
+			Actor:     r.PerformedBy,
+			CreatedAt: r.CreatedAt,
+			PrevHash:  r.PrevHash,
+			Hash:      r.Hash,
+		}
+		res.Requests[i] = requestResponse{
+			ID:          r.ID.String(),
+			Action:      r.Action,
+			SubjectHash: r.SubjectHash,
+			PerformedBy: r.PerformedBy,
+			CreatedAt:   r.CreatedAt,
+			Hash:        r.Hash,
+		}
+		if json.Valid([]byte(r.Details)) {
+			res.Requests[i].Details = json.RawMessage(r.Details)
+		}
+	}
+
+	brokenAt, err := auditlog.Verify([]byte(cfg.AuditLogKey), entries)
+	res.Valid = err == nil
+	if err != nil {
+		res.BrokenAt = &brokenAt
+	}
+
+	respondWithJSON(w, http.StatusOK, res)
+}
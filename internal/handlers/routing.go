@@ -0,0 +1,402 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/DiegoGarciaCo/websitesAPI/internal/database"
+	"github.com/DiegoGarciaCo/websitesAPI/internal/routing"
+	"github.com/DiegoGarciaCo/websitesAPI/internal/timeofday"
+	"github.com/DiegoGarciaCo/websitesAPI/internal/validation"
+	"github.com/google/uuid"
+)
+
+// leadRouter is implemented by details that carry facts routing rules
+// match on, such as an address or a price.
+type leadRouter interface {
+	applyRouting(lead *routing.Lead)
+}
+
+func routingLeadForSubmission(sub leadSubmission, details leadDetails) routing.Lead {
+	lead := routing.Lead{Type: sub.Kind}
+	if r, ok := details.(leadRouter); ok {
+		r.applyRouting(&lead)
+	}
+	return lead
+}
+
+func ruleFromRow(row database.RoutingRule) routing.Rule {
+	rule := routing.Rule{
+		LeadTypes: row.LeadTypes,
+		Zips:      row.Zips,
+		Cities:    row.Cities,
+		Strategy:  row.Strategy,
+		Agents:    row.Agents,
+	}
+	if row.MinPrice.Valid {
+		rule.MinPrice = &row.MinPrice.Float64
+	}
+	if row.MaxPrice.Valid {
+		rule.MaxPrice = &row.MaxPrice.Float64
+	}
+	if row.ActiveHours != "" {
+		if window, err := timeofday.Parse(row.ActiveHours); err == nil {
+			rule.ActiveHours = &window
+		}
+	}
+	return rule
+}
+
+// matchRoutingRule returns the first enabled rule, in priority order, that
+// applies to lead at the given time.
+func (cfg *apiCfg) matchRoutingRule(ctx context.Context, db *database.Queries, lead routing.Lead, at time.Time) (database.RoutingRule, bool, error) {
+	rows, err := db.ListEnabledRoutingRules(ctx)
+	if err != nil {
+		return database.RoutingRule{}, false, fmt.Errorf("failed to load routing rules: %w", err)
+	}
+
+	rules := make([]routing.Rule, len(rows))
+	for i, row := range rows {
+		rules[i] = ruleFromRow(row)
+	}
+
+	i := routing.Match(rules, lead, at.In(cfg.Location))
+	if i < 0 {
+		return database.RoutingRule{}, false, nil
+	}
+	return rows[i], true, nil
+}
+
+// assignAgent picks the agent for a new lead. Round-robin rules advance
+// their position in the database, so concurrent submissions on any replica
+// get consecutive agents. db should be the lead's transaction, so a rule
+// only advances for leads that are saved. Leads no rule matches stay
+// unassigned.
+func (cfg *apiCfg) assignAgent(ctx context.Context, db *database.Queries, lead routing.Lead) (string, uuid.NullUUID, error) {
+	row, ok, err := cfg.matchRoutingRule(ctx, db, lead, time.Now())
+	if err != nil || !ok {
+		return "", uuid.NullUUID{}, err
+	}
+
+	n := 0
+	if row.Strategy == routing.StrategyRoundRobin {
+		row, err = db.AdvanceRoutingRule(ctx, row.ID)
+		if err != nil {
+			return "", uuid.NullUUID{}, fmt.Errorf("failed to advance routing rule: %w", err)
+		}
+		n = int(row.NextAgent) - 1
+	}
+
+	return ruleFromRow(row).Agent(n), uuid.NullUUID{UUID: row.ID, Valid: true}, nil
+}
+
+type routingRuleParams struct {
+	Name        string   `json:"name"`
+	Priority    int32    `json:"priority"`
+	Enabled     *bool    `json:"enabled"`
+	LeadTypes   []string `json:"leadTypes"`
+	Zips        []string `json:"zips"`
+	Cities      []string `json:"cities"`
+	MinPrice    *float64 `json:"minPrice"`
+	MaxPrice    *float64 `json:"maxPrice"`
+	ActiveHours string   `json:"activeHours"` // HH:MM-HH:MM, empty for all day
+	Strategy    string   `json:"strategy"`
+	Agents      []string `json:"agents"` // Follow Up Boss user names
+}
+
+// normalize trims and deduplicates the rule's lists and fills defaults.
+func (p routingRuleParams) normalize() routingRuleParams {
+	clean := func(values []string, normalize func(string) string) []string {
+		out := []string{}
+		for _, v := range values {
+			v = normalize(v)
+			if v != "" && !slices.Contains(out, v) {
+				out = append(out, v)
+			}
+		}
+		return out
+	}
+
+	p.Name = strings.TrimSpace(p.Name)
+	p.LeadTypes = clean(p.LeadTypes, strings.TrimSpace)
+	p.Zips = clean(p.Zips, routing.NormalizeZip)
+	p.Cities = clean(p.Cities, routing.NormalizeCity)
+	p.Agents = clean(p.Agents, strings.TrimSpace)
+	p.ActiveHours = strings.TrimSpace(p.ActiveHours)
+	if p.Strategy == "" {
+		p.Strategy = routing.StrategyFixed
+	}
+	return p
+}
+
+func (p routingRuleParams) validate() validation.Errors {
+	errs := validation.Errors{}
+
+	switch {
+	case p.Name == "":
+		errs.Add("name", "Name is required")
+	case len(p.Name) > 100:
+		errs.Add("name", "Name is too long")
+	}
+	for _, t := range p.LeadTypes {
+		if _, ok := leadKinds[t]; !ok {
+			errs.Add("leadTypes", fmt.Sprintf("Unknown lead type %q", t))
+		}
+	}
+	if p.MinPrice != nil && *p.MinPrice < 0 {
+		errs.Add("minPrice", "Minimum price must not be negative")
+	}
+	if p.MaxPrice != nil && *p.MaxPrice <= 0 {
+		errs.Add("maxPrice", "Maximum price must be greater than zero")
+	}
+	if p.MinPrice != nil && p.MaxPrice != nil && *p.MinPrice > *p.MaxPrice {
+		errs.Add("maxPrice", "Maximum price must not be below the minimum")
+	}
+	if p.ActiveHours != "" {
+		if _, err := timeofday.Parse(p.ActiveHours); err != nil {
+			errs.Add("activeHours", "Use the format HH:MM-HH:MM")
+		}
+	}
+	if p.Strategy != routing.StrategyFixed && p.Strategy != routing.StrategyRoundRobin {
+		errs.Add("strategy", "Strategy must be fixed or round_robin")
+	}
+	switch {
+	case len(p.Agents) == 0:
+		errs.Add("agents", "Add at least one agent")
+	case p.Strategy == routing.StrategyFixed && len(p.Agents) > 1:
+		errs.Add("agents", "A fixed rule assigns exactly one agent")
+	}
+
+	return errs
+}
+
+func (p routingRuleParams) enabled() bool {
+	return p.Enabled == nil || *p.Enabled
+}
+
+func nullFloat(f *float64) sql.NullFloat64 {
+	if f == nil {
+		return sql.NullFloat64{}
+	}
+	return sql.NullFloat64{Float64: *f, Valid: true}
+}
+
+// decodeRoutingRule reads, normalizes and validates a rule body. It writes
+// the error response and returns false when the body is unusable.
+func decodeRoutingRule(w http.ResponseWriter, req *http.Request) (routingRuleParams, bool) {
+	params := routingRuleParams{}
+	decoder := json.NewDecoder(req.Body)
+	if err := decoder.Decode(&params); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload", err)
+		return params, false
+	}
+
+	params = params.normalize()
+	if errs := params.validate(); len(errs) > 0 {
+		respondWithValidationErrors(w, errs)
+		return params, false
+	}
+	return params, true
+}
+
+type routingRuleResponse struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Priority    int32     `json:"priority"`
+	Enabled     bool      `json:"enabled"`
+	LeadTypes   []string  `json:"leadTypes"`
+	Zips        []string  `json:"zips"`
+	Cities      []string  `json:"cities"`
+	MinPrice    *float64  `json:"minPrice"`
+	MaxPrice    *float64  `json:"maxPrice"`
+	ActiveHours string    `json:"activeHours"`
+	Strategy    string    `json:"strategy"`
+	Agents      []string  `json:"agents"`
+	NextAgent   string    `json:"nextAgent"`
+	CreatedAt   time.Time `json:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
+
+func newRoutingRuleResponse(row database.RoutingRule) routingRuleResponse {
+	rule := ruleFromRow(row)
+	return routingRuleResponse{
+		ID:          row.ID.String(),
+		Name:        row.Name,
+		Priority:    row.Priority,
+		Enabled:     row.Enabled,
+		LeadTypes:   row.LeadTypes,
+		Zips:        row.Zips,
+		Cities:      row.Cities,
+		MinPrice:    rule.MinPrice,
+		MaxPrice:    rule.MaxPrice,
+		ActiveHours: row.ActiveHours,
+		Strategy:    row.Strategy,
+		Agents:      row.Agents,
+		NextAgent:   rule.Agent(int(row.NextAgent)),
+		CreatedAt:   row.CreatedAt,
+		UpdatedAt:   row.UpdatedAt,
+	}
+}
+
+func (cfg *apiCfg) ListRoutingRules(w http.ResponseWriter, req *http.Request) {
+	rows, err := cfg.DB.ListRoutingRules(req.Context())
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Something went wrong", err)
+		return
+	}
+
+	res := make([]routingRuleResponse, len(rows))
+	for i, row := range rows {
+		res[i] = newRoutingRuleResponse(row)
+	}
+	respondWithJSON(w, http.StatusOK, res)
+}
+
+func (cfg *apiCfg) CreateRoutingRule(w http.ResponseWriter, req *http.Request) {
+	params, ok := decodeRoutingRule(w, req)
+	if !ok {
+		return
+	}
+
+	row, err := cfg.DB.CreateRoutingRule(req.Context(), database.CreateRoutingRuleParams{
+		Name:        params.Name,
+		Priority:    params.Priority,
+		Enabled:     params.enabled(),
+		LeadTypes:   params.LeadTypes,
+		Zips:        params.Zips,
+		Cities:      params.Cities,
+		MinPrice:    nullFloat(params.MinPrice),
+		MaxPrice:    nullFloat(params.MaxPrice),
+		ActiveHours: params.ActiveHours,
+		Strategy:    params.Strategy,
+		Agents:      params.Agents,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Something went wrong", err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, newRoutingRuleResponse(row))
+}
+
+// UpdateRoutingRule replaces a rule. Round-robin rules start again from the
+// first agent.
+func (cfg *apiCfg) UpdateRoutingRule(w http.ResponseWriter, req *http.Request) {
+	UUID, err := uuid.Parse(req.PathValue("id"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid UUID", err)
+		return
+	}
+
+	params, ok := decodeRoutingRule(w, req)
+	if !ok {
+		return
+	}
+
+	row, err := cfg.DB.UpdateRoutingRule(req.Context(), database.UpdateRoutingRuleParams{
+		ID:          UUID,
+		Name:        params.Name,
+		Priority:    params.Priority,
+		Enabled:     params.enabled(),
+		LeadTypes:   params.LeadTypes,
+		Zips:        params.Zips,
+		Cities:      params.Cities,
+		MinPrice:    nullFloat(params.MinPrice),
+		MaxPrice:    nullFloat(params.MaxPrice),
+		ActiveHours: params.ActiveHours,
+		Strategy:    params.Strategy,
+		Agents:      params.Agents,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusNotFound, "Routing rule not found", err)
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Something went wrong", err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, newRoutingRuleResponse(row))
+}
+
+func (cfg *apiCfg) DeleteRoutingRule(w http.ResponseWriter, req *http.Request) {
+	UUID, err := uuid.Parse(req.PathValue("id"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid UUID", err)
+		return
+	}
+
+	deleted, err := cfg.DB.DeleteRoutingRule(req.Context(), UUID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Something went wrong", err)
+		return
+	}
+	if deleted == 0 {
+		respondWithError(w, http.StatusNotFound, "Routing rule not found", nil)
+		return
+	}
+
+	respondWithJSON(w, http.StatusNoContent, nil)
+}
+
+// TestRoutingRules reports which rule and agent a sample lead would get,
+// without advancing any round-robin rule.
+func (cfg *apiCfg) TestRoutingRules(w http.ResponseWriter, req *http.Request) {
+	type reqParams struct {
+		Kind  string     `json:"kind"`
+		Zip   string     `json:"zip"`
+		City  string     `json:"city"`
+		Price float64    `json:"price"`
+		At    *time.Time `json:"at"` // defaults to now
+	}
+	type resParams struct {
+		Matched bool                 `json:"matched"`
+		Rule    *routingRuleResponse `json:"rule,omitempty"`
+		Agent   string               `json:"agent,omitempty"`
+	}
+
+	params := reqParams{}
+	decoder := json.NewDecoder(req.Body)
+	if err := decoder.Decode(&params); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload", err)
+		return
+	}
+	if _, ok := leadKinds[params.Kind]; !ok {
+		respondWithValidationErrors(w, validation.Errors{"kind": "Unknown lead kind"})
+		return
+	}
+
+	at := time.Now()
+	if params.At != nil {
+		at = *params.At
+	}
+
+	row, ok, err := cfg.matchRoutingRule(req.Context(), cfg.DB, routing.Lead{
+		Type:  params.Kind,
+		Zip:   params.Zip,
+		City:  params.City,
+		Price: params.Price,
+	}, at)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Something went wrong", err)
+		return
+	}
+	if !ok {
+		respondWithJSON(w, http.StatusOK, resParams{})
+		return
+	}
+
+	rule := newRoutingRuleResponse(row)
+	respondWithJSON(w, http.StatusOK, resParams{
+		Matched: true,
+		Rule:    &rule,
+		Agent:   rule.NextAgent,
+	})
+}
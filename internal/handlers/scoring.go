@@ -0,0 +1,431 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/DiegoGarciaCo/websitesAPI/internal/crm"
+	"github.com/DiegoGarciaCo/websitesAPI/internal/database"
+	"github.com/DiegoGarciaCo/websitesAPI/internal/scoring"
+	"github.com/DiegoGarciaCo/websitesAPI/internal/validation"
+	"github.com/google/uuid"
+)
+
+// rescoreTimeout bounds a background rescore of every lead.
+const rescoreTimeout = 30 * time.Minute
+
+// leadScorer is implemented by details that carry facts scoring rules
+// match on, such as a price or a seller timeline.
+type leadScorer interface {
+	applyScoring(facts *scoring.Facts)
+}
+
+func scoringFacts(sub leadSubmission, details leadDetails, priorLeads int64) scoring.Facts {
+	facts := scoring.Facts{
+		Type:         sub.Kind,
+		HasPhone:     sub.Phone != "",
+		PriorLeads:   int(priorLeads),
+		PagesVisited: sub.PagesVisited,
+	}
+	if s, ok := details.(leadScorer); ok {
+		s.applyScoring(&facts)
+	}
+	return facts
+}
+
+func scoringRuleFromRow(row database.ScoringRule) scoring.Rule {
+	rule := scoring.Rule{
+		Signal: row.Signal,
+		Value:  row.Value,
+		Points: int(row.Points),
+	}
+	if row.MinValue.Valid {
+		rule.Min = &row.MinValue.Float64
+	}
+	if row.MaxValue.Valid {
+		rule.Max = &row.MaxValue.Float64
+	}
+	return rule
+}
+
+func (cfg *apiCfg) loadScoringRules(ctx context.Context) ([]scoring.Rule, error) {
+	rows, err := cfg.DB.ListScoringRules(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load scoring rules: %w", err)
+	}
+
+	rules := make([]scoring.Rule, len(rows))
+	for i, row := range rows {
+		rules[i] = scoringRuleFromRow(row)
+	}
+	return rules, nil
+}
+
+// scoreLead scores a submission against rules. Earlier leads with the same
+// email, created before the given time, count as repeat submissions.
+func (cfg *apiCfg) scoreLead(ctx context.Context, rules []scoring.Rule, sub leadSubmission, details leadDetails, before time.Time) (int32, error) {
+	prior, err := cfg.DB.CountPriorLeads(ctx, database.CountPriorLeadsParams{
+		Email:  sub.Email,
+		Before: before,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to count prior leads: %w", err)
+	}
+
+	return int32(scoring.Score(rules, scoringFacts(sub, details, prior))), nil
+}
+
+// rescoreLeads recomputes the score of every stored lead with the current
+// rules and returns how many scores changed. Leads are scored a batch at a
+// time, with one query for the batch's prior leads and one for its updates.
+// When the CRM can retag leads, a tag update is queued for each changed
+// score.
+func (cfg *apiCfg) rescoreLeads(ctx context.Context) (int64, error) {
+	rules, err := cfg.loadScoringRules(ctx)
+	if err != nil {
+		return 0, err
+	}
+	_, retag := cfg.CRM.(crm.TagUpdater)
+
+	var changed int64
+	params := database.ListLeadsForExportParams{Limit: exportBatchSize}
+	for {
+		leads, err := cfg.DB.ListLeadsForExport(ctx, params)
+		if err != nil {
+			return changed, fmt.Errorf("failed to list leads: %w", err)
+		}
+		if len(leads) == 0 {
+			return changed, nil
+		}
+
+		ids := make([]uuid.UUID, len(leads))
+		for i, lead := range leads {
+			ids[i] = lead.ID
+		}
+		counts, err := cfg.DB.CountPriorLeadsForLeads(ctx, ids)
+		if err != nil {
+			return changed, fmt.Errorf("failed to count prior leads: %w", err)
+		}
+		prior := make(map[uuid.UUID]int64, len(counts))
+		for _, c := range counts {
+			prior[c.ID] = c.PriorLeads
+		}
+
+		update := database.UpdateLeadScoresParams{}
+		for _, lead := range leads {
+			sub := leadSubmission{}
+			if err := json.Unmarshal(lead.Payload, &sub); err != nil {
+				log.Printf("Error decoding lead %s for scoring: %s", lead.ID, err)
+				continue
+			}
+			_, details, err := decodeLeadDetails(sub)
+			if err != nil {
+				log.Printf("Error decoding lead %s for scoring: %s", lead.ID, err)
+				continue
+			}
+
+			score := int32(scoring.Score(rules, scoringFacts(sub, details, prior[lead.ID])))
+			update.Ids = append(update.Ids, lead.ID)
+			update.Scores = append(update.Scores, score)
+		}
+
+		updated, err := cfg.saveScores(ctx, update, retag)
+		if err != nil {
+			return changed, err
+		}
+		changed += int64(len(updated))
+
+		if len(leads) < exportBatchSize {
+			return changed, nil
+		}
+		last := leads[len(leads)-1]
+		params.AfterCreatedAt, params.AfterID = last.CreatedAt, last.ID
+	}
+}
+
+// saveScores updates a batch of scores and, with retag, queues a CRM tag
+// update for every lead whose score changed, in one transaction.
+func (cfg *apiCfg) saveScores(ctx context.Context, params database.UpdateLeadScoresParams, retag bool) ([]uuid.UUID, error) {
+	tx, err := cfg.SQLDB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	qtx := cfg.DB.WithTx(tx)
+
+	updated, err := qtx.UpdateLeadScores(ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update lead scores: %w", err)
+	}
+	if retag && len(updated) > 0 {
+		err := qtx.CreateLeadDeliveries(ctx, database.CreateLeadDeliveriesParams{
+			LeadIds: updated,
+			Target:  deliveryTargetCRMTags,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to queue CRM tag updates: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return updated, nil
+}
+
+// rescoreJob runs rescores in the background, one at a time per process. A
+// rule change while a rescore is running queues one more run, since the
+// running one may have loaded the old rules.
+type rescoreJob struct {
+	mu      sync.Mutex
+	running bool
+	pending bool
+}
+
+// queueRescore recomputes lead scores in the background once a rule has
+// changed, so the request neither waits for it nor cuts it short. The rule
+// change stands even if rescoring fails; POST /api/scoring/rescore retries.
+func (cfg *apiCfg) queueRescore() {
+	cfg.rescore.mu.Lock()
+	defer cfg.rescore.mu.Unlock()
+
+	if cfg.rescore.running {
+		cfg.rescore.pending = true
+		return
+	}
+	cfg.rescore.running = true
+	go cfg.runRescores()
+}
+
+func (cfg *apiCfg) runRescores() {
+	for {
+		ctx, cancel := context.WithTimeout(context.Background(), rescoreTimeout)
+		changed, err := cfg.rescoreLeads(ctx)
+		cancel()
+		if err != nil {
+			log.Printf("Error rescoring leads: %s", err)
+		} else {
+			log.Printf("Rescored leads, %d scores changed", changed)
+		}
+
+		cfg.rescore.mu.Lock()
+		if !cfg.rescore.pending {
+			cfg.rescore.running = false
+			cfg.rescore.mu.Unlock()
+			return
+		}
+		cfg.rescore.pending = false
+		cfg.rescore.mu.Unlock()
+	}
+}
+
+type scoringRuleParams struct {
+	Signal string   `json:"signal"`
+	Value  string   `json:"value"` // for lead_type and timeline
+	Min    *float64 `json:"min"`   // inclusive, for price, repeat and pages
+	Max    *float64 `json:"max"`   // exclusive
+	Points int32    `json:"points"`
+}
+
+func (p scoringRuleParams) validate() validation.Errors {
+	errs := validation.Errors{}
+
+	if !scoring.ValidSignal(p.Signal) {
+		errs.Add("signal", "Signal must be one of "+strings.Join(scoring.Signals, ", "))
+		return errs
+	}
+
+	switch p.Signal {
+	case scoring.SignalLeadType:
+		if _, ok := leadKinds[p.Value]; !ok {
+			errs.Add("value", "Unknown lead type")
+		}
+	case scoring.SignalTimeline:
+		if !slices.Contains(sellerTimelines, p.Value) {
+			errs.Add("value", "Timeline must be one of "+strings.Join(sellerTimelines, ", "))
+		}
+	}
+	if scoring.Numeric(p.Signal) {
+		if p.Min == nil && p.Max == nil {
+			errs.Add("min", "Set a minimum, a maximum or both")
+		}
+		if p.Min != nil && p.Max != nil && *p.Min >= *p.Max {
+			errs.Add("max", "Maximum must be above the minimum")
+		}
+	}
+	if p.Points < -scoring.MaxScore || p.Points > scoring.MaxScore {
+		errs.Add("points", fmt.Sprintf("Points must be between %d and %d", -scoring.MaxScore, scoring.MaxScore))
+	}
+
+	return errs
+}
+
+// decodeScoringRule reads and validates a rule body, dropping the fields
+// its signal doesn't use. It writes the error response and returns false
+// when the body is unusable.
+func decodeScoringRule(w http.ResponseWriter, req *http.Request) (scoringRuleParams, bool) {
+	params := scoringRuleParams{}
+	decoder := json.NewDecoder(req.Body)
+	if err := decoder.Decode(&params); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload", err)
+		return params, false
+	}
+
+	params.Signal = strings.TrimSpace(params.Signal)
+	params.Value = strings.TrimSpace(params.Value)
+	if errs := params.validate(); len(errs) > 0 {
+		respondWithValidationErrors(w, errs)
+		return params, false
+	}
+
+	if scoring.Numeric(params.Signal) {
+		params.Value = ""
+	} else {
+		params.Min, params.Max = nil, nil
+	}
+	if params.Signal == scoring.SignalPhone {
+		params.Value = ""
+	}
+	return params, true
+}
+
+type scoringRuleResponse struct {
+	ID        string    `json:"id"`
+	Signal    string    `json:"signal"`
+	Value     string    `json:"value,omitempty"`
+	Min       *float64  `json:"min,omitempty"`
+	Max       *float64  `json:"max,omitempty"`
+	Points    int32     `json:"points"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+func newScoringRuleResponse(row database.ScoringRule) scoringRuleResponse {
+	rule := scoringRuleFromRow(row)
+	return scoringRuleResponse{
+		ID:        row.ID.String(),
+		Signal:    row.Signal,
+		Value:     row.Value,
+		Min:       rule.Min,
+		Max:       rule.Max,
+		Points:    row.Points,
+		CreatedAt: row.CreatedAt,
+		UpdatedAt: row.UpdatedAt,
+	}
+}
+
+func (cfg *apiCfg) ListScoringRules(w http.ResponseWriter, req *http.Request) {
+	rows, err := cfg.DB.ListScoringRules(req.Context())
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Something went wrong", err)
+		return
+	}
+
+	res := make([]scoringRuleResponse, len(rows))
+	for i, row := range rows {
+		res[i] = newScoringRuleResponse(row)
+	}
+	respondWithJSON(w, http.StatusOK, res)
+}
+
+func (cfg *apiCfg) CreateScoringRule(w http.ResponseWriter, req *http.Request) {
+	params, ok := decodeScoringRule(w, req)
+	if !ok {
+		return
+	}
+
+	row, err := cfg.DB.CreateScoringRule(req.Context(), database.CreateScoringRuleParams{
+		Signal:   params.Signal,
+		Value:    params.Value,
+		MinValue: nullFloat(params.Min),
+		MaxValue: nullFloat(params.Max),
+		Points:   params.Points,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Something went wrong", err)
+		return
+	}
+
+	cfg.queueRescore()
+	respondWithJSON(w, http.StatusCreated, newScoringRuleResponse(row))
+}
+
+func (cfg *apiCfg) UpdateScoringRule(w http.ResponseWriter, req *http.Request) {
+	UUID, err := uuid.Parse(req.PathValue("id"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid UUID", err)
+		return
+	}
+
+	params, ok := decodeScoringRule(w, req)
+	if !ok {
+		return
+	}
+
+	row, err := cfg.DB.UpdateScoringRule(req.Context(), database.UpdateScoringRuleParams{
+		ID:       UUID,
+		Signal:   params.Signal,
+		Value:    params.Value,
+		MinValue: nullFloat(params.Min),
+		MaxValue: nullFloat(params.Max),
+		Points:   params.Points,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusNotFound, "Scoring rule not found", err)
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Something went wrong", err)
+		return
+	}
+
+	cfg.queueRescore()
+	respondWithJSON(w, http.StatusOK, newScoringRuleResponse(row))
+}
+
+func (cfg *apiCfg) DeleteScoringRule(w http.ResponseWriter, req *http.Request) {
+	UUID, err := uuid.Parse(req.PathValue("id"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid UUID", err)
+		return
+	}
+
+	deleted, err := cfg.DB.DeleteScoringRule(req.Context(), UUID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Something went wrong", err)
+		return
+	}
+	if deleted == 0 {
+		respondWithError(w, http.StatusNotFound, "Scoring rule not found", nil)
+		return
+	}
+
+	cfg.queueRescore()
+	respondWithJSON(w, http.StatusNoContent, nil)
+}
+
+// RescoreLeads recomputes every lead's score with the current rules.
+func (cfg *apiCfg) RescoreLeads(w http.ResponseWriter, req *http.Request) {
+	type resParams struct {
+		Changed int64 `json:"changed"`
+	}
+
+	// A client that gives up waiting doesn't stop the rescore halfway
+	changed, err := cfg.rescoreLeads(context.WithoutCancel(req.Context()))
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Something went wrong", err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, resParams{Changed: changed})
+}
@@ -0,0 +1,153 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/DiegoGarciaCo/websitesAPI/internal/database"
+	"github.com/DiegoGarciaCo/websitesAPI/internal/spam"
+	"github.com/google/uuid"
+)
+
+const maxFormBodyBytes = 1 << 20
+
+// SpamGuard screens public form submissions before they reach the handler.
+// Rejected submissions are stored in the quarantine table and never
+// forwarded to the CRM or Brevo. Silent rejections are answered by decoy,
+// which should look like next's success so bots can't tell they were caught.
+func (cfg *apiCfg) SpamGuard(next, decoy http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		body, err := io.ReadAll(http.MaxBytesReader(w, req.Body, maxFormBodyBytes))
+		if err != nil {
+			respondWithError(w, http.StatusRequestEntityTooLarge, "Request body too large", err)
+			return
+		}
+		req.Body = io.NopCloser(bytes.NewReader(body))
+
+		// Let the handler report malformed JSON
+		var fields map[string]any
+		if err := json.Unmarshal(body, &fields); err != nil {
+			next(w, req)
+			return
+		}
+
+		verdict := cfg.Spam.Check(fields, time.Now())
+		if !verdict.Rejected {
+			next(w, req)
+			return
+		}
+
+		email, _ := fields["email"].(string)
+		err = cfg.DB.CreateQuarantinedSubmission(req.Context(), database.CreateQuarantinedSubmissionParams{
+			Route:     req.URL.Path,
+			Reason:    verdict.Reason,
+			Score:     int32(verdict.Score),
+			Email:     email,
+			IpAddress: clientIP(req),
+			UserAgent: req.UserAgent(),
+			Payload:   body,
+		})
+		if err != nil {
+			log.Printf("Error quarantining submission: %s", err)
+		}
+		log.Printf("Quarantined submission to %s: %s", req.URL.Path, verdict.Reason)
+
+		if verdict.Silent {
+			decoy(w, req)
+			return
+		}
+		respondWithError(w, http.StatusUnprocessableEntity, spamMessage(verdict), nil)
+	}
+}
+
+// DecoyStatus answers a silently rejected submission with code, as a
+// handler whose success has no payload does.
+func DecoyStatus(code int) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		respondWithJSON(w, code, nil)
+	}
+}
+
+// DecoyLeadCreated answers a silently rejected submission as CreateLead
+// does, with the ID of a lead that was never stored.
+func DecoyLeadCreated(w http.ResponseWriter, req *http.Request) {
+	respondWithJSON(w, http.StatusCreated, leadCreatedResponse{ID: uuid.NewString()})
+}
+
+// spamMessage tells a visitor how to fix a rejected submission.
+func spamMessage(verdict spam.Verdict) string {
+	switch {
+	case strings.HasPrefix(verdict.Reason, spam.ReasonToken):
+		return "Form expired, please reload the page and try again"
+	case verdict.Reason == spam.ReasonDisposable:
+		return "Please use a permanent email address"
+	default:
+		return "Your submission could not be accepted"
+	}
+}
+
+// FormToken issues the signed token forms send back as formToken.
+func (cfg *apiCfg) FormToken(w http.ResponseWriter, req *http.Request) {
+	type resParams struct {
+		Token string `json:"token"`
+	}
+	w.Header().Set("Cache-Control", "no-store")
+	respondWithJSON(w, http.StatusOK, resParams{Token: cfg.Spam.IssueToken(time.Now())})
+}
+
+func (cfg *apiCfg) ListQuarantine(w http.ResponseWriter, req *http.Request) {
+	type resParams struct {
+		ID        string          `json:"id"`
+		Route     string          `json:"route"`
+		Reason    string          `json:"reason"`
+		Score     int32           `json:"score"`
+		Email     string          `json:"email"`
+		IPAddress string          `json:"ipAddress"`
+		UserAgent string          `json:"userAgent"`
+		Payload   json.RawMessage `json:"payload"`
+		CreatedAt time.Time       `json:"createdAt"`
+	}
+
+	query := req.URL.Query()
+	page, err := queryInt(query, "page", 1)
+	if err != nil || page < 1 {
+		respondWithError(w, http.StatusBadRequest, "Invalid page", err)
+		return
+	}
+	pageSize, err := queryInt(query, "pageSize", defaultLeadPageSize)
+	if err != nil || pageSize < 1 || pageSize > maxLeadPageSize {
+		respondWithError(w, http.StatusBadRequest, "Invalid pageSize", err)
+		return
+	}
+
+	rows, err := cfg.DB.ListQuarantinedSubmissions(req.Context(), database.ListQuarantinedSubmissionsParams{
+		Limit:  int32(pageSize),
+		Offset: int32((page - 1) * pageSize),
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Something went wrong", err)
+		return
+	}
+
+	res := make([]resParams, len(rows))
+	for i, row := range rows {
+		res[i] = resParams{
+			ID:        row.ID.String(),
+			Route:     row.Route,
+			Reason:    row.Reason,
+			Score:     row.Score,
+			Email:     row.Email,
+			IPAddress: row.IpAddress,
+			UserAgent: row.UserAgent,
+			Payload:   row.Payload,
+			CreatedAt: row.CreatedAt,
+		}
+	}
+
+	respondWithJSON(w, http.StatusOK, res)
+}
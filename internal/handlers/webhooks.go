@@ -0,0 +1,549 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/DiegoGarciaCo/websitesAPI/internal/database"
+	"github.com/DiegoGarciaCo/websitesAPI/internal/validation"
+	"github.com/DiegoGarciaCo/websitesAPI/internal/webhooks"
+	"github.com/google/uuid"
+)
+
+const (
+	eventLeadCreated   = "lead.created"
+	eventPostPublished = "post.published"
+	eventPostDeleted   = "post.deleted"
+)
+
+var webhookEvents = []string{
+	eventLeadCreated,
+	eventPostPublished,
+	eventPostDeleted,
+}
+
+var webhookClient = &http.Client{Timeout: 15 * time.Second}
+
+// webhookPayload is the body sent to subscribers. ID identifies the event
+// and is the same for every subscription it is delivered to.
+type webhookPayload struct {
+	ID        string    `json:"id"`
+	Event     string    `json:"event"`
+	CreatedAt time.Time `json:"createdAt"`
+	Data      any       `json:"data"`
+}
+
+type leadEventData struct {
+	leadResponse
+	Attribution leadAttribution `json:"attribution"`
+}
+
+type postEventData struct {
+	ID      string `json:"id"`
+	Title   string `json:"title,omitempty"`
+	Slug    string `json:"slug,omitempty"`
+	Excerpt string `json:"excerpt,omitempty"`
+}
+
+// emitWebhookEvent queues event for every active subscription to it. Pass
+// a transaction's queries to queue the deliveries atomically with the change
+// that caused the event.
+func emitWebhookEvent(ctx context.Context, db *database.Queries, event string, data any) error {
+	subscriptions, err := db.ListWebhookSubscriptionsForEvent(ctx, event)
+	if err != nil {
+		return fmt.Errorf("failed to list webhook subscriptions: %w", err)
+	}
+	if len(subscriptions) == 0 {
+		return nil
+	}
+
+	payload, err := json.Marshal(webhookPayload{
+		ID:        uuid.NewString(),
+		Event:     event,
+		CreatedAt: time.Now().UTC(),
+		Data:      data,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s event: %w", event, err)
+	}
+
+	for _, sub := range subscriptions {
+		err := db.CreateWebhookDelivery(ctx, database.CreateWebhookDeliveryParams{
+			SubscriptionID: sub.ID,
+			Event:          event,
+			Payload:        payload,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to queue %s webhook: %w", event, err)
+		}
+	}
+
+	return nil
+}
+
+// emitPostEvent queues a post event outside of a transaction. The post has
+// already changed, so failures are logged rather than returned.
+func (cfg *apiCfg) emitPostEvent(ctx context.Context, event string, data postEventData) {
+	if err := emitWebhookEvent(ctx, cfg.DB, event, data); err != nil {
+		log.Printf("Error emitting %s for post %s: %s", event, data.ID, err)
+	}
+}
+
+func newPostEventData(id uuid.UUID, title, slug string, excerpt sql.NullString) postEventData {
+	return postEventData{
+		ID:      id.String(),
+		Title:   title,
+		Slug:    slug,
+		Excerpt: excerpt.String,
+	}
+}
+
+func (cfg *apiCfg) processWebhookDeliveries(ctx context.Context) {
+	deliveries, err := cfg.DB.ClaimWebhookDeliveries(ctx, outboxBatchSize)
+	if err != nil {
+		log.Printf("Error claiming webhook deliveries: %s", err)
+		return
+	}
+
+	for _, delivery := range deliveries {
+		cfg.deliverWebhook(ctx, delivery)
+	}
+}
+
+// deliverWebhook posts one delivery, logs the attempt and either marks the
+// delivery done or schedules the next retry.
+func (cfg *apiCfg) deliverWebhook(ctx context.Context, delivery database.WebhookDelivery) {
+	attempt := database.CreateWebhookDeliveryAttemptParams{
+		DeliveryID: delivery.ID,
+		Attempt:    delivery.Attempts,
+		Status:     "succeeded",
+	}
+
+	start := time.Now()
+	statusCode, deliveryErr := cfg.sendWebhook(ctx, delivery)
+	attempt.DurationMs = int32(time.Since(start).Milliseconds())
+	if statusCode != 0 {
+		attempt.ResponseStatus = sql.NullInt32{Int32: int32(statusCode), Valid: true}
+	}
+	if deliveryErr != nil {
+		attempt.Status = "failed"
+		attempt.Error = sql.NullString{String: deliveryErr.Error(), Valid: true}
+	}
+	if err := cfg.DB.CreateWebhookDeliveryAttempt(ctx, attempt); err != nil {
+		log.Printf("Error recording attempt for webhook delivery %s: %s", delivery.ID, err)
+	}
+
+	if deliveryErr == nil {
+		if err := cfg.DB.MarkWebhookDeliveryDelivered(ctx, delivery.ID); err != nil {
+			log.Printf("Error marking webhook delivery %s delivered: %s", delivery.ID, err)
+		}
+		return
+	}
+
+	log.Printf("Error delivering %s webhook %s (attempt %d): %s", delivery.Event, delivery.ID, delivery.Attempts, deliveryErr)
+
+	status := "pending"
+	if delivery.Attempts >= outboxMaxAttempts {
+		status = "failed"
+	}
+
+	err := cfg.DB.MarkWebhookDeliveryFailed(ctx, database.MarkWebhookDeliveryFailedParams{
+		ID:            delivery.ID,
+		Status:        status,
+		LastError:     sql.NullString{String: deliveryErr.Error(), Valid: true},
+		NextAttemptAt: time.Now().Add(outboxBackoff(delivery.Attempts)),
+	})
+	if err != nil {
+		log.Printf("Error rescheduling webhook delivery %s: %s", delivery.ID, err)
+	}
+}
+
+// sendWebhook posts the signed payload and returns the response status, or
+// zero when no response was received.
+func (cfg *apiCfg) sendWebhook(ctx context.Context, delivery database.WebhookDelivery) (int, error) {
+	sub, err := cfg.DB.GetWebhookSubscription(ctx, delivery.SubscriptionID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to load subscription: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", sub.Url, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, fmt.Errorf("failed to create webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Webhook-Event", delivery.Event)
+	req.Header.Set("X-Webhook-Delivery", delivery.ID.String())
+	webhooks.SetHeaders(req.Header, sub.Secret, time.Now(), delivery.Payload)
+
+	resp, err := webhookClient.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to send webhook request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return resp.StatusCode, fmt.Errorf("webhook request failed with status %s: %s", resp.Status, body)
+	}
+
+	return resp.StatusCode, nil
+}
+
+type webhookSubscriptionResponse struct {
+	ID          string    `json:"id"`
+	URL         string    `json:"url"`
+	Events      []string  `json:"events"`
+	Description string    `json:"description"`
+	Active      bool      `json:"active"`
+	CreatedAt   time.Time `json:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
+
+func newWebhookSubscriptionResponse(sub database.WebhookSubscription) webhookSubscriptionResponse {
+	return webhookSubscriptionResponse{
+		ID:          sub.ID.String(),
+		URL:         sub.Url,
+		Events:      sub.Events,
+		Description: sub.Description,
+		Active:      sub.Active,
+		CreatedAt:   sub.CreatedAt,
+		UpdatedAt:   sub.UpdatedAt,
+	}
+}
+
+type webhookSubscriptionParams struct {
+	URL         string   `json:"url"`
+	Events      []string `json:"events"`
+	Description string   `json:"description"`
+	Active      *bool    `json:"active"`
+}
+
+func (cfg *apiCfg) validateWebhookSubscription(params webhookSubscriptionParams) validation.Errors {
+	errs := validation.Errors{}
+
+	u, err := url.Parse(params.URL)
+	switch {
+	case err != nil || u.Host == "":
+		errs.Add("url", "Enter a valid URL")
+	case u.Scheme != "https" && !(u.Scheme == "http" && cfg.Env != "Production"):
+		errs.Add("url", "URL must use https")
+	}
+
+	if len(params.Events) == 0 {
+		errs.Add("events", "Choose at least one event")
+	}
+	for _, event := range params.Events {
+		if !slices.Contains(webhookEvents, event) {
+			errs.Add("events", fmt.Sprintf("Unknown event %q", event))
+		}
+	}
+
+	return errs
+}
+
+func (cfg *apiCfg) ListWebhookSubscriptions(w http.ResponseWriter, req *http.Request) {
+	subs, err := cfg.DB.ListWebhookSubscriptions(req.Context())
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Something went wrong", err)
+		return
+	}
+
+	res := make([]webhookSubscriptionResponse, len(subs))
+	for i, sub := range subs {
+		res[i] = newWebhookSubscriptionResponse(sub)
+	}
+	respondWithJSON(w, http.StatusOK, res)
+}
+
+// CreateWebhookSubscription registers a URL for events. The signing secret
+// is only returned here.
+func (cfg *apiCfg) CreateWebhookSubscription(w http.ResponseWriter, req *http.Request) {
+	params := webhookSubscriptionParams{}
+	decoder := json.NewDecoder(req.Body)
+	if err := decoder.Decode(&params); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload", err)
+		return
+	}
+	params.URL = strings.TrimSpace(params.URL)
+	if errs := cfg.validateWebhookSubscription(params); len(errs) > 0 {
+		respondWithValidationErrors(w, errs)
+		return
+	}
+
+	secret, err := webhooks.NewSecret()
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Could not generate secret", err)
+		return
+	}
+
+	sub, err := cfg.DB.CreateWebhookSubscription(req.Context(), database.CreateWebhookSubscriptionParams{
+		Url:         params.URL,
+		Secret:      secret,
+		Events:      params.Events,
+		Description: params.Description,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Something went wrong", err)
+		return
+	}
+
+	type resParams struct {
+		webhookSubscriptionResponse
+		Secret string `json:"secret"`
+	}
+	respondWithJSON(w, http.StatusCreated, resParams{
+		webhookSubscriptionResponse: newWebhookSubscriptionResponse(sub),
+		Secret:                      sub.Secret,
+	})
+}
+
+func (cfg *apiCfg) UpdateWebhookSubscription(w http.ResponseWriter, req *http.Request) {
+	UUID, err := uuid.Parse(req.PathValue("id"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid UUID", err)
+		return
+	}
+
+	params := webhookSubscriptionParams{}
+	decoder := json.NewDecoder(req.Body)
+	if err := decoder.Decode(&params); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload", err)
+		return
+	}
+	params.URL = strings.TrimSpace(params.URL)
+	if errs := cfg.validateWebhookSubscription(params); len(errs) > 0 {
+		respondWithValidationErrors(w, errs)
+		return
+	}
+
+	active := true
+	if params.Active != nil {
+		active = *params.Active
+	}
+
+	sub, err := cfg.DB.UpdateWebhookSubscription(req.Context(), database.UpdateWebhookSubscriptionParams{
+		ID:          UUID,
+		Url:         params.URL,
+		Events:      params.Events,
+		Description: params.Description,
+		Active:      active,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusNotFound, "Webhook not found", err)
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Something went wrong", err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, newWebhookSubscriptionResponse(sub))
+}
+
+func (cfg *apiCfg) DeleteWebhookSubscription(w http.ResponseWriter, req *http.Request) {
+	UUID, err := uuid.Parse(req.PathValue("id"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid UUID", err)
+		return
+	}
+
+	deleted, err := cfg.DB.DeleteWebhookSubscription(req.Context(), UUID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Something went wrong", err)
+		return
+	}
+	if deleted == 0 {
+		respondWithError(w, http.StatusNotFound, "Webhook not found", nil)
+		return
+	}
+
+	respondWithJSON(w, http.StatusNoContent, nil)
+}
+
+type webhookDeliveryResponse struct {
+	ID             string     `json:"id"`
+	SubscriptionID string     `json:"subscriptionId"`
+	Event          string     `json:"event"`
+	Status         string     `json:"status"`
+	Attempts       int32      `json:"attempts"`
+	LastError      string     `json:"lastError,omitempty"`
+	NextAttemptAt  time.Time  `json:"nextAttemptAt"`
+	DeliveredAt    *time.Time `json:"deliveredAt,omitempty"`
+	CreatedAt      time.Time  `json:"createdAt"`
+}
+
+func newWebhookDeliveryResponse(d database.WebhookDelivery) webhookDeliveryResponse {
+	res := webhookDeliveryResponse{
+		ID:             d.ID.String(),
+		SubscriptionID: d.SubscriptionID.String(),
+		Event:          d.Event,
+		Status:         d.Status,
+		Attempts:       d.Attempts,
+		LastError:      d.LastError.String,
+		NextAttemptAt:  d.NextAttemptAt,
+		CreatedAt:      d.CreatedAt,
+	}
+	if d.DeliveredAt.Valid {
+		res.DeliveredAt = &d.DeliveredAt.Time
+	}
+	return res
+}
+
+// ListWebhookDeliveries pages through the delivery log, optionally filtered
+// by subscription, event and status.
+func (cfg *apiCfg) ListWebhookDeliveries(w http.ResponseWriter, req *http.Request) {
+	type resParams struct {
+		Deliveries []webhookDeliveryResponse `json:"deliveries"`
+		Total      int64                     `json:"total"`
+		Page       int                       `json:"page"`
+		PageSize   int                       `json:"pageSize"`
+	}
+
+	query := req.URL.Query()
+
+	page, err := queryInt(query, "page", 1)
+	if err != nil || page < 1 {
+		respondWithError(w, http.StatusBadRequest, "Invalid page", err)
+		return
+	}
+	pageSize, err := queryInt(query, "pageSize", defaultLeadPageSize)
+	if err != nil || pageSize < 1 || pageSize > maxLeadPageSize {
+		respondWithError(w, http.StatusBadRequest, "Invalid pageSize", err)
+		return
+	}
+
+	filter := database.CountWebhookDeliveriesParams{
+		Event:  queryNullString(query, "event"),
+		Status: queryNullString(query, "status"),
+	}
+	if v := query.Get("subscription"); v != "" {
+		subscriptionID, err := uuid.Parse(v)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, "Invalid subscription", err)
+			return
+		}
+		filter.SubscriptionID = uuid.NullUUID{UUID: subscriptionID, Valid: true}
+	}
+
+	deliveries, err := cfg.DB.ListWebhookDeliveries(req.Context(), database.ListWebhookDeliveriesParams{
+		SubscriptionID: filter.SubscriptionID,
+		Event:          filter.Event,
+		Status:         filter.Status,
+		Limit:          int32(pageSize),
+		Offset:         int32((page - 1) * pageSize),
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Something went wrong", err)
+		return
+	}
+
+	total, err := cfg.DB.CountWebhookDeliveries(req.Context(), filter)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Something went wrong", err)
+		return
+	}
+
+	res := resParams{
+		Deliveries: make([]webhookDeliveryResponse, len(deliveries)),
+		Total:      total,
+		Page:       page,
+		PageSize:   pageSize,
+	}
+	for i, d := range deliveries {
+		res.Deliveries[i] = newWebhookDeliveryResponse(d)
+	}
+
+	respondWithJSON(w, http.StatusOK, res)
+}
+
+func (cfg *apiCfg) GetWebhookDelivery(w http.ResponseWriter, req *http.Request) {
+	type attemptResponse struct {
+		Attempt        int32     `json:"attempt"`
+		Status         string    `json:"status"`
+		ResponseStatus *int32    `json:"responseStatus,omitempty"`
+		Error          string    `json:"error,omitempty"`
+		DurationMs     int32     `json:"durationMs"`
+		AttemptedAt    time.Time `json:"attemptedAt"`
+	}
+	type resParams struct {
+		webhookDeliveryResponse
+		Payload  json.RawMessage   `json:"payload"`
+		Attempts []attemptResponse `json:"attemptLog"`
+	}
+
+	UUID, err := uuid.Parse(req.PathValue("id"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid UUID", err)
+		return
+	}
+
+	delivery, err := cfg.DB.GetWebhookDelivery(req.Context(), UUID)
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusNotFound, "Delivery not found", err)
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Something went wrong", err)
+		return
+	}
+
+	attempts, err := cfg.DB.ListWebhookDeliveryAttempts(req.Context(), UUID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Something went wrong", err)
+		return
+	}
+
+	res := resParams{
+		webhookDeliveryResponse: newWebhookDeliveryResponse(delivery),
+		Payload:                 delivery.Payload,
+		Attempts:                make([]attemptResponse, len(attempts)),
+	}
+	for i, a := range attempts {
+		res.Attempts[i] = attemptResponse{
+			Attempt:     a.Attempt,
+			Status:      a.Status,
+			Error:       a.Error.String,
+			DurationMs:  a.DurationMs,
+			AttemptedAt: a.AttemptedAt,
+		}
+		if a.ResponseStatus.Valid {
+			res.Attempts[i].ResponseStatus = &a.ResponseStatus.Int32
+		}
+	}
+
+	respondWithJSON(w, http.StatusOK, res)
+}
+
+// ReplayWebhookDelivery queues a delivery to be sent again with a fresh
+// set of retries. The payload and event ID are unchanged so subscribers can
+// deduplicate.
+func (cfg *apiCfg) ReplayWebhookDelivery(w http.ResponseWriter, req *http.Request) {
+	UUID, err := uuid.Parse(req.PathValue("id"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid UUID", err)
+		return
+	}
+
+	delivery, err := cfg.DB.ReplayWebhookDelivery(req.Context(), UUID)
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusConflict, "Delivery not found or in progress", err)
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Something went wrong", err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, newWebhookDeliveryResponse(delivery))
+}
@@ -0,0 +1,115 @@
+// Package idempotency derives keys for repeated requests and records
+// responses so they can be replayed.
+//
+// A client may name a request with the Idempotency-Key header. Requests
+// without one are keyed by a hash of their route and body, so identical
+// submissions within a short window, such as a double-click or a mobile
+// retry, count as one. Keys are scoped to the client, so one client's key
+// never replays another client's response.
+package idempotency
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	Header = "Idempotency-Key"
+	// ReplayedHeader marks a replayed response.
+	ReplayedHeader = "Idempotent-Replayed"
+	MaxKeyLength   = 255
+
+	// How long responses are kept for explicit and derived keys.
+	ExplicitTTL = 24 * time.Hour
+	DerivedTTL  = 10 * time.Minute
+)
+
+var ErrInvalidKey = errors.New("invalid idempotency key")
+
+// Fingerprint identifies a request by its method, route and body.
+func Fingerprint(method, route string, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(method + " " + route + "\n"))
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Key returns the storage key of a request and how long its response is
+// kept. client identifies the caller, such as their IP address. header is
+// the Idempotency-Key header, possibly empty; fingerprint is from
+// Fingerprint.
+func Key(route, client, header, fingerprint string) (string, time.Duration, error) {
+	header = strings.TrimSpace(header)
+	if header == "" {
+		return "hash:" + route + ":" + scoped(client, fingerprint), DerivedTTL, nil
+	}
+	if len(header) > MaxKeyLength {
+		return "", 0, ErrInvalidKey
+	}
+	return "key:" + route + ":" + scoped(client, header), ExplicitTTL, nil
+}
+
+// scoped hashes a key with the client it belongs to, which also keeps
+// client addresses out of the stored keys.
+func scoped(client, key string) string {
+	h := sha256.New()
+	h.Write([]byte(strconv.Itoa(len(client)) + ":" + client))
+	h.Write([]byte(key))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Recorder passes a response through to the client while keeping a copy.
+type Recorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func NewRecorder(w http.ResponseWriter) *Recorder {
+	return &Recorder{ResponseWriter: w}
+}
+
+func (r *Recorder) WriteHeader(status int) {
+	if r.status == 0 {
+		r.status = status
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *Recorder) Write(b []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
+
+// Status is the response status, 200 if the handler wrote nothing.
+func (r *Recorder) Status() int {
+	if r.status == 0 {
+		return http.StatusOK
+	}
+	return r.status
+}
+
+// Body is a copy of everything written.
+func (r *Recorder) Body() []byte {
+	return r.body.Bytes()
+}
+
+// Replayable reports whether a response should be stored. Server errors
+// and transient client errors, such as rate limiting, aren't, so the
+// client's retry runs the handler again.
+func Replayable(status int) bool {
+	switch status {
+	case http.StatusRequestTimeout, http.StatusLocked, http.StatusTooEarly, http.StatusTooManyRequests:
+		return false
+	}
+	return status < http.StatusInternalServerError
+}
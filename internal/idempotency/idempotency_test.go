@@ -0,0 +1,125 @@
+package idempotency
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestKey(t *testing.T) {
+	fp := Fingerprint("POST", "/api/estimate", []byte(`{"email":"jane@example.com"}`))
+
+	tests := []struct {
+		name       string
+		route      string
+		client     string
+		header     string
+		wantPrefix string
+		wantTTL    bool // true for ExplicitTTL
+		wantErr    error
+	}{
+		{"Derived from the payload", "/api/estimate", "203.0.113.7", "", "hash:/api/estimate:", false, nil},
+		{"Explicit header", "/api/estimate", "203.0.113.7", "abc-123", "key:/api/estimate:", true, nil},
+		{"Header too long", "/api/estimate", "203.0.113.7", strings.Repeat("a", MaxKeyLength+1), "", false, ErrInvalidKey},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, ttl, err := Key(tt.route, tt.client, tt.header, fp)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Key() error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if !strings.HasPrefix(key, tt.wantPrefix) {
+				t.Errorf("Key() = %q, want prefix %q", key, tt.wantPrefix)
+			}
+			if (ttl == ExplicitTTL) != tt.wantTTL {
+				t.Errorf("Key() ttl = %v", ttl)
+			}
+		})
+	}
+}
+
+func TestKeyScope(t *testing.T) {
+	fp := Fingerprint("POST", "/api/estimate", []byte(`{"email":"jane@example.com"}`))
+	key := func(route, client, header string) string {
+		k, _, err := Key(route, client, header, fp)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return k
+	}
+
+	if key("/api/estimate", "203.0.113.7", "abc-123") != key("/api/estimate", "203.0.113.7", "  abc-123 ") {
+		t.Error("Key() differs for a padded header")
+	}
+	if key("/api/estimate", "203.0.113.7", "abc-123") == key("/api/estimate", "198.51.100.2", "abc-123") {
+		t.Error("Key() is shared between clients")
+	}
+	if key("/api/estimate", "203.0.113.7", "") == key("/api/estimate", "198.51.100.2", "") {
+		t.Error("Derived Key() is shared between clients")
+	}
+	if key("/api/estimate", "203.0.113.7", "abc-123") == key("/api/showing", "203.0.113.7", "abc-123") {
+		t.Error("Key() is shared between routes")
+	}
+	if strings.Contains(key("/api/estimate", "203.0.113.7", "abc-123"), "203.0.113.7") {
+		t.Error("Key() stores the client address")
+	}
+}
+
+func TestReplayable(t *testing.T) {
+	tests := map[int]bool{
+		http.StatusOK:                  true,
+		http.StatusCreated:             true,
+		http.StatusBadRequest:          true,
+		http.StatusUnprocessableEntity: true,
+		http.StatusRequestTimeout:      false,
+		http.StatusTooEarly:            false,
+		http.StatusTooManyRequests:     false,
+		http.StatusInternalServerError: false,
+		http.StatusBadGateway:          false,
+	}
+
+	for status, want := range tests {
+		if got := Replayable(status); got != want {
+			t.Errorf("Replayable(%d) = %v, want %v", status, got, want)
+		}
+	}
+}
+
+func TestFingerprint(t *testing.T) {
+	a := Fingerprint("POST", "/api/leads", []byte(`{"a":1}`))
+	if a != Fingerprint("POST", "/api/leads", []byte(`{"a":1}`)) {
+		t.Error("Fingerprint() differs for identical requests")
+	}
+	if a == Fingerprint("POST", "/api/leads", []byte(`{"a":2}`)) {
+		t.Error("Fingerprint() matches for different bodies")
+	}
+	if a == Fingerprint("POST", "/api/estimate", []byte(`{"a":1}`)) {
+		t.Error("Fingerprint() matches for different routes")
+	}
+}
+
+func TestRecorder(t *testing.T) {
+	w := httptest.NewRecorder()
+	rec := NewRecorder(w)
+	rec.WriteHeader(http.StatusCreated)
+	rec.Write([]byte(`{"id":"1"}`))
+
+	if rec.Status() != http.StatusCreated || string(rec.Body()) != `{"id":"1"}` {
+		t.Errorf("Recorder kept %d %q", rec.Status(), rec.Body())
+	}
+	if w.Code != http.StatusCreated || w.Body.String() != `{"id":"1"}` {
+		t.Errorf("Recorder passed through %d %q", w.Code, w.Body.String())
+	}
+
+	implicit := NewRecorder(httptest.NewRecorder())
+	implicit.Write([]byte("ok"))
+	if implicit.Status() != http.StatusOK {
+		t.Errorf("Status() = %d without WriteHeader, want 200", implicit.Status())
+	}
+}
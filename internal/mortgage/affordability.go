@@ -0,0 +1,141 @@
+package mortgage
+
+import "math"
+
+// DTILimits are the debt-to-income ratios a program lends up to, as shares
+// of gross monthly income. The front-end ratio covers housing and the
+// back-end ratio housing plus other debts. Zero means no limit.
+type DTILimits struct {
+	FrontEnd float64 `json:"frontEnd"`
+	BackEnd  float64 `json:"backEnd"`
+}
+
+var dtiLimits = map[string]DTILimits{
+	ProgramConventional: {FrontEnd: 0.28, BackEnd: 0.36},
+	ProgramFHA:          {FrontEnd: 0.31, BackEnd: 0.43},
+	ProgramVA:           {BackEnd: 0.41},
+	ProgramUSDA:         {FrontEnd: 0.29, BackEnd: 0.41},
+}
+
+// Limits returns the DTI limits of program, conventional when empty.
+func Limits(program string) DTILimits {
+	if program == "" {
+		program = ProgramConventional
+	}
+	return dtiLimits[program]
+}
+
+// What caps an affordable price.
+const (
+	LimitFrontEnd    = "frontEnd"
+	LimitBackEnd     = "backEnd"
+	LimitDownPayment = "downPayment" // the program's minimum down payment
+)
+
+// maxAffordablePrice bounds the search for a price.
+const maxAffordablePrice = 100_000_000
+
+// Buyer describes someone working out what they can afford.
+type Buyer struct {
+	MonthlyIncome float64 // gross
+	MonthlyDebts  float64
+	DownPayment   float64 // amount available
+	AnnualRate    float64 // percent
+	Years         int
+	Program       string // one of Programs, conventional when empty
+	CreditScore   int    // zero when unknown
+	// VASubsequentUse prices the VA funding fee for a borrower who has used
+	// the benefit before.
+	VASubsequentUse bool
+}
+
+// loan is b's loan for a home at price, putting all of the down payment
+// toward it.
+func (b Buyer) loan(price float64) Loan {
+	return Loan{
+		Price:       price,
+		DownPayment: math.Min(b.DownPayment, price),
+		AnnualRate:  b.AnnualRate,
+		Years:       b.Years,
+		Program:     b.Program,
+		CreditScore: b.CreditScore,
+
+		VASubsequentUse: b.VASubsequentUse,
+	}
+}
+
+// Affordability is the most a buyer can pay for a home.
+type Affordability struct {
+	MaxPrice float64   `json:"maxPrice"`
+	Limits   DTILimits `json:"limits"`
+	// MaxHousingPayment is the most the DTI limits allow for the monthly
+	// payment including mortgage insurance, taxes and insurance.
+	MaxHousingPayment float64 `json:"maxHousingPayment"`
+	// LimitedBy names what caps MaxPrice.
+	LimitedBy string `json:"limitedBy"`
+	// FrontEndRatio and BackEndRatio are the buyer's DTI ratios at MaxPrice.
+	FrontEndRatio float64 `json:"frontEndRatio"`
+	BackEndRatio  float64 `json:"backEndRatio"`
+	// Breakdown is the monthly cost at MaxPrice.
+	Breakdown Breakdown `json:"breakdown"`
+}
+
+// Afford solves for the highest whole-dollar price whose monthly cost, with
+// the tax and insurance of a, stays within b's program's DTI limits and
+// whose down payment meets the program's minimum. The cost rises with the
+// price, so the price is found by bisection.
+func Afford(b Buyer, a Assumptions) Affordability {
+	limits := Limits(b.Program)
+	res := Affordability{Limits: limits}
+
+	maxHousing := math.Inf(1)
+	res.LimitedBy = LimitBackEnd
+	if limits.BackEnd > 0 {
+		maxHousing = b.MonthlyIncome*limits.BackEnd - b.MonthlyDebts
+	}
+	if limits.FrontEnd > 0 && b.MonthlyIncome*limits.FrontEnd <= maxHousing {
+		maxHousing = b.MonthlyIncome * limits.FrontEnd
+		res.LimitedBy = LimitFrontEnd
+	}
+	res.MaxHousingPayment = round(math.Max(maxHousing, 0))
+
+	cost := func(price float64) float64 {
+		return Calculate(b.loan(price), a).TotalPayment
+	}
+
+	hi := float64(maxAffordablePrice)
+	if min := MinDownPayment(b.Program, b.CreditScore); min > 0 && b.DownPayment/min < hi {
+		hi = math.Floor(b.DownPayment / min)
+		if cost(hi) <= maxHousing {
+			res.LimitedBy = LimitDownPayment
+		}
+	}
+
+	var price float64
+	switch {
+	case cost(0) > maxHousing:
+		price = 0
+	case cost(hi) <= maxHousing:
+		price = hi
+	default:
+		// cost(lo) always fits and cost(hi) never does.
+		lo := 0.0
+		for hi-lo > 1 {
+			mid := math.Floor((lo + hi) / 2)
+			if cost(mid) <= maxHousing {
+				lo = mid
+			} else {
+				hi = mid
+			}
+		}
+		price = lo
+	}
+
+	res.MaxPrice = price
+	res.Breakdown = Calculate(b.loan(price), a)
+	if b.MonthlyIncome > 0 {
+		res.FrontEndRatio = math.Round(res.Breakdown.TotalPayment/b.MonthlyIncome*1e4) / 1e4
+		res.BackEndRatio = math.Round((res.Breakdown.TotalPayment+b.MonthlyDebts)/b.MonthlyIncome*1e4) / 1e4
+	}
+	return res
+}
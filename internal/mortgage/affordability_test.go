@@ -0,0 +1,71 @@
+package mortgage
+
+import "testing"
+
+func TestAfford(t *testing.T) {
+	tests := []struct {
+		name          string
+		buyer         Buyer
+		wantLimitedBy string
+		wantZero      bool
+	}{
+		{"Front-end limit", Buyer{MonthlyIncome: 10_000, MonthlyDebts: 200, DownPayment: 80_000, AnnualRate: 6.5, Years: 30}, LimitFrontEnd, false},
+		{"Back-end limit", Buyer{MonthlyIncome: 10_000, MonthlyDebts: 1_500, DownPayment: 80_000, AnnualRate: 6.5, Years: 30}, LimitBackEnd, false},
+		{"VA has no front-end limit", Buyer{MonthlyIncome: 10_000, MonthlyDebts: 200, AnnualRate: 6, Years: 30, Program: ProgramVA}, LimitBackEnd, false},
+		{"FHA", Buyer{MonthlyIncome: 6_000, MonthlyDebts: 800, DownPayment: 15_000, AnnualRate: 6.75, Years: 30, Program: ProgramFHA, CreditScore: 640}, LimitBackEnd, false},
+		{"USDA", Buyer{MonthlyIncome: 5_000, AnnualRate: 6, Years: 30, Program: ProgramUSDA}, LimitFrontEnd, false},
+		{"Minimum down payment", Buyer{MonthlyIncome: 20_000, DownPayment: 6_000, AnnualRate: 6, Years: 30}, LimitDownPayment, false},
+		{"No down payment for a conventional loan", Buyer{MonthlyIncome: 20_000, AnnualRate: 6, Years: 30}, LimitDownPayment, true},
+		{"Debts above the back-end limit", Buyer{MonthlyIncome: 4_000, MonthlyDebts: 2_000, DownPayment: 50_000, AnnualRate: 6, Years: 30}, LimitBackEnd, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Afford(tt.buyer, Defaults)
+
+			if got.LimitedBy != tt.wantLimitedBy {
+				t.Errorf("LimitedBy = %q, want %q", got.LimitedBy, tt.wantLimitedBy)
+			}
+			if (got.MaxPrice == 0) != tt.wantZero {
+				t.Fatalf("MaxPrice = %v", got.MaxPrice)
+			}
+			if got.Breakdown.Price != got.MaxPrice {
+				t.Errorf("Breakdown is for %v, want %v", got.Breakdown.Price, got.MaxPrice)
+			}
+			if tt.wantZero {
+				return
+			}
+
+			if got.Breakdown.TotalPayment > got.MaxHousingPayment {
+				t.Errorf("payment %v at the max price exceeds %v", got.Breakdown.TotalPayment, got.MaxHousingPayment)
+			}
+			limits := Limits(tt.buyer.Program)
+			if limits.FrontEnd > 0 && got.FrontEndRatio > limits.FrontEnd {
+				t.Errorf("FrontEndRatio = %v, above %v", got.FrontEndRatio, limits.FrontEnd)
+			}
+			if got.BackEndRatio > limits.BackEnd {
+				t.Errorf("BackEndRatio = %v, above %v", got.BackEndRatio, limits.BackEnd)
+			}
+
+			next := Calculate(tt.buyer.loan(got.MaxPrice+1), Defaults)
+			if tt.wantLimitedBy == LimitDownPayment {
+				if share := tt.buyer.DownPayment / (got.MaxPrice + 1); share >= MinDownPayment(tt.buyer.Program, tt.buyer.CreditScore) {
+					t.Errorf("a dollar more still meets the minimum down payment")
+				}
+			} else if next.TotalPayment <= got.MaxHousingPayment {
+				t.Errorf("a dollar more still fits: %v <= %v", next.TotalPayment, got.MaxHousingPayment)
+			}
+		})
+	}
+}
+
+func TestLimits(t *testing.T) {
+	if Limits("") != Limits(ProgramConventional) {
+		t.Errorf("Limits() of no program isn't conventional")
+	}
+	for _, p := range Programs {
+		if Limits(p).BackEnd == 0 {
+			t.Errorf("Limits(%q) has no back-end limit", p)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package mortgage
+
+import "strings"
+
+// Scopes of assumptions, from the most to the least specific.
+const (
+	ScopeZip     = "zip"
+	ScopeCounty  = "county"
+	ScopeState   = "state"
+	ScopeDefault = "default"
+)
+
+// Location is where a home is. Any part may be empty.
+type Location struct {
+	State  string `json:"state,omitempty"`
+	County string `json:"county,omitempty"`
+	Zip    string `json:"zip,omitempty"`
+}
+
+// Normalize upper-cases the state, drops a trailing "County" from the
+// county and keeps the five-digit part of a ZIP+4 code.
+func (l Location) Normalize() Location {
+	l.State = strings.ToUpper(strings.TrimSpace(l.State))
+	l.County = strings.TrimSpace(l.County)
+	if len(l.County) > len(" county") && strings.EqualFold(l.County[len(l.County)-len(" county"):], " county") {
+		l.County = strings.TrimSpace(l.County[:len(l.County)-len(" county")])
+	}
+	l.Zip = strings.TrimSpace(l.Zip)
+	if i := strings.IndexByte(l.Zip, '-'); i >= 0 {
+		l.Zip = l.Zip[:i]
+	}
+	return l
+}
+
+// Scope is the most specific part l is keyed by.
+func (l Location) Scope() string {
+	switch {
+	case l.Zip != "":
+		return ScopeZip
+	case l.County != "":
+		return ScopeCounty
+	case l.State != "":
+		return ScopeState
+	default:
+		return ScopeDefault
+	}
+}
+
+// Assumptions are the property tax and insurance of a location.
+type Assumptions struct {
+	TaxRate         float64 `json:"taxRate"` // yearly share of the price
+	AnnualInsurance float64 `json:"annualInsurance"`
+	// Scope and Location name the rule the assumptions came from, or
+	// ScopeDefault and an empty location for the defaults.
+	Scope    string   `json:"scope"`
+	Location Location `json:"location"`
+}
+
+// Defaults are the assumptions used when no rule matches.
+var Defaults = Assumptions{TaxRate: TaxRate, AnnualInsurance: AnnualInsurance, Scope: ScopeDefault}
+
+// Rule sets the assumptions of every home in its location. A zip rule
+// matches on the ZIP code alone, a county rule on the state and county, and
+// a state rule on the state.
+type Rule struct {
+	Location
+	TaxRate         float64
+	AnnualInsurance float64
+}
+
+func (r Rule) matches(loc Location) bool {
+	switch r.Scope() {
+	case ScopeZip:
+		return r.Zip == loc.Zip
+	case ScopeCounty:
+		return r.State == loc.State && strings.EqualFold(r.County, loc.County)
+	case ScopeState:
+		return r.State == loc.State
+	}
+	return false
+}
+
+// Resolve picks the assumptions of the most specific rule matching loc,
+// falling back to defaults. Among rules of the same scope the first wins.
+func Resolve(rules []Rule, loc Location, defaults Assumptions) Assumptions {
+	loc = loc.Normalize()
+	for _, scope := range []string{ScopeZip, ScopeCounty, ScopeState} {
+		for _, r := range rules {
+			if r.Scope() != scope || !r.matches(loc) {
+				continue
+			}
+			return Assumptions{
+				TaxRate:         r.TaxRate,
+				AnnualInsurance: r.AnnualInsurance,
+				Scope:           scope,
+				Location:        r.Location,
+			}
+		}
+	}
+
+	defaults.Scope = ScopeDefault
+	defaults.Location = Location{}
+	return defaults
+}
+
+// Source describes where a came from, such as "Denver County, CO".
+func (a Assumptions) Source() string {
+	switch a.Scope {
+	case ScopeZip:
+		return "ZIP " + a.Location.Zip
+	case ScopeCounty:
+		return a.Location.County + " County, " + a.Location.State
+	case ScopeState:
+		return a.Location.State
+	default:
+		return "Default"
+	}
+}
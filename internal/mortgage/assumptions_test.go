@@ -0,0 +1,77 @@
+package mortgage
+
+import "testing"
+
+func TestLocationNormalize(t *testing.T) {
+	tests := []struct {
+		name string
+		loc  Location
+		want Location
+	}{
+		{"Already normal", Location{State: "CO", County: "Denver", Zip: "80202"}, Location{State: "CO", County: "Denver", Zip: "80202"}},
+		{"Lower-case state", Location{State: " co "}, Location{State: "CO"}},
+		{"County suffix", Location{County: "El Paso County"}, Location{County: "El Paso"}},
+		{"Only the suffix", Location{County: " County"}, Location{County: "County"}},
+		{"ZIP+4", Location{Zip: "80202-1234"}, Location{Zip: "80202"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.loc.Normalize(); got != tt.want {
+				t.Errorf("Normalize() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolve(t *testing.T) {
+	rules := []Rule{
+		{Location: Location{State: "CO"}, TaxRate: 0.005, AnnualInsurance: 2500},
+		{Location: Location{State: "CO", County: "Denver"}, TaxRate: 0.006, AnnualInsurance: 2400},
+		{Location: Location{Zip: "80202"}, TaxRate: 0.007, AnnualInsurance: 2300},
+		{Location: Location{State: "TX"}, TaxRate: 0.018, AnnualInsurance: 3900},
+	}
+	defaults := Assumptions{TaxRate: 0.01, AnnualInsurance: 1000}
+
+	tests := []struct {
+		name string
+		loc  Location
+		want Assumptions
+	}{
+		{"Zip wins", Location{State: "CO", County: "Denver", Zip: "80202-0001"}, Assumptions{TaxRate: 0.007, AnnualInsurance: 2300, Scope: ScopeZip, Location: Location{Zip: "80202"}}},
+		{"County without a zip rule", Location{State: "co", County: "denver county", Zip: "80203"}, Assumptions{TaxRate: 0.006, AnnualInsurance: 2400, Scope: ScopeCounty, Location: Location{State: "CO", County: "Denver"}}},
+		{"County needs the state", Location{State: "TX", County: "Denver"}, Assumptions{TaxRate: 0.018, AnnualInsurance: 3900, Scope: ScopeState, Location: Location{State: "TX"}}},
+		{"State", Location{State: "CO", County: "Boulder"}, Assumptions{TaxRate: 0.005, AnnualInsurance: 2500, Scope: ScopeState, Location: Location{State: "CO"}}},
+		{"Defaults", Location{State: "NM"}, Assumptions{TaxRate: 0.01, AnnualInsurance: 1000, Scope: ScopeDefault}},
+		{"No location", Location{}, Assumptions{TaxRate: 0.01, AnnualInsurance: 1000, Scope: ScopeDefault}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Resolve(rules, tt.loc, defaults); got != tt.want {
+				t.Errorf("Resolve() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAssumptionsSource(t *testing.T) {
+	tests := []struct {
+		name string
+		a    Assumptions
+		want string
+	}{
+		{"Zip", Assumptions{Scope: ScopeZip, Location: Location{Zip: "80202"}}, "ZIP 80202"},
+		{"County", Assumptions{Scope: ScopeCounty, Location: Location{State: "CO", County: "Denver"}}, "Denver County, CO"},
+		{"State", Assumptions{Scope: ScopeState, Location: Location{State: "CO"}}, "CO"},
+		{"Default", Defaults, "Default"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.a.Source(); got != tt.want {
+				t.Errorf("Source() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,240 @@
+// Package mortgage calculates fixed-rate mortgage payments and schedules.
+package mortgage
+
+import "math"
+
+const (
+	// PMIThreshold is the loan-to-value ratio above which conventional
+	// loans pay PMI.
+	PMIThreshold = 0.8
+	// PMICancelLTV is the loan-to-value ratio, against the original price,
+	// at which conventional PMI is cancelled automatically.
+	PMICancelLTV = 0.78
+
+	// TaxRate is the default yearly property tax as a share of the price.
+	TaxRate = 0.0211
+	// AnnualInsurance is the default yearly homeowners insurance premium.
+	AnnualInsurance = 2119
+)
+
+// Payment is the monthly principal and interest payment, rounded to cents,
+// for a loan of principal at annualRate percent over years.
+func Payment(principal, annualRate float64, years int) float64 {
+	monthlyRate := (annualRate / 100) / 12
+	n := float64(years * 12)
+
+	if monthlyRate == 0 {
+		return round(principal / n)
+	}
+
+	m := principal * (monthlyRate * math.Pow(1+monthlyRate, n)) / (math.Pow(1+monthlyRate, n) - 1)
+	return round(m)
+}
+
+func round(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+// Loan describes a purchase financed with a fixed-rate mortgage.
+type Loan struct {
+	Price       float64
+	DownPayment float64 // amount, not percent
+	AnnualRate  float64 // percent
+	Years       int
+	Program     string // one of Programs, conventional when empty
+	CreditScore int    // zero when unknown
+	// VASubsequentUse prices the VA funding fee for a borrower who has used
+	// the benefit before.
+	VASubsequentUse bool
+}
+
+func (l Loan) program() string {
+	if l.Program == "" {
+		return ProgramConventional
+	}
+	return l.Program
+}
+
+func (l Loan) downShare() float64 {
+	if l.Price <= 0 {
+		return 0
+	}
+	return l.DownPayment / l.Price
+}
+
+// BaseLoan is the price less the down payment.
+func (l Loan) BaseLoan() float64 {
+	return l.Price - l.DownPayment
+}
+
+// LTV is the loan-to-value ratio of the base loan.
+func (l Loan) LTV() float64 {
+	if l.Price <= 0 {
+		return 0
+	}
+	return l.BaseLoan() / l.Price
+}
+
+// UpfrontFee is the FHA upfront premium, VA funding fee or USDA guarantee
+// fee. It is financed.
+func (l Loan) UpfrontFee() float64 {
+	switch l.program() {
+	case ProgramFHA:
+		return round(l.BaseLoan() * FHAUpfrontMIP)
+	case ProgramVA:
+		return round(l.BaseLoan() * vaFundingFee(l.downShare(), l.VASubsequentUse))
+	case ProgramUSDA:
+		return round(l.BaseLoan() * USDAUpfrontFee)
+	default:
+		return 0
+	}
+}
+
+// Principal is the amount borrowed, including a financed upfront fee.
+func (l Loan) Principal() float64 {
+	return l.BaseLoan() + l.UpfrontFee()
+}
+
+// MonthlyPMI is the mortgage insurance premium while it applies: PMI on
+// conventional loans, annual MIP on FHA loans and the annual guarantee fee
+// on USDA loans. VA loans have none.
+func (l Loan) MonthlyPMI() float64 {
+	var rate float64
+	switch l.program() {
+	case ProgramFHA:
+		rate = fhaAnnualMIP(l.LTV(), l.Years)
+	case ProgramVA:
+		rate = 0
+	case ProgramUSDA:
+		rate = USDAAnnualFee
+	default:
+		rate = conventionalPMI(l.LTV(), l.CreditScore)
+	}
+	return round(l.BaseLoan() * rate / 12)
+}
+
+// chargesPMI reports whether mortgage insurance is due in month, given the
+// balance before its payment.
+func (l Loan) chargesPMI(month int, balance float64) bool {
+	switch l.program() {
+	case ProgramFHA:
+		return l.downShare() < FHAMIPDownPayment || month <= FHAMIPYears*12
+	case ProgramUSDA:
+		return true
+	case ProgramVA:
+		return false
+	default:
+		return balance > l.Price*PMICancelLTV
+	}
+}
+
+// Month is one row of an amortization schedule.
+type Month struct {
+	Number             int     `json:"month"`
+	Payment            float64 `json:"payment"` // principal and interest
+	Principal          float64 `json:"principal"`
+	Interest           float64 `json:"interest"`
+	PMI                float64 `json:"pmi"`
+	Balance            float64 `json:"balance"` // after the payment
+	CumulativeInterest float64 `json:"cumulativeInterest"`
+}
+
+// Schedule is a loan's month-by-month amortization.
+type Schedule struct {
+	Payment    float64 `json:"payment"`
+	MonthlyPMI float64 `json:"monthlyPmi"`
+	// PMIDropOffMonth is the last month mortgage insurance is charged, zero
+	// without it. Conventional PMI ends once the scheduled balance reaches
+	// PMICancelLTV of the price, FHA MIP after FHAMIPYears with enough down.
+	PMIDropOffMonth int     `json:"pmiDropOffMonth"`
+	TotalInterest   float64 `json:"totalInterest"`
+	TotalPMI        float64 `json:"totalPmi"`
+	Months          []Month `json:"months"`
+}
+
+// Amortize builds the schedule of l. Amounts are rounded to cents each
+// month and the final payment absorbs the rounding, so the balance ends at
+// exactly zero.
+func Amortize(l Loan) Schedule {
+	payment := Payment(l.Principal(), l.AnnualRate, l.Years)
+	monthlyPMI := l.MonthlyPMI()
+	monthlyRate := (l.AnnualRate / 100) / 12
+	n := l.Years * 12
+
+	s := Schedule{
+		Payment:    round(payment),
+		MonthlyPMI: monthlyPMI,
+		Months:     make([]Month, 0, n),
+	}
+
+	balance := round(l.Principal())
+	var cumulative float64
+	for i := 1; i <= n && balance > 0; i++ {
+		interest := round(balance * monthlyRate)
+		principal := round(payment - interest)
+		if i == n || principal > balance {
+			principal = balance
+		}
+
+		pmi := 0.0
+		if monthlyPMI > 0 && l.chargesPMI(i, balance) {
+			pmi = monthlyPMI
+			s.PMIDropOffMonth = i
+		}
+
+		balance = round(balance - principal)
+		cumulative = round(cumulative + interest)
+		s.TotalPMI = round(s.TotalPMI + pmi)
+		s.Months = append(s.Months, Month{
+			Number:             i,
+			Payment:            round(principal + interest),
+			Principal:          principal,
+			Interest:           interest,
+			PMI:                pmi,
+			Balance:            balance,
+			CumulativeInterest: cumulative,
+		})
+	}
+	s.TotalInterest = cumulative
+
+	return s
+}
+
+// Breakdown is the monthly cost of a loan.
+type Breakdown struct {
+	Price        float64 `json:"price"`
+	DownPayment  float64 `json:"downPayment"`
+	Program      string  `json:"program"`
+	UpfrontFee   float64 `json:"upfrontFee"` // financed
+	LoanAmount   float64 `json:"loanAmount"`
+	Interest     float64 `json:"interest"` // percent
+	Years        int     `json:"years"`
+	Payment      float64 `json:"payment"` // principal and interest
+	MonthlyPMI   float64 `json:"monthlyPmi"`
+	Taxes        float64 `json:"taxes"`
+	Insurance    float64 `json:"insurance"`
+	TotalPayment float64 `json:"totalPayment"`
+	// Assumptions are the tax rate and insurance the costs are based on.
+	Assumptions Assumptions `json:"assumptions"`
+}
+
+// Calculate breaks down the monthly cost of l, including mortgage insurance
+// and the property tax and insurance of a.
+func Calculate(l Loan, a Assumptions) Breakdown {
+	b := Breakdown{
+		Price:       l.Price,
+		DownPayment: round(l.DownPayment),
+		Program:     l.program(),
+		UpfrontFee:  l.UpfrontFee(),
+		LoanAmount:  round(l.Principal()),
+		Interest:    l.AnnualRate,
+		Years:       l.Years,
+		Payment:     round(Payment(l.Principal(), l.AnnualRate, l.Years)),
+		MonthlyPMI:  l.MonthlyPMI(),
+		Taxes:       round(l.Price * a.TaxRate / 12),
+		Insurance:   round(a.AnnualInsurance / 12),
+		Assumptions: a,
+	}
+	b.TotalPayment = round(b.Payment + b.MonthlyPMI + b.Taxes + b.Insurance)
+	return b
+}
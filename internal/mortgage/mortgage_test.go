@@ -0,0 +1,122 @@
+package mortgage
+
+import (
+	"math"
+	"testing"
+)
+
+func TestPayment(t *testing.T) {
+	tests := []struct {
+		name      string
+		principal float64
+		rate      float64
+		years     int
+		want      float64
+	}{
+		{"30 years at 6%", 200_000, 6, 30, 1199.10},
+		{"15 years at 7.5%", 300_000, 7.5, 15, 2781.04},
+		{"Zero interest", 120_000, 0, 10, 1000},
+		{"Zero interest rounds to cents", 100_000, 0, 30, 277.78},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Payment(tt.principal, tt.rate, tt.years); got != tt.want {
+				t.Errorf("Payment() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCalculate(t *testing.T) {
+	tests := []struct {
+		name string
+		loan Loan
+		want Breakdown
+	}{
+		{
+			"20% down",
+			Loan{Price: 250_000, DownPayment: 50_000, AnnualRate: 6, Years: 30},
+			Breakdown{Price: 250_000, DownPayment: 50_000, Program: ProgramConventional, LoanAmount: 200_000, Interest: 6, Years: 30, Payment: 1199.10, Taxes: 439.58, Insurance: 176.58, TotalPayment: 1815.26, Assumptions: Defaults},
+		},
+		{
+			"5% down pays PMI",
+			Loan{Price: 400_000, DownPayment: 20_000, AnnualRate: 6.5, Years: 30},
+			Breakdown{Price: 400_000, DownPayment: 20_000, Program: ProgramConventional, LoanAmount: 380_000, Interest: 6.5, Years: 30, Payment: 2401.86, MonthlyPMI: 262.83, Taxes: 703.33, Insurance: 176.58, TotalPayment: 3544.60, Assumptions: Defaults},
+		},
+		{
+			"Local assumptions",
+			Loan{Price: 300_000, DownPayment: 60_000, AnnualRate: 0, Years: 20},
+			Breakdown{Price: 300_000, DownPayment: 60_000, Program: ProgramConventional, LoanAmount: 240_000, Years: 20, Payment: 1000, Taxes: 250, Insurance: 100, TotalPayment: 1350, Assumptions: Assumptions{TaxRate: 0.01, AnnualInsurance: 1200, Scope: ScopeState, Location: Location{State: "CO"}}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Calculate(tt.loan, tt.want.Assumptions); got != tt.want {
+				t.Errorf("Calculate() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAmortize(t *testing.T) {
+	tests := []struct {
+		name        string
+		loan        Loan
+		wantPMI     float64
+		wantDropOff int
+	}{
+		{"20% down has no PMI", Loan{Price: 250_000, DownPayment: 50_000, AnnualRate: 6, Years: 30}, 0, 0},
+		{"5% down pays PMI until 78% LTV", Loan{Price: 400_000, DownPayment: 20_000, AnnualRate: 6.5, Years: 30}, 262.83, 0},
+		{"Zero interest", Loan{Price: 120_000, DownPayment: 24_000, AnnualRate: 0, Years: 10}, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := Amortize(tt.loan)
+
+			if len(s.Months) != tt.loan.Years*12 {
+				t.Fatalf("Amortize() has %d months, want %d", len(s.Months), tt.loan.Years*12)
+			}
+			last := s.Months[len(s.Months)-1]
+			if last.Balance != 0 {
+				t.Errorf("final balance = %v, want 0", last.Balance)
+			}
+
+			var principal float64
+			for _, m := range s.Months {
+				principal += m.Principal
+			}
+			if math.Abs(principal-tt.loan.Principal()) > 0.005 {
+				t.Errorf("principal paid = %.2f, want %.2f", principal, tt.loan.Principal())
+			}
+			if last.CumulativeInterest != s.TotalInterest {
+				t.Errorf("TotalInterest = %v, want the last cumulative %v", s.TotalInterest, last.CumulativeInterest)
+			}
+
+			if s.MonthlyPMI != tt.wantPMI {
+				t.Errorf("MonthlyPMI = %v, want %v", s.MonthlyPMI, tt.wantPMI)
+			}
+			if tt.wantPMI == 0 && (s.PMIDropOffMonth != 0 || s.TotalPMI != 0) {
+				t.Errorf("PMI charged without PMI: drop-off %d, total %v", s.PMIDropOffMonth, s.TotalPMI)
+			}
+			if tt.wantPMI > 0 {
+				drop := s.PMIDropOffMonth
+				if drop == 0 || drop >= len(s.Months) {
+					t.Fatalf("PMIDropOffMonth = %d", drop)
+				}
+				cancelAt := tt.loan.Price * PMICancelLTV
+				if s.Months[drop-1].PMI == 0 || s.Months[drop].PMI != 0 {
+					t.Errorf("PMI doesn't stop after month %d", drop)
+				}
+				if s.Months[drop].Balance > cancelAt || s.Months[drop-2].Balance <= cancelAt {
+					t.Errorf("PMI drop-off at month %d doesn't match %.0f%% LTV", drop, PMICancelLTV*100)
+				}
+				if s.TotalPMI != round(float64(drop)*tt.wantPMI) {
+					t.Errorf("TotalPMI = %v, want %v", s.TotalPMI, round(float64(drop)*tt.wantPMI))
+				}
+			}
+		})
+	}
+}
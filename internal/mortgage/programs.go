@@ -0,0 +1,146 @@
+package mortgage
+
+import "slices"
+
+// Loan programs.
+const (
+	ProgramConventional = "conventional"
+	ProgramFHA          = "fha"
+	ProgramVA           = "va"
+	ProgramUSDA         = "usda"
+)
+
+// Programs lists the loan programs in the order forms show them.
+var Programs = []string{ProgramConventional, ProgramFHA, ProgramVA, ProgramUSDA}
+
+// ValidProgram reports whether p is one of Programs.
+func ValidProgram(p string) bool {
+	return slices.Contains(Programs, p)
+}
+
+// DefaultCreditScore prices conventional PMI when the borrower's score is
+// unknown.
+const DefaultCreditScore = 700
+
+const (
+	// FHAUpfrontMIP is FHA's upfront mortgage insurance premium as a share
+	// of the base loan. It is financed.
+	FHAUpfrontMIP = 0.0175
+	// FHAMIPYears is how long FHA's annual premium lasts with at least
+	// FHAMIPDownPayment down. With less it lasts the life of the loan.
+	FHAMIPYears       = 11
+	FHAMIPDownPayment = 0.1
+
+	// USDA's guarantee fees as shares of the base loan. The upfront fee is
+	// financed and the annual fee lasts the life of the loan.
+	USDAUpfrontFee = 0.01
+	USDAAnnualFee  = 0.0035
+)
+
+// MinCreditScore is the lowest credit score program lends to, zero when
+// the calculator doesn't enforce one.
+func MinCreditScore(program string) int {
+	switch program {
+	case ProgramFHA:
+		return 500
+	case ProgramVA, ProgramUSDA:
+		return 0
+	default:
+		return 620
+	}
+}
+
+// MinDownPayment is the smallest down payment program allows, as a share of
+// the price. FHA asks for more below a 580 credit score; an unknown score
+// of zero gets the lower minimum.
+func MinDownPayment(program string, creditScore int) float64 {
+	switch program {
+	case ProgramFHA:
+		if creditScore != 0 && creditScore < 580 {
+			return 0.1
+		}
+		return 0.035
+	case ProgramVA, ProgramUSDA:
+		return 0
+	default:
+		return 0.03
+	}
+}
+
+// vaFundingFees are VA's funding fee tiers by down payment share, for the
+// first and any later use of the benefit.
+var vaFundingFees = []struct {
+	minDown           float64
+	first, subsequent float64
+}{
+	{0.1, 0.0125, 0.0125},
+	{0.05, 0.015, 0.015},
+	{0, 0.0215, 0.033},
+}
+
+func vaFundingFee(downShare float64, subsequentUse bool) float64 {
+	for _, tier := range vaFundingFees {
+		if downShare >= tier.minDown {
+			if subsequentUse {
+				return tier.subsequent
+			}
+			return tier.first
+		}
+	}
+	return 0
+}
+
+// fhaAnnualMIP is FHA's yearly premium as a share of the base loan.
+func fhaAnnualMIP(ltv float64, years int) float64 {
+	if years > 15 {
+		if ltv > 0.95 {
+			return 0.0055
+		}
+		return 0.005
+	}
+	if ltv > 0.9 {
+		return 0.004
+	}
+	return 0.0015
+}
+
+// pmiCreditBands are the lower bounds of the credit score bands of
+// pmiRates' columns.
+var pmiCreditBands = []int{760, 740, 720, 700, 680, 660, 640, 620}
+
+// pmiRates are conventional PMI's yearly premiums as a share of the loan,
+// by the highest LTV of each tier and the credit band.
+var pmiRates = []struct {
+	maxLTV float64
+	rates  []float64
+}{
+	{0.85, []float64{0.0019, 0.0023, 0.0028, 0.0033, 0.0039, 0.0049, 0.0055, 0.0061}},
+	{0.9, []float64{0.003, 0.0038, 0.0048, 0.0058, 0.007, 0.0089, 0.0101, 0.0112}},
+	{0.95, []float64{0.0041, 0.0054, 0.0068, 0.0083, 0.0101, 0.0131, 0.0149, 0.0165}},
+	{0.97, []float64{0.0055, 0.0075, 0.0095, 0.0115, 0.014, 0.0175, 0.02, 0.0225}},
+}
+
+// conventionalPMI is the yearly PMI premium as a share of the loan, zero at
+// or below PMIThreshold LTV. Scores below the lowest band are priced in it.
+func conventionalPMI(ltv float64, creditScore int) float64 {
+	if ltv <= PMIThreshold {
+		return 0
+	}
+	if creditScore == 0 {
+		creditScore = DefaultCreditScore
+	}
+
+	band := len(pmiCreditBands) - 1
+	for i, min := range pmiCreditBands {
+		if creditScore >= min {
+			band = i
+			break
+		}
+	}
+	for _, tier := range pmiRates {
+		if ltv <= tier.maxLTV {
+			return tier.rates[band]
+		}
+	}
+	return pmiRates[len(pmiRates)-1].rates[band]
+}
@@ -0,0 +1,72 @@
+package mortgage
+
+import "testing"
+
+func TestMinDownPayment(t *testing.T) {
+	tests := []struct {
+		name        string
+		program     string
+		creditScore int
+		want        float64
+	}{
+		{"Conventional", ProgramConventional, 700, 0.03},
+		{"FHA", ProgramFHA, 620, 0.035},
+		{"FHA below 580", ProgramFHA, 560, 0.1},
+		{"FHA with an unknown score", ProgramFHA, 0, 0.035},
+		{"VA", ProgramVA, 0, 0},
+		{"USDA", ProgramUSDA, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MinDownPayment(tt.program, tt.creditScore); got != tt.want {
+				t.Errorf("MinDownPayment() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoanFees(t *testing.T) {
+	tests := []struct {
+		name        string
+		loan        Loan
+		wantUpfront float64
+		wantPMI     float64
+		wantDropOff int
+	}{
+		{"Conventional 10% down, 780 score", Loan{Price: 300_000, DownPayment: 30_000, Program: ProgramConventional, CreditScore: 780}, 0, 67.5, 0},
+		{"Conventional 15% down, 630 score", Loan{Price: 240_000, DownPayment: 36_000, Program: ProgramConventional, CreditScore: 630}, 0, 103.7, 0},
+		{"Conventional 15% down, score below the bands", Loan{Price: 240_000, DownPayment: 36_000, CreditScore: 550}, 0, 103.7, 0},
+		{"Conventional 20% down", Loan{Price: 300_000, DownPayment: 60_000, Program: ProgramConventional}, 0, 0, 0},
+		{"FHA 3.5% down keeps MIP for life", Loan{Price: 300_000, DownPayment: 10_500, Program: ProgramFHA}, 5066.25, 132.69, 360},
+		{"FHA 10% down drops MIP after 11 years", Loan{Price: 300_000, DownPayment: 30_000, Program: ProgramFHA}, 4725, 112.5, 132},
+		{"VA no down payment", Loan{Price: 300_000, Program: ProgramVA}, 6450, 0, 0},
+		{"VA subsequent use", Loan{Price: 300_000, Program: ProgramVA, VASubsequentUse: true}, 9900, 0, 0},
+		{"VA 5% down", Loan{Price: 300_000, DownPayment: 15_000, Program: ProgramVA}, 4275, 0, 0},
+		{"VA 10% down", Loan{Price: 300_000, DownPayment: 30_000, Program: ProgramVA, VASubsequentUse: true}, 3375, 0, 0},
+		{"USDA", Loan{Price: 300_000, Program: ProgramUSDA}, 3000, 87.5, 360},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.loan.AnnualRate = 6.5
+			tt.loan.Years = 30
+
+			if got := tt.loan.UpfrontFee(); got != tt.wantUpfront {
+				t.Errorf("UpfrontFee() = %v, want %v", got, tt.wantUpfront)
+			}
+			if got := tt.loan.Principal(); got != tt.loan.BaseLoan()+tt.wantUpfront {
+				t.Errorf("Principal() = %v, want the fee financed", got)
+			}
+			if got := tt.loan.MonthlyPMI(); got != tt.wantPMI {
+				t.Errorf("MonthlyPMI() = %v, want %v", got, tt.wantPMI)
+			}
+			if tt.loan.program() == ProgramConventional {
+				return
+			}
+			if got := Amortize(tt.loan).PMIDropOffMonth; got != tt.wantDropOff {
+				t.Errorf("PMIDropOffMonth = %v, want %v", got, tt.wantDropOff)
+			}
+		})
+	}
+}
@@ -0,0 +1,49 @@
+// Package notify decides when agents are told about new leads.
+package notify
+
+import (
+	"strings"
+	"time"
+
+	"github.com/DiegoGarciaCo/websitesAPI/internal/timeofday"
+)
+
+// Config controls agent notifications. Recipients and templates are set
+// per lead type in the database; these settings apply to every rule.
+type Config struct {
+	QuietHours       QuietHours
+	DigestTemplateID int64 // Brevo template for the batched email
+}
+
+// QuietHours is a daily window during which notifications are held for a
+// digest instead of being sent right away. The zero value is never active.
+type QuietHours struct {
+	window timeofday.Window
+	loc    *time.Location
+}
+
+// ParseQuietHours parses a "HH:MM-HH:MM" window in loc. A window may wrap
+// past midnight, e.g. "21:00-07:00". An empty spec disables quiet hours.
+func ParseQuietHours(spec string, loc *time.Location) (QuietHours, error) {
+	if strings.TrimSpace(spec) == "" {
+		return QuietHours{}, nil
+	}
+
+	window, err := timeofday.Parse(spec)
+	if err != nil {
+		return QuietHours{}, err
+	}
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	return QuietHours{window: window, loc: loc}, nil
+}
+
+// Active reports whether t falls inside the quiet window.
+func (q QuietHours) Active(t time.Time) bool {
+	if q.loc == nil {
+		return false
+	}
+	return q.window.Contains(t.In(q.loc))
+}
@@ -0,0 +1,73 @@
+package notify
+
+import (
+	"testing"
+	"time"
+)
+
+func TestQuietHoursActive(t *testing.T) {
+	denver, err := time.LoadLocation("America/Denver")
+	if err != nil {
+		t.Skipf("time zone data unavailable: %v", err)
+	}
+
+	overnight, err := ParseQuietHours("21:00-07:00", denver)
+	if err != nil {
+		t.Fatalf("ParseQuietHours() error = %v", err)
+	}
+	lunch, err := ParseQuietHours("12:00-13:30", denver)
+	if err != nil {
+		t.Fatalf("ParseQuietHours() error = %v", err)
+	}
+
+	at := func(hour, minute int) time.Time {
+		return time.Date(2025, 3, 1, hour, minute, 0, 0, denver)
+	}
+
+	tests := []struct {
+		name  string
+		quiet QuietHours
+		t     time.Time
+		want  bool
+	}{
+		{"Overnight before start", overnight, at(20, 59), false},
+		{"Overnight at start", overnight, at(21, 0), true},
+		{"Overnight after midnight", overnight, at(3, 0), true},
+		{"Overnight at end", overnight, at(7, 0), false},
+		{"Daytime window inside", lunch, at(12, 45), true},
+		{"Daytime window after", lunch, at(13, 30), false},
+		{"Other time zone", overnight, time.Date(2025, 3, 1, 5, 0, 0, 0, time.UTC), true}, // 22:00 in Denver
+		{"Disabled", QuietHours{}, at(3, 0), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.quiet.Active(tt.t); got != tt.want {
+				t.Errorf("Active(%v) = %v, want %v", tt.t, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseQuietHours(t *testing.T) {
+	tests := []struct {
+		spec    string
+		wantErr bool
+	}{
+		{"", false},
+		{"21:00-07:00", false},
+		{" 9:30 - 17:00 ", false},
+		{"21:00", true},
+		{"25:00-07:00", true},
+		{"08:00-08:00", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			_, err := ParseQuietHours(tt.spec, time.UTC)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseQuietHours(%q) error = %v, wantErr %v", tt.spec, err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,99 @@
+// Package routing picks the agent a new lead is assigned to.
+package routing
+
+import (
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/DiegoGarciaCo/websitesAPI/internal/timeofday"
+)
+
+const (
+	StrategyFixed      = "fixed"       // always the first agent
+	StrategyRoundRobin = "round_robin" // each agent in turn
+)
+
+// Lead holds the facts about a lead that rules match on. Empty fields and a
+// zero price match only rules that don't filter on them.
+type Lead struct {
+	Type  string
+	Zip   string
+	City  string
+	Price float64
+}
+
+// Rule assigns matching leads to one of Agents. Empty criteria match any
+// lead.
+type Rule struct {
+	LeadTypes   []string
+	Zips        []string
+	Cities      []string
+	MinPrice    *float64
+	MaxPrice    *float64
+	ActiveHours *timeofday.Window // compared against the clock time of at
+	Strategy    string
+	Agents      []string
+}
+
+// Matches reports whether the rule applies to lead submitted at at.
+func (r Rule) Matches(lead Lead, at time.Time) bool {
+	if len(r.Agents) == 0 {
+		return false
+	}
+	if len(r.LeadTypes) > 0 && !slices.Contains(r.LeadTypes, lead.Type) {
+		return false
+	}
+	if len(r.Zips) > 0 && !slices.Contains(r.Zips, NormalizeZip(lead.Zip)) {
+		return false
+	}
+	if len(r.Cities) > 0 && !slices.Contains(r.Cities, NormalizeCity(lead.City)) {
+		return false
+	}
+	if r.MinPrice != nil && (lead.Price <= 0 || lead.Price < *r.MinPrice) {
+		return false
+	}
+	if r.MaxPrice != nil && (lead.Price <= 0 || lead.Price > *r.MaxPrice) {
+		return false
+	}
+	if r.ActiveHours != nil && !r.ActiveHours.Contains(at) {
+		return false
+	}
+	return true
+}
+
+// Agent returns the agent for the n-th lead the rule has assigned.
+func (r Rule) Agent(n int) string {
+	if len(r.Agents) == 0 {
+		return ""
+	}
+	if r.Strategy != StrategyRoundRobin || n < 0 {
+		return r.Agents[0]
+	}
+	return r.Agents[n%len(r.Agents)]
+}
+
+// Match returns the index of the first rule that applies to lead, or -1.
+// Rules must already be in priority order.
+func Match(rules []Rule, lead Lead, at time.Time) int {
+	for i, r := range rules {
+		if r.Matches(lead, at) {
+			return i
+		}
+	}
+	return -1
+}
+
+// NormalizeZip reduces a US ZIP+4 code to its five-digit form.
+func NormalizeZip(zip string) string {
+	zip = strings.TrimSpace(zip)
+	if len(zip) > 5 && zip[5] == '-' {
+		return zip[:5]
+	}
+	return zip
+}
+
+// NormalizeCity lowercases a city name and collapses its whitespace.
+func NormalizeCity(city string) string {
+	return strings.ToLower(strings.Join(strings.Fields(city), " "))
+}
@@ -0,0 +1,79 @@
+package routing
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DiegoGarciaCo/websitesAPI/internal/timeofday"
+)
+
+func TestMatch(t *testing.T) {
+	price := func(p float64) *float64 { return &p }
+	business := &timeofday.Window{Start: 9 * 60, End: 17 * 60}
+	noon := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	night := time.Date(2025, 3, 1, 22, 0, 0, 0, time.UTC)
+
+	rules := []Rule{
+		{LeadTypes: []string{"seller-estimate"}, Zips: []string{"80202"}, Agents: []string{"downtown"}},
+		{LeadTypes: []string{"seller-estimate"}, Cities: []string{"boulder"}, Agents: []string{"boulder"}},
+		{LeadTypes: []string{"mortgage"}, MinPrice: price(1_000_000), Agents: []string{"luxury"}},
+		{ActiveHours: business, Agents: []string{"office"}},
+		{Agents: []string{"fallback"}},
+	}
+
+	tests := []struct {
+		name string
+		lead Lead
+		at   time.Time
+		want string
+	}{
+		{"Zip", Lead{Type: "seller-estimate", Zip: "80202"}, noon, "downtown"},
+		{"ZIP+4", Lead{Type: "seller-estimate", Zip: "80202-1234"}, noon, "downtown"},
+		{"City ignores case and spacing", Lead{Type: "seller-estimate", City: "  Boulder "}, night, "boulder"},
+		{"Price at minimum", Lead{Type: "mortgage", Price: 1_000_000}, night, "luxury"},
+		{"Price below minimum", Lead{Type: "mortgage", Price: 400_000}, night, "fallback"},
+		{"Unknown price skips price rules", Lead{Type: "mortgage"}, night, "fallback"},
+		{"Inside active hours", Lead{Type: "contact"}, noon, "office"},
+		{"Outside active hours", Lead{Type: "contact"}, night, "fallback"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			i := Match(rules, tt.lead, tt.at)
+			if i < 0 {
+				t.Fatalf("Match() = -1, want %q", tt.want)
+			}
+			if got := rules[i].Agent(0); got != tt.want {
+				t.Errorf("Match() agent = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if i := Match(rules[:3], Lead{Type: "contact"}, noon); i != -1 {
+		t.Errorf("Match() = %d, want -1", i)
+	}
+}
+
+func TestRuleAgent(t *testing.T) {
+	pool := []string{"ana", "ben", "cy"}
+
+	tests := []struct {
+		name string
+		rule Rule
+		n    int
+		want string
+	}{
+		{"Fixed ignores position", Rule{Strategy: StrategyFixed, Agents: pool}, 4, "ana"},
+		{"Round robin first", Rule{Strategy: StrategyRoundRobin, Agents: pool}, 0, "ana"},
+		{"Round robin wraps", Rule{Strategy: StrategyRoundRobin, Agents: pool}, 4, "ben"},
+		{"No agents", Rule{Strategy: StrategyRoundRobin}, 1, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.rule.Agent(tt.n); got != tt.want {
+				t.Errorf("Agent(%d) = %q, want %q", tt.n, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,105 @@
+// Package scoring rates how promising a lead is from weighted signals.
+package scoring
+
+import (
+	"slices"
+	"strings"
+)
+
+// Signals a rule can score.
+const (
+	SignalLeadType = "lead_type" // Value is a lead type
+	SignalPrice    = "price"     // Min/Max bound the mortgage price
+	SignalPhone    = "phone"     // a phone number was given
+	SignalRepeat   = "repeat"    // Min/Max bound the number of earlier leads
+	SignalPages    = "pages"     // Min/Max bound the pages visited
+	SignalTimeline = "timeline"  // Value is a seller timeline
+)
+
+var Signals = []string{SignalLeadType, SignalPrice, SignalPhone, SignalRepeat, SignalPages, SignalTimeline}
+
+// Scores are clamped to this range.
+const (
+	MinScore = 0
+	MaxScore = 100
+)
+
+// Facts are what is known about a lead. Zero values mean unknown.
+type Facts struct {
+	Type         string
+	Price        float64
+	HasPhone     bool
+	PriorLeads   int // earlier leads with the same email
+	PagesVisited int
+	Timeline     string
+}
+
+// Rule awards Points to leads whose signal matches. Text signals match
+// Value case-insensitively; numeric signals match Min <= n < Max, with a nil
+// bound left open.
+type Rule struct {
+	Signal string
+	Value  string
+	Min    *float64
+	Max    *float64
+	Points int
+}
+
+func inRange(n float64, min, max *float64) bool {
+	return (min == nil || n >= *min) && (max == nil || n < *max)
+}
+
+// Matches reports whether the rule applies to f.
+func (r Rule) Matches(f Facts) bool {
+	switch r.Signal {
+	case SignalLeadType:
+		return strings.EqualFold(r.Value, f.Type)
+	case SignalPrice:
+		return f.Price > 0 && inRange(f.Price, r.Min, r.Max)
+	case SignalPhone:
+		return f.HasPhone
+	case SignalRepeat:
+		return inRange(float64(f.PriorLeads), r.Min, r.Max)
+	case SignalPages:
+		return f.PagesVisited > 0 && inRange(float64(f.PagesVisited), r.Min, r.Max)
+	case SignalTimeline:
+		return f.Timeline != "" && strings.EqualFold(r.Value, f.Timeline)
+	}
+	return false
+}
+
+// Score adds up the points of every matching rule.
+func Score(rules []Rule, f Facts) int {
+	score := 0
+	for _, r := range rules {
+		if r.Matches(f) {
+			score += r.Points
+		}
+	}
+	return min(max(score, MinScore), MaxScore)
+}
+
+// TagPrefix starts every tag Tag returns.
+const TagPrefix = "Score: "
+
+// Tag is the CRM tag for a score.
+func Tag(score int) string {
+	switch {
+	case score >= 70:
+		return TagPrefix + "Hot"
+	case score >= 40:
+		return TagPrefix + "Warm"
+	default:
+		return TagPrefix + "Cold"
+	}
+}
+
+// ValidSignal reports whether s is a known signal.
+func ValidSignal(s string) bool {
+	return slices.Contains(Signals, s)
+}
+
+// Numeric reports whether a signal is matched by range rather than value.
+func Numeric(signal string) bool {
+	return signal == SignalPrice || signal == SignalRepeat || signal == SignalPages
+}
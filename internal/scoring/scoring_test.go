@@ -0,0 +1,60 @@
+package scoring
+
+import "testing"
+
+func TestScore(t *testing.T) {
+	f := func(v float64) *float64 { return &v }
+	rules := []Rule{
+		{Signal: SignalLeadType, Value: "seller-estimate", Points: 30},
+		{Signal: SignalLeadType, Value: "mortgage", Points: 10},
+		{Signal: SignalPrice, Min: f(500_000), Max: f(1_000_000), Points: 15},
+		{Signal: SignalPrice, Min: f(1_000_000), Points: 25},
+		{Signal: SignalPhone, Points: 10},
+		{Signal: SignalRepeat, Min: f(1), Points: 20},
+		{Signal: SignalPages, Min: f(5), Points: 5},
+		{Signal: SignalTimeline, Value: "0-3 months", Points: 40},
+		{Signal: SignalTimeline, Value: "just curious", Points: -20},
+	}
+
+	tests := []struct {
+		name  string
+		facts Facts
+		want  int
+	}{
+		{"Nothing matches", Facts{Type: "contact"}, 0},
+		{"Type and phone", Facts{Type: "mortgage", HasPhone: true}, 20},
+		{"Price band lower bound", Facts{Type: "mortgage", Price: 500_000}, 25},
+		{"Price band upper bound is exclusive", Facts{Type: "mortgage", Price: 1_000_000}, 35},
+		{"Repeat submission", Facts{Type: "contact", PriorLeads: 2}, 20},
+		{"Pages visited", Facts{Type: "contact", PagesVisited: 5}, 5},
+		{"Timeline ignores case", Facts{Type: "seller-estimate", Timeline: "0-3 Months"}, 70},
+		{"Negative points clamp at zero", Facts{Type: "contact", Timeline: "just curious"}, 0},
+		{"Clamped at maximum", Facts{Type: "seller-estimate", Timeline: "0-3 months", HasPhone: true, PriorLeads: 1, PagesVisited: 9}, 100},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Score(rules, tt.facts); got != tt.want {
+				t.Errorf("Score() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTag(t *testing.T) {
+	tests := []struct {
+		score int
+		want  string
+	}{
+		{0, "Score: Cold"},
+		{39, "Score: Cold"},
+		{40, "Score: Warm"},
+		{70, "Score: Hot"},
+	}
+
+	for _, tt := range tests {
+		if got := Tag(tt.score); got != tt.want {
+			t.Errorf("Tag(%d) = %q, want %q", tt.score, got, tt.want)
+		}
+	}
+}
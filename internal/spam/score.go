@@ -0,0 +1,105 @@
+package spam
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// Heuristic weights. A submission whose total reaches the configured
+// threshold is treated as spam.
+const (
+	linkWeight       = 2
+	markupLinkWeight = 3
+	keywordWeight    = 2
+	foreignWeight    = 2
+	shoutingWeight   = 1
+	badNameWeight    = 2
+	maxLinkScore     = 6
+)
+
+var (
+	linkPattern       = regexp.MustCompile(`(?i)https?://|www\.`)
+	markupLinkPattern = regexp.MustCompile(`(?i)<a\s|\[url=|\[link=`)
+)
+
+var spamKeywords = []string{
+	"backlink",
+	"bitcoin",
+	"casino",
+	"crypto",
+	"forex",
+	"guest post",
+	"loan offer",
+	"rank your website",
+	"search engine optimization",
+	"seo services",
+	"viagra",
+	"web design services",
+}
+
+// ScoreContent rates free-text fields and names. Higher is spammier.
+func ScoreContent(firstName, lastName string, texts []string) (int, []string) {
+	score := 0
+	var reasons []string
+
+	joined := strings.Join(texts, "\n")
+	lower := strings.ToLower(joined)
+
+	if n := len(linkPattern.FindAllString(joined, -1)); n > 0 {
+		score += min(n*linkWeight, maxLinkScore)
+		reasons = append(reasons, "links")
+	}
+	if markupLinkPattern.MatchString(joined) {
+		score += markupLinkWeight
+		reasons = append(reasons, "markup links")
+	}
+	for _, kw := range spamKeywords {
+		if strings.Contains(lower, kw) {
+			score += keywordWeight
+			reasons = append(reasons, "keyword: "+kw)
+		}
+	}
+	if hasForeignScript(joined) {
+		score += foreignWeight
+		reasons = append(reasons, "foreign script")
+	}
+	if isShouting(joined) {
+		score += shoutingWeight
+		reasons = append(reasons, "all caps")
+	}
+
+	for _, name := range []string{firstName, lastName} {
+		if strings.ContainsAny(name, "0123456789/@") || linkPattern.MatchString(name) {
+			score += badNameWeight
+			reasons = append(reasons, "suspicious name")
+			break
+		}
+	}
+
+	return score, reasons
+}
+
+// hasForeignScript reports Cyrillic or Han characters, which our market
+// never uses in inquiries but link spam often does.
+func hasForeignScript(s string) bool {
+	for _, r := range s {
+		if unicode.Is(unicode.Cyrillic, r) || unicode.Is(unicode.Han, r) {
+			return true
+		}
+	}
+	return false
+}
+
+func isShouting(s string) bool {
+	letters, upper := 0, 0
+	for _, r := range s {
+		if unicode.IsLetter(r) {
+			letters++
+			if unicode.IsUpper(r) {
+				upper++
+			}
+		}
+	}
+	return letters >= 20 && upper*10 >= letters*7
+}
@@ -0,0 +1,151 @@
+// Package spam detects bot and junk submissions on public forms.
+package spam
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+type Config struct {
+	Secret          string        // signs form tokens
+	HoneypotField   string        // hidden field real visitors leave empty
+	TokenField      string        // field carrying the form token
+	RequireToken    bool          // reject submissions without a form token
+	MinSubmitTime   time.Duration // minimum time between rendering and submitting
+	MaxTokenAge     time.Duration
+	ScoreThreshold  int // content score at which a submission is rejected
+	BlockDisposable bool
+}
+
+// Verdict explains why a submission was rejected.
+type Verdict struct {
+	Rejected bool
+	Reason   string
+	Score    int
+	// Silent is true for bot signals, where the caller should pretend the
+	// submission succeeded rather than tell the sender what tripped.
+	Silent bool
+}
+
+// Reason codes
+const (
+	ReasonHoneypot   = "honeypot"
+	ReasonToken      = "form token"
+	ReasonDisposable = "disposable email"
+	ReasonContent    = "content score"
+)
+
+type Checker struct {
+	cfg Config
+}
+
+func NewChecker(cfg Config) *Checker {
+	if cfg.HoneypotField == "" {
+		cfg.HoneypotField = "website"
+	}
+	if cfg.TokenField == "" {
+		cfg.TokenField = "formToken"
+	}
+	return &Checker{cfg: cfg}
+}
+
+// IssueToken returns a new form token for a visitor about to fill a form.
+func (c *Checker) IssueToken(now time.Time) string {
+	return IssueFormToken(c.cfg.Secret, now)
+}
+
+// Check inspects a decoded JSON form submission. Nested objects such as the
+// details of a /api/leads payload are searched as well.
+func (c *Checker) Check(fields map[string]any, now time.Time) Verdict {
+	if v, ok := lookupString(fields, c.cfg.HoneypotField); ok && strings.TrimSpace(v) != "" {
+		return Verdict{Rejected: true, Reason: ReasonHoneypot, Silent: true}
+	}
+
+	token, ok := lookupString(fields, c.cfg.TokenField)
+	if ok && token != "" {
+		err := CheckFormToken(token, c.cfg.Secret, now, c.cfg.MinSubmitTime, c.cfg.MaxTokenAge)
+		if err != nil {
+			return Verdict{Rejected: true, Reason: fmt.Sprintf("%s: %s", ReasonToken, err), Silent: !errors.Is(err, ErrTokenExpired)}
+		}
+	} else if c.cfg.RequireToken {
+		return Verdict{Rejected: true, Reason: ReasonToken + ": missing", Silent: true}
+	}
+
+	if c.cfg.BlockDisposable {
+		if email, ok := lookupString(fields, "email"); ok && IsDisposableEmail(email) {
+			return Verdict{Rejected: true, Reason: ReasonDisposable}
+		}
+	}
+
+	firstName, _ := lookupString(fields, "firstName")
+	lastName, _ := lookupString(fields, "lastName")
+	if name, ok := lookupString(fields, "name"); ok && firstName == "" {
+		firstName = name
+	}
+	score, reasons := ScoreContent(firstName, lastName, c.texts(fields))
+	if c.cfg.ScoreThreshold > 0 && score >= c.cfg.ScoreThreshold {
+		return Verdict{
+			Rejected: true,
+			Reason:   ReasonContent + ": " + strings.Join(reasons, ", "),
+			Score:    score,
+			Silent:   true,
+		}
+	}
+
+	return Verdict{Score: score}
+}
+
+// texts collects the free-text values worth scoring.
+func (c *Checker) texts(fields map[string]any) []string {
+	skip := map[string]bool{
+		c.cfg.TokenField: true,
+		"email":          true,
+		"phone":          true,
+		"number":         true,
+		"firstName":      true,
+		"lastName":       true,
+		"name":           true,
+		// Attribution sent by the frontend, not typed by the visitor
+		"utm_source":   true,
+		"utm_medium":   true,
+		"utm_campaign": true,
+		"gclid":        true,
+		"fbclid":       true,
+		"referrer":     true,
+		"landingPage":  true,
+	}
+
+	var out []string
+	var walk func(m map[string]any)
+	walk = func(m map[string]any) {
+		for k, v := range m {
+			switch v := v.(type) {
+			case string:
+				if !skip[k] {
+					out = append(out, v)
+				}
+			case map[string]any:
+				walk(v)
+			}
+		}
+	}
+	walk(fields)
+	return out
+}
+
+// lookupString finds a string field at the top level or one level down.
+func lookupString(fields map[string]any, key string) (string, bool) {
+	if v, ok := fields[key].(string); ok {
+		return v, true
+	}
+	for _, v := range fields {
+		if nested, ok := v.(map[string]any); ok {
+			if s, ok := nested[key].(string); ok {
+				return s, true
+			}
+		}
+	}
+	return "", false
+}
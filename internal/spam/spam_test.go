@@ -0,0 +1,148 @@
+package spam
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCheckFormToken(t *testing.T) {
+	issued := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	token := IssueFormToken("secret", issued)
+
+	tests := []struct {
+		name    string
+		token   string
+		secret  string
+		now     time.Time
+		wantErr error
+	}{
+		{
+			name:    "Valid token",
+			token:   token,
+			secret:  "secret",
+			now:     issued.Add(10 * time.Second),
+			wantErr: nil,
+		},
+		{
+			name:    "Submitted too fast",
+			token:   token,
+			secret:  "secret",
+			now:     issued.Add(time.Second),
+			wantErr: ErrTooFast,
+		},
+		{
+			name:    "Expired token",
+			token:   token,
+			secret:  "secret",
+			now:     issued.Add(48 * time.Hour),
+			wantErr: ErrTokenExpired,
+		},
+		{
+			name:    "Wrong secret",
+			token:   token,
+			secret:  "wrong_secret",
+			now:     issued.Add(10 * time.Second),
+			wantErr: ErrInvalidToken,
+		},
+		{
+			name:    "Malformed token",
+			token:   "not-a-token",
+			secret:  "secret",
+			now:     issued.Add(10 * time.Second),
+			wantErr: ErrInvalidToken,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckFormToken(tt.token, tt.secret, tt.now, 3*time.Second, 24*time.Hour)
+			if err != tt.wantErr {
+				t.Errorf("CheckFormToken() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestIsDisposableEmail(t *testing.T) {
+	tests := []struct {
+		email string
+		want  bool
+	}{
+		{"jane@gmail.com", false},
+		{"bot@mailinator.com", true},
+		{"bot@eu.mailinator.com", true},
+		{"BOT@YOPMAIL.COM", true},
+		{"not-an-email", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.email, func(t *testing.T) {
+			if got := IsDisposableEmail(tt.email); got != tt.want {
+				t.Errorf("IsDisposableEmail(%q) = %v, want %v", tt.email, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckerCheck(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	checker := NewChecker(Config{
+		Secret:          "secret",
+		MinSubmitTime:   3 * time.Second,
+		MaxTokenAge:     24 * time.Hour,
+		ScoreThreshold:  5,
+		BlockDisposable: true,
+	})
+
+	tests := []struct {
+		name       string
+		fields     map[string]any
+		wantReject bool
+	}{
+		{
+			name:       "Clean submission",
+			fields:     map[string]any{"firstName": "Jane", "email": "jane@gmail.com", "message": "Can we tour 123 Main St?"},
+			wantReject: false,
+		},
+		{
+			name: "Attribution URLs are not scored",
+			fields: map[string]any{
+				"firstName":   "Jane",
+				"message":     "Can we tour 123 Main St?",
+				"referrer":    "https://www.google.com/",
+				"landingPage": "https://soldbyghost.com/homes?utm_source=google&gclid=abc",
+				"utm_source":  "google",
+			},
+			wantReject: false,
+		},
+		{
+			name:       "Honeypot filled",
+			fields:     map[string]any{"firstName": "Jane", "website": "http://spam.example"},
+			wantReject: true,
+		},
+		{
+			name:       "Token too fresh",
+			fields:     map[string]any{"firstName": "Jane", "formToken": IssueFormToken("secret", now)},
+			wantReject: true,
+		},
+		{
+			name:       "Disposable email in details",
+			fields:     map[string]any{"firstName": "Jane", "details": map[string]any{"email": "x@yopmail.com"}},
+			wantReject: true,
+		},
+		{
+			name:       "Link spam",
+			fields:     map[string]any{"firstName": "Jane", "message": "Best SEO services https://a.example https://b.example casino"},
+			wantReject: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := checker.Check(tt.fields, now)
+			if got.Rejected != tt.wantReject {
+				t.Errorf("Check() rejected = %v (%s), want %v", got.Rejected, got.Reason, tt.wantReject)
+			}
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package spam
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidToken = errors.New("invalid form token")
+	ErrTooFast      = errors.New("form submitted too quickly")
+	ErrTokenExpired = errors.New("form token expired")
+)
+
+// IssueFormToken returns a token recording when the form was rendered. It is
+// "<unix seconds>.<hex HMAC-SHA256 of the timestamp>".
+func IssueFormToken(secret string, now time.Time) string {
+	ts := strconv.FormatInt(now.Unix(), 10)
+	return ts + "." + signToken(secret, ts)
+}
+
+// CheckFormToken verifies a token from IssueFormToken and that at least
+// minAge and at most maxAge has passed since it was issued.
+func CheckFormToken(token, secret string, now time.Time, minAge, maxAge time.Duration) error {
+	ts, sig, ok := strings.Cut(token, ".")
+	if !ok {
+		return ErrInvalidToken
+	}
+	if !hmac.Equal([]byte(sig), []byte(signToken(secret, ts))) {
+		return ErrInvalidToken
+	}
+
+	unix, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return ErrInvalidToken
+	}
+
+	age := now.Sub(time.Unix(unix, 0))
+	if age < minAge {
+		return ErrTooFast
+	}
+	if maxAge > 0 && age > maxAge {
+		return ErrTokenExpired
+	}
+	return nil
+}
+
+func signToken(secret, ts string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte("form-token:" + ts))
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,56 @@
+// Package timeofday handles daily clock windows such as "09:00-17:00".
+package timeofday
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Window is a daily span of clock time. End may be before Start for a
+// window that wraps past midnight, e.g. 21:00-07:00.
+type Window struct {
+	Start, End int // minutes after midnight
+}
+
+// Parse parses a "HH:MM-HH:MM" window.
+func Parse(spec string) (Window, error) {
+	from, to, ok := strings.Cut(strings.TrimSpace(spec), "-")
+	if !ok {
+		return Window{}, fmt.Errorf("invalid window %q: want HH:MM-HH:MM", spec)
+	}
+	start, err := parseClock(from)
+	if err != nil {
+		return Window{}, fmt.Errorf("invalid window %q: %w", spec, err)
+	}
+	end, err := parseClock(to)
+	if err != nil {
+		return Window{}, fmt.Errorf("invalid window %q: %w", spec, err)
+	}
+	if start == end {
+		return Window{}, fmt.Errorf("invalid window %q: start and end are equal", spec)
+	}
+	return Window{Start: start, End: end}, nil
+}
+
+// Contains reports whether the clock time of t falls inside the window.
+// Convert t to the intended location first.
+func (w Window) Contains(t time.Time) bool {
+	minute := t.Hour()*60 + t.Minute()
+	if w.Start < w.End {
+		return minute >= w.Start && minute < w.End
+	}
+	return minute >= w.Start || minute < w.End
+}
+
+func (w Window) String() string {
+	return fmt.Sprintf("%02d:%02d-%02d:%02d", w.Start/60, w.Start%60, w.End/60, w.End%60)
+}
+
+func parseClock(s string) (int, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(s))
+	if err != nil {
+		return 0, err
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
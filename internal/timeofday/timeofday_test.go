@@ -0,0 +1,30 @@
+package timeofday
+
+import "testing"
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    string
+		want    Window
+		wantErr bool
+	}{
+		{"Daytime", "09:00-17:30", Window{Start: 540, End: 1050}, false},
+		{"Overnight", " 21:00 - 07:00 ", Window{Start: 1260, End: 420}, false},
+		{"Missing end", "09:00", Window{}, true},
+		{"Bad clock", "9am-5pm", Window{}, true},
+		{"Empty window", "08:00-08:00", Window{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse(%q) error = %v, wantErr %v", tt.spec, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Parse(%q) = %+v, want %+v", tt.spec, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package validation
+
+import "strings"
+
+// Name is a person's name split into parts.
+type Name struct {
+	First  string
+	Middle string
+	Last   string
+	Suffix string
+}
+
+var nameSuffixes = map[string]bool{
+	"jr": true, "sr": true, "ii": true, "iii": true, "iv": true, "v": true,
+	"md": true, "phd": true, "dds": true, "esq": true, "cpa": true,
+}
+
+// ParseName splits a full name as typed into a single form field. It
+// handles single names, middle names, trailing suffixes such as "Jr." and
+// "Last, First" ordering.
+func ParseName(full string) Name {
+	full = strings.Join(strings.Fields(full), " ")
+	if full == "" {
+		return Name{}
+	}
+
+	// "Smith, John" or "Smith, John Jr."
+	if last, rest, ok := strings.Cut(full, ","); ok {
+		rest = strings.TrimSpace(rest)
+		if rest != "" && !isSuffix(rest) {
+			n := ParseName(rest)
+			if n.Last != "" {
+				n.Middle = strings.TrimSpace(n.Middle + " " + n.Last)
+			}
+			n.Last = strings.TrimSpace(last)
+			return n
+		}
+		full = strings.TrimSpace(last + " " + rest)
+	}
+
+	parts := strings.Fields(full)
+
+	var suffix []string
+	for len(parts) > 1 && isSuffix(parts[len(parts)-1]) {
+		suffix = append([]string{parts[len(parts)-1]}, suffix...)
+		parts = parts[:len(parts)-1]
+	}
+
+	n := Name{First: parts[0], Suffix: strings.Join(suffix, " ")}
+	if len(parts) > 1 {
+		n.Last = parts[len(parts)-1]
+		n.Middle = strings.Join(parts[1:len(parts)-1], " ")
+	}
+	return n
+}
+
+// LastWithSuffix returns the last name followed by any suffix, which is how
+// CRMs without a suffix field expect it.
+func (n Name) LastWithSuffix() string {
+	return strings.TrimSpace(n.Last + " " + n.Suffix)
+}
+
+func isSuffix(s string) bool {
+	return nameSuffixes[strings.ToLower(strings.Trim(s, ".,"))]
+}
@@ -0,0 +1,113 @@
+package validation
+
+import (
+	"errors"
+	"strings"
+)
+
+var ErrInvalidPhone = errors.New("invalid phone number")
+
+type region struct {
+	code      string // country calling code
+	minLen    int    // national significant number length
+	maxLen    int
+	trunkZero bool // national format starts with a 0 that is dropped in E.164
+	nanp      bool
+}
+
+var regions = map[string]region{
+	"US": {code: "1", minLen: 10, maxLen: 10, nanp: true},
+	"CA": {code: "1", minLen: 10, maxLen: 10, nanp: true},
+	"MX": {code: "52", minLen: 10, maxLen: 10},
+	"GB": {code: "44", minLen: 9, maxLen: 10, trunkZero: true},
+	"DE": {code: "49", minLen: 6, maxLen: 13, trunkZero: true},
+	"FR": {code: "33", minLen: 9, maxLen: 9, trunkZero: true},
+	"ES": {code: "34", minLen: 9, maxLen: 9},
+	"AU": {code: "61", minLen: 9, maxLen: 9, trunkZero: true},
+	"IN": {code: "91", minLen: 10, maxLen: 10, trunkZero: true},
+}
+
+// SupportedRegion reports whether Phone can normalize national numbers for
+// the ISO 3166 region code.
+func SupportedRegion(code string) bool {
+	_, ok := regions[strings.ToUpper(code)]
+	return ok
+}
+
+// Phone normalizes a phone number to E.164. Numbers written with a leading
+// "+" or "00" are treated as international; anything else is read as a
+// national number in defaultRegion.
+func Phone(raw, defaultRegion string) (string, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return "", ErrInvalidPhone
+	}
+
+	// Drop extensions such as "x123" or "ext. 123"
+	if i := strings.Index(strings.ToLower(raw), "ext"); i > 0 {
+		raw = raw[:i]
+	} else if i := strings.IndexAny(strings.ToLower(raw), "x#"); i > 0 {
+		raw = raw[:i]
+	}
+
+	international := strings.HasPrefix(raw, "+")
+	var digits strings.Builder
+	for _, r := range raw {
+		switch {
+		case r >= '0' && r <= '9':
+			digits.WriteRune(r)
+		case strings.ContainsRune(" ()-./+", r):
+		default:
+			return "", ErrInvalidPhone
+		}
+	}
+	number := digits.String()
+
+	if !international && strings.HasPrefix(number, "00") {
+		international = true
+		number = number[2:]
+	}
+
+	if international {
+		if len(number) < 8 || len(number) > 15 || number[0] == '0' {
+			return "", ErrInvalidPhone
+		}
+		if strings.HasPrefix(number, "1") && !validNANP(number[1:]) {
+			return "", ErrInvalidPhone
+		}
+		return "+" + number, nil
+	}
+
+	reg, ok := regions[strings.ToUpper(defaultRegion)]
+	if !ok {
+		return "", ErrInvalidPhone
+	}
+
+	if reg.nanp {
+		if len(number) == 11 && number[0] == '1' {
+			number = number[1:]
+		}
+		if !validNANP(number) {
+			return "", ErrInvalidPhone
+		}
+		return "+1" + number, nil
+	}
+
+	if strings.HasPrefix(number, reg.code) && len(number)-len(reg.code) >= reg.minLen && len(number)-len(reg.code) <= reg.maxLen {
+		number = number[len(reg.code):]
+	}
+	if reg.trunkZero {
+		number = strings.TrimPrefix(number, "0")
+	}
+	if len(number) < reg.minLen || len(number) > reg.maxLen || number[0] == '0' {
+		return "", ErrInvalidPhone
+	}
+	return "+" + reg.code + number, nil
+}
+
+// validNANP checks a 10 digit North American number: neither the area code
+// nor the exchange may start with 0 or 1.
+func validNANP(number string) bool {
+	return len(number) == 10 &&
+		number[0] >= '2' && number[3] >= '2'
+}
@@ -0,0 +1,77 @@
+// Package validation checks and normalizes user-submitted contact details.
+package validation
+
+import (
+	"errors"
+	"net/mail"
+	"sort"
+	"strings"
+)
+
+// Errors maps a field name to a message describing what is wrong with it.
+type Errors map[string]string
+
+// Add records msg for field unless the field already has an error.
+func (e Errors) Add(field, msg string) {
+	if _, ok := e[field]; !ok {
+		e[field] = msg
+	}
+}
+
+// Err returns e as an error, or nil when there are no field errors.
+func (e Errors) Err() error {
+	if len(e) == 0 {
+		return nil
+	}
+	return e
+}
+
+func (e Errors) Error() string {
+	fields := make([]string, 0, len(e))
+	for field := range e {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	parts := make([]string, len(fields))
+	for i, field := range fields {
+		parts[i] = field + ": " + e[field]
+	}
+	return "validation failed: " + strings.Join(parts, "; ")
+}
+
+// Rename returns a copy of e with field names replaced according to names.
+// Fields missing from names keep their name.
+func (e Errors) Rename(names map[string]string) Errors {
+	out := Errors{}
+	for field, msg := range e {
+		if renamed, ok := names[field]; ok {
+			field = renamed
+		}
+		out.Add(field, msg)
+	}
+	return out
+}
+
+var ErrInvalidEmail = errors.New("invalid email address")
+
+// Email checks the syntax of a bare email address and returns it trimmed
+// with the domain lowercased.
+func Email(email string) (string, error) {
+	email = strings.TrimSpace(email)
+	if email == "" || len(email) > 254 {
+		return "", ErrInvalidEmail
+	}
+
+	addr, err := mail.ParseAddress(email)
+	if err != nil || addr.Address != email || addr.Name != "" {
+		return "", ErrInvalidEmail
+	}
+
+	local, domain, ok := strings.Cut(email, "@")
+	if !ok || local == "" || !strings.Contains(domain, ".") || strings.HasPrefix(domain, ".") || strings.HasSuffix(domain, ".") {
+		return "", ErrInvalidEmail
+	}
+
+	return local + "@" + strings.ToLower(domain), nil
+}
@@ -0,0 +1,96 @@
+package validation
+
+import "testing"
+
+func TestEmail(t *testing.T) {
+	tests := []struct {
+		email   string
+		want    string
+		wantErr bool
+	}{
+		{"jane@example.com", "jane@example.com", false},
+		{"  Jane.Doe@Example.COM ", "Jane.Doe@example.com", false},
+		{"jane+homes@example.co.uk", "jane+homes@example.co.uk", false},
+		{"", "", true},
+		{"jane", "", true},
+		{"jane@localhost", "", true},
+		{"Jane <jane@example.com>", "", true},
+		{"jane@@example.com", "", true},
+		{"jane@example.", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.email, func(t *testing.T) {
+			got, err := Email(tt.email)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Email(%q) error = %v, wantErr %v", tt.email, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Email(%q) = %q, want %q", tt.email, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseName(t *testing.T) {
+	tests := []struct {
+		name string
+		want Name
+	}{
+		{"", Name{}},
+		{"Cher", Name{First: "Cher"}},
+		{"Jane Doe", Name{First: "Jane", Last: "Doe"}},
+		{"  Jane   Doe  ", Name{First: "Jane", Last: "Doe"}},
+		{"Mary Ann Smith", Name{First: "Mary", Middle: "Ann", Last: "Smith"}},
+		{"John Smith Jr.", Name{First: "John", Last: "Smith", Suffix: "Jr."}},
+		{"John Paul Smith III", Name{First: "John", Middle: "Paul", Last: "Smith", Suffix: "III"}},
+		{"Smith, John", Name{First: "John", Last: "Smith"}},
+		{"Smith, John Paul", Name{First: "John", Middle: "Paul", Last: "Smith"}},
+		{"John Smith, Jr.", Name{First: "John", Last: "Smith", Suffix: "Jr."}},
+		{"Jr", Name{First: "Jr"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ParseName(tt.name); got != tt.want {
+				t.Errorf("ParseName(%q) = %+v, want %+v", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPhone(t *testing.T) {
+	tests := []struct {
+		raw     string
+		region  string
+		want    string
+		wantErr bool
+	}{
+		{"(303) 555-0123", "US", "+13035550123", false},
+		{"303.555.0123", "US", "+13035550123", false},
+		{"1-303-555-0123", "US", "+13035550123", false},
+		{"+1 303 555 0123", "MX", "+13035550123", false},
+		{"303-555-0123 ext. 45", "US", "+13035550123", false},
+		{"+44 7911 123456", "US", "+447911123456", false},
+		{"0044 7911 123456", "US", "+447911123456", false},
+		{"07911 123456", "GB", "+447911123456", false},
+		{"55 1234 5678", "MX", "+525512345678", false},
+		{"555-0123", "US", "", true},
+		{"(103) 555-0123", "US", "", true},
+		{"303-555-0123", "ZZ", "", true},
+		{"call me", "US", "", true},
+		{"", "US", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			got, err := Phone(tt.raw, tt.region)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Phone(%q, %q) error = %v, wantErr %v", tt.raw, tt.region, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Phone(%q, %q) = %q, want %q", tt.raw, tt.region, got, tt.want)
+			}
+		})
+	}
+}
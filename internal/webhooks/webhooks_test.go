@@ -0,0 +1,85 @@
+package webhooks
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestVerify(t *testing.T) {
+	sent := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	body := []byte(`{"event":"lead.created"}`)
+
+	signed := http.Header{}
+	SetHeaders(signed, "secret", sent, body)
+
+	tampered := signed.Clone()
+	tampered.Set(TimestampHeader, "1")
+
+	tests := []struct {
+		name    string
+		header  http.Header
+		secret  string
+		body    []byte
+		now     time.Time
+		wantErr error
+	}{
+		{
+			name:    "Valid signature",
+			header:  signed,
+			secret:  "secret",
+			body:    body,
+			now:     sent.Add(time.Minute),
+			wantErr: nil,
+		},
+		{
+			name:    "Wrong secret",
+			header:  signed,
+			secret:  "wrong_secret",
+			body:    body,
+			now:     sent.Add(time.Minute),
+			wantErr: ErrInvalidSignature,
+		},
+		{
+			name:    "Modified body",
+			header:  signed,
+			secret:  "secret",
+			body:    []byte(`{"event":"post.deleted"}`),
+			now:     sent.Add(time.Minute),
+			wantErr: ErrInvalidSignature,
+		},
+		{
+			name:    "Modified timestamp",
+			header:  tampered,
+			secret:  "secret",
+			body:    body,
+			now:     sent.Add(time.Minute),
+			wantErr: ErrInvalidSignature,
+		},
+		{
+			name:    "Too old",
+			header:  signed,
+			secret:  "secret",
+			body:    body,
+			now:     sent.Add(time.Hour),
+			wantErr: ErrExpired,
+		},
+		{
+			name:    "Missing headers",
+			header:  http.Header{},
+			secret:  "secret",
+			body:    body,
+			now:     sent,
+			wantErr: ErrInvalidSignature,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Verify(tt.header, tt.secret, tt.body, tt.now, 5*time.Minute)
+			if err != tt.wantErr {
+				t.Errorf("Verify() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -6,10 +6,16 @@ import (
 	"log"
 	"net/http"
 	"os"
+	"strconv"
 	"time"
+	_ "time/tzdata" // the runtime image has no zoneinfo
 
 	"github.com/DiegoGarciaCo/websitesAPI/internal/database"
 	"github.com/DiegoGarciaCo/websitesAPI/internal/handlers"
+	"github.com/DiegoGarciaCo/websitesAPI/internal/mortgage"
+	"github.com/DiegoGarciaCo/websitesAPI/internal/notify"
+	"github.com/DiegoGarciaCo/websitesAPI/internal/spam"
+	"github.com/DiegoGarciaCo/websitesAPI/internal/validation"
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/rs/cors"
@@ -33,22 +39,44 @@ func main() {
 	if secret == "" {
 		log.Fatal("TOKEN_SECRET is not set")
 	}
+	// Dedicated keys for the privacy request log, so rotating TOKEN_SECRET
+	// doesn't break the chain or change subject hashes
+	auditLogKey := os.Getenv("AUDIT_LOG_KEY")
+	if auditLogKey == "" {
+		log.Fatal("AUDIT_LOG_KEY is not set")
+	}
+	subjectHashKey := os.Getenv("PRIVACY_HASH_KEY")
+	if subjectHashKey == "" {
+		log.Fatal("PRIVACY_HASH_KEY is not set")
+	}
 	appPassword := os.Getenv("APP_PASSWORD")
 	if appPassword == "" {
 		log.Fatal("APP_PASSWORD is not set")
 	}
+	crmProvider := os.Getenv("CRM_PROVIDER")
+	if crmProvider == "" {
+		crmProvider = "fub"
+	}
 	FUBKey := os.Getenv("FUB_API_KEY")
-	if FUBKey == "" {
+	if FUBKey == "" && crmProvider == "fub" {
 		log.Fatal("FUB_API_KEY is not set")
 	}
 	system := os.Getenv("X_SYSTEM")
-	if system == "" {
+	if system == "" && crmProvider == "fub" {
 		log.Fatal("X_SYSTEM is not set")
 	}
 	systemKey := os.Getenv("X_SYSTEM_KEY")
-	if systemKey == "" {
+	if systemKey == "" && crmProvider == "fub" {
 		log.Fatal("X_SYSTEM_KEY is not set")
 	}
+	crmWebhookURL := os.Getenv("CRM_WEBHOOK_URL")
+	if crmWebhookURL == "" && crmProvider == "webhook" {
+		log.Fatal("CRM_WEBHOOK_URL is not set")
+	}
+	crmWebhookSecret := os.Getenv("CRM_WEBHOOK_SECRET")
+	if crmWebhookSecret == "" && crmProvider == "webhook" {
+		log.Fatal("CRM_WEBHOOK_SECRET is not set")
+	}
 
 	s3Region := os.Getenv("S3_REGION")
 	if s3Region == "" {
@@ -63,6 +91,120 @@ func main() {
 		log.Fatal("BREVO_API_KEY is not set")
 	}
 
+	consentVersion := os.Getenv("CONSENT_TEXT_VERSION")
+	if consentVersion == "" {
+		consentVersion = "1"
+	}
+
+	phoneRegion := os.Getenv("DEFAULT_PHONE_REGION")
+	if phoneRegion == "" {
+		phoneRegion = "US"
+	}
+	if !validation.SupportedRegion(phoneRegion) {
+		log.Fatalf("Unsupported DEFAULT_PHONE_REGION: %s", phoneRegion)
+	}
+
+	spamConfig := spam.Config{
+		Secret:          secret,
+		HoneypotField:   os.Getenv("SPAM_HONEYPOT_FIELD"),
+		RequireToken:    os.Getenv("SPAM_REQUIRE_FORM_TOKEN") == "true",
+		MinSubmitTime:   3 * time.Second,
+		MaxTokenAge:     24 * time.Hour,
+		ScoreThreshold:  5,
+		BlockDisposable: true,
+	}
+	if v := os.Getenv("SPAM_MIN_SUBMIT_SECONDS"); v != "" {
+		seconds, err := strconv.Atoi(v)
+		if err != nil {
+			log.Fatal("SPAM_MIN_SUBMIT_SECONDS must be a number")
+		}
+		spamConfig.MinSubmitTime = time.Duration(seconds) * time.Second
+	}
+	if v := os.Getenv("SPAM_SCORE_THRESHOLD"); v != "" {
+		threshold, err := strconv.Atoi(v)
+		if err != nil {
+			log.Fatal("SPAM_SCORE_THRESHOLD must be a number")
+		}
+		spamConfig.ScoreThreshold = threshold
+	}
+
+	// The agents' time zone also applies to the active hours of routing rules
+	notifyTimezone := os.Getenv("AGENT_NOTIFY_TIMEZONE")
+	if notifyTimezone == "" {
+		notifyTimezone = "UTC"
+	}
+	notifyLocation, err := time.LoadLocation(notifyTimezone)
+	if err != nil {
+		log.Fatalf("Invalid AGENT_NOTIFY_TIMEZONE: %s", err)
+	}
+	quietHours, err := notify.ParseQuietHours(os.Getenv("AGENT_NOTIFY_QUIET_HOURS"), notifyLocation)
+	if err != nil {
+		log.Fatalf("Invalid AGENT_NOTIFY_QUIET_HOURS: %s", err)
+	}
+	notifyConfig := notify.Config{QuietHours: quietHours}
+	if v := os.Getenv("AGENT_NOTIFY_DIGEST_TEMPLATE_ID"); v != "" {
+		notifyConfig.DigestTemplateID, err = strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			log.Fatal("AGENT_NOTIFY_DIGEST_TEMPLATE_ID must be a number")
+		}
+	}
+	if os.Getenv("AGENT_NOTIFY_QUIET_HOURS") != "" && notifyConfig.DigestTemplateID == 0 {
+		log.Fatal("AGENT_NOTIFY_DIGEST_TEMPLATE_ID is required with AGENT_NOTIFY_QUIET_HOURS")
+	}
+
+	// Showing requests and open house sign-ins have dedicated Brevo lists;
+	// without one, their contacts are added to Brevo on no list
+	var showingListID int64
+	if v := os.Getenv("BREVO_SHOWING_LIST_ID"); v != "" {
+		showingListID, err = strconv.ParseInt(v, 10, 64)
+		if err != nil || showingListID < 0 {
+			log.Fatal("BREVO_SHOWING_LIST_ID must be a Brevo list ID")
+		}
+	}
+	var openHouseListID int64
+	if v := os.Getenv("BREVO_OPEN_HOUSE_LIST_ID"); v != "" {
+		openHouseListID, err = strconv.ParseInt(v, 10, 64)
+		if err != nil || openHouseListID < 0 {
+			log.Fatal("BREVO_OPEN_HOUSE_LIST_ID must be a Brevo list ID")
+		}
+	}
+
+	// Brevo's webhook receiver stays off without a secret
+	brevoWebhookSecret := os.Getenv("BREVO_WEBHOOK_SECRET")
+
+	// Newsletter signup stays off until all four are set
+	newsletterConfig := handlers.NewsletterConfig{
+		ConfirmURL: os.Getenv("NEWSLETTER_CONFIRM_URL"),
+		TokenKey:   os.Getenv("NEWSLETTER_TOKEN_KEY"),
+	}
+	if v := os.Getenv("BREVO_NEWSLETTER_LIST_ID"); v != "" {
+		newsletterConfig.ListID, err = strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			log.Fatal("BREVO_NEWSLETTER_LIST_ID must be a number")
+		}
+	}
+	if v := os.Getenv("BREVO_NEWSLETTER_TEMPLATE_ID"); v != "" {
+		newsletterConfig.TemplateID, err = strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			log.Fatal("BREVO_NEWSLETTER_TEMPLATE_ID must be a number")
+		}
+	}
+
+	// Tax and insurance used where no location's assumptions match
+	mortgageDefaults := mortgage.Defaults
+	if v := os.Getenv("MORTGAGE_DEFAULT_TAX_RATE"); v != "" {
+		mortgageDefaults.TaxRate, err = strconv.ParseFloat(v, 64)
+		if err != nil || mortgageDefaults.TaxRate < 0 || mortgageDefaults.TaxRate >= 1 {
+			log.Fatal("MORTGAGE_DEFAULT_TAX_RATE must be a share of the price between 0 and 1")
+		}
+	}
+	if v := os.Getenv("MORTGAGE_DEFAULT_ANNUAL_INSURANCE"); v != "" {
+		mortgageDefaults.AnnualInsurance, err = strconv.ParseFloat(v, 64)
+		if err != nil || mortgageDefaults.AnnualInsurance < 0 {
+			log.Fatal("MORTGAGE_DEFAULT_ANNUAL_INSURANCE must be a non-negative number")
+		}
+	}
+
 	awsCfg, err := config.LoadDefaultConfig(context.Background(), config.WithRegion(s3Region))
 	if err != nil {
 		log.Fatal(err)
@@ -75,21 +217,103 @@ func main() {
 	}
 	dbQueries := database.New(db)
 
-	apiCfg := handlers.NewConfig(port, secret, appPassword, FUBKey, system, systemKey, s3Bucket, s3Region, brevoAPIKey, env, dbQueries, db, client)
+	apiCfg := handlers.NewConfig(port, secret, appPassword, FUBKey, system, systemKey, s3Bucket, s3Region, brevoAPIKey, brevoWebhookSecret, env, crmProvider, crmWebhookURL, crmWebhookSecret, phoneRegion, consentVersion, auditLogKey, subjectHashKey, spamConfig, notifyConfig, notifyLocation, showingListID, openHouseListID, newsletterConfig, mortgageDefaults, dbQueries, db, client)
 
 	corsHandler := cors.New(cors.Options{
 		AllowedOrigins:   []string{"https://soldbyghost.com", "https://admin.soldbyghost.com"},
 		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"Content-Type", "Authorization", "X-CSRF-TOKEN"},
+		AllowedHeaders:   []string{"Content-Type", "Authorization", "X-CSRF-TOKEN", "Idempotency-Key", "X-Device-Token"},
 		AllowCredentials: true,
 	})
 
+	// Deliver stored leads to the CRM and Brevo in the background
+	go apiCfg.RunOutbox(context.Background())
+
 	mux := http.NewServeMux()
 
 	// Lead Submission
-	mux.HandleFunc("POST /api/submit/form", apiCfg.SubmitForm)
-	mux.HandleFunc("POST /api/calculator", apiCfg.CalculateMortgage)
-	mux.HandleFunc("POST /api/estimate", apiCfg.Estimate)
+	mux.HandleFunc("GET /api/forms/token", apiCfg.FormToken)
+	mux.HandleFunc("POST /api/submit/form", apiCfg.Idempotent(apiCfg.SpamGuard(apiCfg.SubmitForm, handlers.DecoyStatus(http.StatusNoContent))))
+	mux.HandleFunc("POST /api/calculator", apiCfg.Idempotent(apiCfg.SpamGuard(apiCfg.CalculateMortgage, handlers.DecoyStatus(http.StatusNoContent))))
+	mux.HandleFunc("POST /api/estimate", apiCfg.Idempotent(apiCfg.SpamGuard(apiCfg.Estimate, handlers.DecoyStatus(http.StatusOK))))
+	mux.HandleFunc("POST /api/showing", apiCfg.Idempotent(apiCfg.SpamGuard(apiCfg.RequestShowing, handlers.DecoyStatus(http.StatusNoContent))))
+	mux.HandleFunc("POST /api/leads", apiCfg.Idempotent(apiCfg.SpamGuard(apiCfg.CreateLead, handlers.DecoyLeadCreated)))
+	mux.HandleFunc("GET /api/spam/quarantine", apiCfg.AuthMiddleware(apiCfg.ListQuarantine))
+
+	// Mortgage calculations, which don't create leads
+	mux.HandleFunc("POST /api/mortgage/calculate", apiCfg.MortgageBreakdown)
+	mux.HandleFunc("POST /api/mortgage/amortization", apiCfg.Amortization)
+	mux.HandleFunc("POST /api/mortgage/affordability", apiCfg.Affordability)
+	mux.HandleFunc("GET /api/mortgage/assumptions", apiCfg.AuthMiddleware(apiCfg.ListMortgageAssumptions))
+	mux.HandleFunc("POST /api/mortgage/assumptions", apiCfg.AuthMiddleware(apiCfg.CreateMortgageAssumption))
+	mux.HandleFunc("PUT /api/mortgage/assumptions/{id}", apiCfg.AuthMiddleware(apiCfg.UpdateMortgageAssumption))
+	mux.HandleFunc("DELETE /api/mortgage/assumptions/{id}", apiCfg.AuthMiddleware(apiCfg.DeleteMortgageAssumption))
+
+	// Leads inbox
+	mux.HandleFunc("GET /api/leads", apiCfg.AuthMiddleware(apiCfg.ListLeads))
+	mux.HandleFunc("GET /api/leads/export", apiCfg.AuthMiddleware(apiCfg.ExportLeads))
+	mux.HandleFunc("POST /api/leads/import", apiCfg.AuthMiddleware(apiCfg.ImportLeads))
+	mux.HandleFunc("GET /api/leads/attribution", apiCfg.AuthMiddleware(apiCfg.LeadAttribution))
+	mux.HandleFunc("GET /api/leads/{id}", apiCfg.AuthMiddleware(apiCfg.GetLead))
+	mux.HandleFunc("POST /api/leads/{id}/notes", apiCfg.AuthMiddleware(apiCfg.AddLeadNote))
+	mux.HandleFunc("PUT /api/leads/{id}/status", apiCfg.AuthMiddleware(apiCfg.UpdateLeadStatus))
+
+	// Webhooks
+	mux.HandleFunc("GET /api/webhooks", apiCfg.AuthMiddleware(apiCfg.ListWebhookSubscriptions))
+	mux.HandleFunc("POST /api/webhooks", apiCfg.AuthMiddleware(apiCfg.CreateWebhookSubscription))
+	mux.HandleFunc("PUT /api/webhooks/{id}", apiCfg.AuthMiddleware(apiCfg.UpdateWebhookSubscription))
+	mux.HandleFunc("DELETE /api/webhooks/{id}", apiCfg.AuthMiddleware(apiCfg.DeleteWebhookSubscription))
+	mux.HandleFunc("GET /api/webhooks/deliveries", apiCfg.AuthMiddleware(apiCfg.ListWebhookDeliveries))
+	mux.HandleFunc("GET /api/webhooks/deliveries/{id}", apiCfg.AuthMiddleware(apiCfg.GetWebhookDelivery))
+	mux.HandleFunc("POST /api/webhooks/deliveries/{id}/replay", apiCfg.AuthMiddleware(apiCfg.ReplayWebhookDelivery))
+
+	// Consent
+	mux.HandleFunc("GET /api/consent", apiCfg.AuthMiddleware(apiCfg.ExportConsent))
+
+	// Follow Up Boss sync
+	mux.HandleFunc("POST /api/fub/webhook", apiCfg.FUBWebhook)
+
+	// Brevo email events
+	mux.HandleFunc("POST /api/brevo/events", apiCfg.BrevoEvents)
+	mux.HandleFunc("GET /api/email/suppressions", apiCfg.AuthMiddleware(apiCfg.ListEmailSuppressions))
+	mux.HandleFunc("DELETE /api/email/suppressions/{id}", apiCfg.AuthMiddleware(apiCfg.DeleteEmailSuppression))
+
+	// Newsletter
+	mux.HandleFunc("POST /api/newsletter/subscribe", apiCfg.Idempotent(apiCfg.SpamGuard(apiCfg.SubscribeNewsletter, handlers.DecoyStatus(http.StatusAccepted))))
+	mux.HandleFunc("POST /api/newsletter/confirm", apiCfg.ConfirmNewsletter)
+	mux.HandleFunc("GET /api/newsletter/subscriptions", apiCfg.AuthMiddleware(apiCfg.ListNewsletterSubscriptions))
+
+	// Privacy requests
+	mux.HandleFunc("POST /api/privacy/access", apiCfg.AuthMiddleware(apiCfg.PrivacyAccess))
+	mux.HandleFunc("POST /api/privacy/erasure", apiCfg.AuthMiddleware(apiCfg.PrivacyErasure))
+	mux.HandleFunc("GET /api/privacy/requests", apiCfg.AuthMiddleware(apiCfg.ListPrivacyRequests))
+
+	// Agent notifications
+	mux.HandleFunc("GET /api/notifications/rules", apiCfg.AuthMiddleware(apiCfg.ListNotificationRules))
+	mux.HandleFunc("PUT /api/notifications/rules/{leadType}", apiCfg.AuthMiddleware(apiCfg.PutNotificationRule))
+	mux.HandleFunc("DELETE /api/notifications/rules/{leadType}", apiCfg.AuthMiddleware(apiCfg.DeleteNotificationRule))
+
+	// Open houses
+	mux.HandleFunc("GET /api/open-houses", apiCfg.AuthMiddleware(apiCfg.ListOpenHouses))
+	mux.HandleFunc("POST /api/open-houses", apiCfg.AuthMiddleware(apiCfg.CreateOpenHouse))
+	mux.HandleFunc("GET /api/open-houses/{id}", apiCfg.GetOpenHouse)
+	mux.HandleFunc("POST /api/open-houses/{id}/device-token", apiCfg.AuthMiddleware(apiCfg.IssueOpenHouseDeviceToken))
+	mux.HandleFunc("POST /api/open-houses/{id}/sign-ins", apiCfg.SignInOpenHouse)
+	mux.HandleFunc("GET /api/open-houses/{id}/attendees", apiCfg.AuthMiddleware(apiCfg.ExportOpenHouseAttendees))
+
+	// Lead routing
+	mux.HandleFunc("GET /api/routing/rules", apiCfg.AuthMiddleware(apiCfg.ListRoutingRules))
+	mux.HandleFunc("POST /api/routing/rules", apiCfg.AuthMiddleware(apiCfg.CreateRoutingRule))
+	mux.HandleFunc("PUT /api/routing/rules/{id}", apiCfg.AuthMiddleware(apiCfg.UpdateRoutingRule))
+	mux.HandleFunc("DELETE /api/routing/rules/{id}", apiCfg.AuthMiddleware(apiCfg.DeleteRoutingRule))
+	mux.HandleFunc("POST /api/routing/test", apiCfg.AuthMiddleware(apiCfg.TestRoutingRules))
+
+	// Lead scoring
+	mux.HandleFunc("GET /api/scoring/rules", apiCfg.AuthMiddleware(apiCfg.ListScoringRules))
+	mux.HandleFunc("POST /api/scoring/rules", apiCfg.AuthMiddleware(apiCfg.CreateScoringRule))
+	mux.HandleFunc("PUT /api/scoring/rules/{id}", apiCfg.AuthMiddleware(apiCfg.UpdateScoringRule))
+	mux.HandleFunc("DELETE /api/scoring/rules/{id}", apiCfg.AuthMiddleware(apiCfg.DeleteScoringRule))
+	mux.HandleFunc("POST /api/scoring/rescore", apiCfg.AuthMiddleware(apiCfg.RescoreLeads))
 
 	// Auth
 	mux.HandleFunc("POST /api/auth/login", apiCfg.Login)
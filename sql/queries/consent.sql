@@ -0,0 +1,9 @@
+-- name: CreateConsentRecord :one
+INSERT INTO consent_records (lead_id, email, purpose, consented, text_version, ip_address, user_agent, route)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING *;
+
+-- name: ListConsentRecordsByEmail :many
+SELECT * FROM consent_records
+WHERE lower(email) = lower(sqlc.arg('email')::text)
+ORDER BY created_at;
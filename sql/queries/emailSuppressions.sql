@@ -0,0 +1,45 @@
+-- name: CreateEmailEvent :execrows
+INSERT INTO email_events (email, event, message_id, payload, occurred_at)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (email, event, message_id, occurred_at) DO NOTHING;
+
+-- name: UpsertEmailSuppression :exec
+INSERT INTO email_suppressions (email, reason, suppressed_at)
+VALUES ($1, $2, $3)
+ON CONFLICT (lower(email)) DO UPDATE
+SET reason = EXCLUDED.reason, suppressed_at = EXCLUDED.suppressed_at
+WHERE email_suppressions.suppressed_at <= EXCLUDED.suppressed_at;
+
+-- name: GetEmailSuppression :one
+SELECT * FROM email_suppressions
+WHERE lower(email) = lower(sqlc.arg('email')::text) OR email = sqlc.arg('erased')::text
+ORDER BY suppressed_at DESC
+LIMIT 1;
+
+-- name: ListSuppressedEmails :many
+SELECT lower(email)::text AS email FROM email_suppressions
+WHERE lower(email) = ANY(sqlc.arg('emails')::text[]);
+
+-- name: ListEmailSuppressions :many
+SELECT * FROM email_suppressions
+ORDER BY suppressed_at DESC
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
+
+-- name: CountEmailSuppressions :one
+SELECT COUNT(*) FROM email_suppressions;
+
+-- name: DeleteEmailSuppression :execrows
+DELETE FROM email_suppressions WHERE id = $1;
+
+-- name: DeleteEmailSuppressionByEmail :execrows
+DELETE FROM email_suppressions
+WHERE (lower(email) = lower(sqlc.arg('email')::text) OR email = sqlc.arg('erased')::text)
+  AND reason = sqlc.arg('reason')::text;
+
+-- name: ListEmailEventsByEmail :many
+SELECT * FROM email_events
+WHERE lower(email) = lower(sqlc.arg('email')::text)
+ORDER BY occurred_at;
+
+-- name: DeleteEmailEventsByEmail :execrows
+DELETE FROM email_events WHERE lower(email) = lower(sqlc.arg('email')::text);
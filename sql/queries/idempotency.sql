@@ -0,0 +1,20 @@
+-- name: LockIdempotencyKey :exec
+SELECT pg_advisory_xact_lock(hashtextextended(sqlc.arg('key')::text, 0));
+
+-- name: GetIdempotencyKey :one
+SELECT * FROM idempotency_keys
+WHERE key = $1 AND expires_at > CURRENT_TIMESTAMP;
+
+-- name: SaveIdempotencyKey :exec
+INSERT INTO idempotency_keys (key, request_hash, response_status, content_type, response_body, expires_at)
+VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT (key) DO UPDATE
+SET request_hash = EXCLUDED.request_hash,
+    response_status = EXCLUDED.response_status,
+    content_type = EXCLUDED.content_type,
+    response_body = EXCLUDED.response_body,
+    created_at = CURRENT_TIMESTAMP,
+    expires_at = EXCLUDED.expires_at;
+
+-- name: DeleteExpiredIdempotencyKeys :execrows
+DELETE FROM idempotency_keys WHERE expires_at <= CURRENT_TIMESTAMP;
@@ -0,0 +1,160 @@
+-- name: CreateLead :one
+INSERT INTO leads (
+    lead_type, first_name, last_name, email, phone, message, payload,
+    source, utm_source, utm_medium, utm_campaign, gclid, fbclid, referrer, landing_page,
+    marketing_consent, assigned_agent, routing_rule_id, score, created_at
+)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20)
+RETURNING *;
+
+-- name: GetLeadByID :one
+SELECT * FROM leads WHERE id = $1;
+
+-- name: CreateLeadDelivery :exec
+INSERT INTO lead_deliveries (lead_id, target)
+VALUES ($1, $2);
+
+-- name: CreateLeadDeliveries :exec
+INSERT INTO lead_deliveries (lead_id, target)
+SELECT unnest(sqlc.arg('lead_ids')::uuid[]), sqlc.arg('target')::text;
+
+-- name: ClaimLeadDeliveries :many
+UPDATE lead_deliveries
+SET status = 'processing', attempts = attempts + 1, updated_at = CURRENT_TIMESTAMP
+WHERE id IN (
+    SELECT id FROM lead_deliveries
+    WHERE (status = 'pending' AND next_attempt_at <= CURRENT_TIMESTAMP)
+       OR (status = 'processing' AND updated_at < CURRENT_TIMESTAMP - INTERVAL '10 minutes')
+    ORDER BY next_attempt_at
+    LIMIT $1
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING *;
+
+-- name: MarkLeadDeliveryDelivered :exec
+UPDATE lead_deliveries
+SET status = 'delivered', last_error = NULL, delivered_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+WHERE id = $1;
+
+-- name: MarkLeadDeliveryFailed :exec
+UPDATE lead_deliveries
+SET status = $2, last_error = $3, next_attempt_at = $4, updated_at = CURRENT_TIMESTAMP
+WHERE id = $1;
+
+-- name: CreateLeadDeliveryAttempt :exec
+INSERT INTO lead_delivery_attempts (delivery_id, attempt, status, error)
+VALUES ($1, $2, $3, $4);
+
+-- name: ListLeads :many
+SELECT * FROM leads
+WHERE (sqlc.narg('lead_type')::text IS NULL OR lead_type = sqlc.narg('lead_type'))
+  AND (sqlc.narg('status')::text IS NULL OR status = sqlc.narg('status'))
+  AND (sqlc.narg('created_from')::timestamptz IS NULL OR created_at >= sqlc.narg('created_from'))
+  AND (sqlc.narg('created_to')::timestamptz IS NULL OR created_at < sqlc.narg('created_to'))
+  AND (sqlc.narg('delivery_status')::text IS NULL OR EXISTS (
+      SELECT 1 FROM lead_deliveries d WHERE d.lead_id = leads.id AND d.status = sqlc.narg('delivery_status')
+  ))
+  AND (sqlc.narg('search')::text IS NULL
+      OR (first_name || ' ' || last_name) ILIKE '%' || sqlc.narg('search') || '%'
+      OR email ILIKE '%' || sqlc.narg('search') || '%'
+      OR (regexp_replace(sqlc.narg('search'), '\D', '', 'g') <> ''
+          AND regexp_replace(phone, '\D', '', 'g') LIKE '%' || regexp_replace(sqlc.narg('search'), '\D', '', 'g') || '%'))
+ORDER BY CASE WHEN sqlc.arg('by_score')::boolean THEN score END DESC, created_at DESC
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
+
+-- name: CountLeads :one
+SELECT COUNT(*) FROM leads
+WHERE (sqlc.narg('lead_type')::text IS NULL OR lead_type = sqlc.narg('lead_type'))
+  AND (sqlc.narg('status')::text IS NULL OR status = sqlc.narg('status'))
+  AND (sqlc.narg('created_from')::timestamptz IS NULL OR created_at >= sqlc.narg('created_from'))
+  AND (sqlc.narg('created_to')::timestamptz IS NULL OR created_at < sqlc.narg('created_to'))
+  AND (sqlc.narg('delivery_status')::text IS NULL OR EXISTS (
+      SELECT 1 FROM lead_deliveries d WHERE d.lead_id = leads.id AND d.status = sqlc.narg('delivery_status')
+  ))
+  AND (sqlc.narg('search')::text IS NULL
+      OR (first_name || ' ' || last_name) ILIKE '%' || sqlc.narg('search') || '%'
+      OR email ILIKE '%' || sqlc.narg('search') || '%'
+      OR (regexp_replace(sqlc.narg('search'), '\D', '', 'g') <> ''
+          AND regexp_replace(phone, '\D', '', 'g') LIKE '%' || regexp_replace(sqlc.narg('search'), '\D', '', 'g') || '%'));
+
+-- name: UpdateLeadStatus :one
+UPDATE leads
+SET status = $2, updated_at = CURRENT_TIMESTAMP
+WHERE id = $1
+RETURNING *;
+
+-- name: ListLeadDeliveries :many
+SELECT * FROM lead_deliveries
+WHERE lead_id = $1
+ORDER BY created_at;
+
+-- name: ListLeadDeliveryAttempts :many
+SELECT a.* FROM lead_delivery_attempts a
+JOIN lead_deliveries d ON d.id = a.delivery_id
+WHERE d.lead_id = $1
+ORDER BY a.attempted_at;
+
+-- name: CreateLeadNote :one
+INSERT INTO lead_notes (lead_id, user_id, body)
+VALUES ($1, $2, $3)
+RETURNING *;
+
+-- name: ListLeadNotes :many
+SELECT n.id, n.lead_id, n.user_id, n.body, n.created_at, u.username
+FROM lead_notes n
+LEFT JOIN users u ON u.id = n.user_id
+WHERE n.lead_id = $1
+ORDER BY n.created_at;
+
+-- name: SummarizeLeadAttribution :many
+SELECT source, utm_medium, utm_campaign, COUNT(*) AS leads FROM leads
+WHERE (sqlc.narg('lead_type')::text IS NULL OR lead_type = sqlc.narg('lead_type'))
+  AND (sqlc.narg('created_from')::timestamptz IS NULL OR created_at >= sqlc.narg('created_from'))
+  AND (sqlc.narg('created_to')::timestamptz IS NULL OR created_at < sqlc.narg('created_to'))
+GROUP BY source, utm_medium, utm_campaign
+ORDER BY leads DESC, source, utm_medium, utm_campaign;
+
+-- name: ListLeadsForExport :many
+SELECT * FROM leads
+WHERE (sqlc.narg('lead_type')::text IS NULL OR lead_type = sqlc.narg('lead_type'))
+  AND (sqlc.narg('status')::text IS NULL OR status = sqlc.narg('status'))
+  AND (sqlc.narg('created_from')::timestamptz IS NULL OR created_at >= sqlc.narg('created_from'))
+  AND (sqlc.narg('created_to')::timestamptz IS NULL OR created_at < sqlc.narg('created_to'))
+  AND (created_at, id) > (sqlc.arg('after_created_at')::timestamptz, sqlc.arg('after_id')::uuid)
+ORDER BY created_at, id
+LIMIT sqlc.arg('limit');
+
+-- name: FindDuplicateLead :one
+SELECT id FROM leads
+WHERE lower(email) = lower(sqlc.arg('email')::text)
+   OR (sqlc.arg('phone')::text <> '' AND phone = sqlc.arg('phone')::text)
+LIMIT 1;
+
+-- name: CountPriorLeads :one
+SELECT COUNT(*) FROM leads
+WHERE lower(email) = lower(sqlc.arg('email')::text)
+  AND created_at < sqlc.arg('before')::timestamptz;
+
+-- name: CountPriorLeadsForLeads :many
+SELECT l.id, COUNT(p.id) AS prior_leads
+FROM leads l
+LEFT JOIN leads p ON lower(p.email) = lower(l.email) AND p.created_at < l.created_at
+WHERE l.id = ANY(sqlc.arg('ids')::uuid[])
+GROUP BY l.id;
+
+-- name: UpdateLeadScores :many
+UPDATE leads
+SET score = s.score
+FROM unnest(sqlc.arg('ids')::uuid[], sqlc.arg('scores')::integer[]) AS s(id, score)
+WHERE leads.id = s.id AND leads.score <> s.score
+RETURNING leads.id;
+
+-- name: SyncLeadsFromCRM :execrows
+UPDATE leads
+SET crm_person_id = sqlc.arg('person_id')::bigint,
+    crm_stage = sqlc.arg('stage')::text,
+    status = COALESCE(sqlc.narg('status')::text, status),
+    updated_at = CURRENT_TIMESTAMP
+WHERE crm_person_id = sqlc.arg('person_id')::bigint
+   OR lower(email) = ANY(sqlc.arg('emails')::text[])
+   OR phone = ANY(sqlc.arg('phones')::text[]);
@@ -0,0 +1,21 @@
+-- name: ListMortgageAssumptions :many
+SELECT * FROM mortgage_assumptions ORDER BY state, county, zip, updated_at DESC;
+
+-- name: CreateMortgageAssumption :one
+INSERT INTO mortgage_assumptions (state, county, zip, tax_rate, annual_insurance)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING *;
+
+-- name: UpdateMortgageAssumption :one
+UPDATE mortgage_assumptions
+SET state = $2,
+    county = $3,
+    zip = $4,
+    tax_rate = $5,
+    annual_insurance = $6,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = $1
+RETURNING *;
+
+-- name: DeleteMortgageAssumption :execrows
+DELETE FROM mortgage_assumptions WHERE id = $1;
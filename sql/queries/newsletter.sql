@@ -0,0 +1,48 @@
+-- name: UpsertNewsletterSubscription :one
+INSERT INTO newsletter_subscriptions (email, first_name, consent_version, ip_address, user_agent, expires_at)
+VALUES (
+    sqlc.arg('email'), sqlc.arg('first_name'), sqlc.arg('consent_version'),
+    sqlc.arg('ip_address'), sqlc.arg('user_agent'), sqlc.arg('expires_at')
+)
+ON CONFLICT (lower(email)) DO UPDATE
+SET first_name = EXCLUDED.first_name,
+    consent_version = EXCLUDED.consent_version,
+    ip_address = EXCLUDED.ip_address,
+    user_agent = EXCLUDED.user_agent,
+    expires_at = EXCLUDED.expires_at,
+    status = 'pending',
+    confirmed_at = NULL,
+    updated_at = CURRENT_TIMESTAMP
+WHERE newsletter_subscriptions.status = 'pending' OR sqlc.arg('reopen')::boolean
+RETURNING *;
+
+-- name: GetNewsletterSubscription :one
+SELECT * FROM newsletter_subscriptions WHERE id = $1;
+
+-- name: ConfirmNewsletterSubscription :one
+UPDATE newsletter_subscriptions
+SET status = 'confirmed', confirmed_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+WHERE id = $1 AND status = 'pending'
+RETURNING *;
+
+-- name: ListNewsletterSubscriptions :many
+SELECT * FROM newsletter_subscriptions
+WHERE sqlc.narg('status')::text IS NULL
+   OR (sqlc.narg('status') = 'confirmed' AND status = 'confirmed')
+   OR (sqlc.narg('status') = 'pending' AND status = 'pending' AND expires_at >= CURRENT_TIMESTAMP)
+   OR (sqlc.narg('status') = 'expired' AND status = 'pending' AND expires_at < CURRENT_TIMESTAMP)
+ORDER BY created_at DESC
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
+
+-- name: CountNewsletterSubscriptions :one
+SELECT COUNT(*) FROM newsletter_subscriptions
+WHERE sqlc.narg('status')::text IS NULL
+   OR (sqlc.narg('status') = 'confirmed' AND status = 'confirmed')
+   OR (sqlc.narg('status') = 'pending' AND status = 'pending' AND expires_at >= CURRENT_TIMESTAMP)
+   OR (sqlc.narg('status') = 'expired' AND status = 'pending' AND expires_at < CURRENT_TIMESTAMP);
+
+-- name: GetNewsletterSubscriptionByEmail :one
+SELECT * FROM newsletter_subscriptions WHERE lower(email) = lower(sqlc.arg('email')::text);
+
+-- name: DeleteNewsletterSubscriptionByEmail :execrows
+DELETE FROM newsletter_subscriptions WHERE lower(email) = lower(sqlc.arg('email')::text);
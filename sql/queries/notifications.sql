@@ -0,0 +1,48 @@
+-- name: ListNotificationRules :many
+SELECT * FROM notification_rules ORDER BY lead_type;
+
+-- name: GetNotificationRuleForLeadType :one
+SELECT * FROM notification_rules
+WHERE lead_type = $1 OR lead_type = '*'
+ORDER BY lead_type = '*'
+LIMIT 1;
+
+-- name: UpsertNotificationRule :one
+INSERT INTO notification_rules (lead_type, recipients, template_id, enabled, respect_quiet_hours)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (lead_type) DO UPDATE
+SET recipients = EXCLUDED.recipients,
+    template_id = EXCLUDED.template_id,
+    enabled = EXCLUDED.enabled,
+    respect_quiet_hours = EXCLUDED.respect_quiet_hours,
+    updated_at = CURRENT_TIMESTAMP
+RETURNING *;
+
+-- name: DeleteNotificationRule :execrows
+DELETE FROM notification_rules WHERE lead_type = $1;
+
+-- name: CreateNotificationDigestItem :exec
+INSERT INTO notification_digest_items (lead_id, recipients)
+VALUES ($1, $2);
+
+-- name: ClaimNotificationDigestItems :many
+SELECT * FROM notification_digest_items
+WHERE sent_at IS NULL
+  AND failed_at IS NULL
+  AND next_attempt_at <= CURRENT_TIMESTAMP
+ORDER BY created_at
+LIMIT $1
+FOR UPDATE SKIP LOCKED;
+
+-- name: MarkNotificationDigestItemsSent :exec
+UPDATE notification_digest_items
+SET sent_at = CURRENT_TIMESTAMP
+WHERE id = ANY(sqlc.arg('ids')::uuid[]);
+
+-- name: MarkNotificationDigestItemsFailed :exec
+UPDATE notification_digest_items
+SET attempts = attempts + 1,
+    last_error = sqlc.arg('last_error'),
+    next_attempt_at = sqlc.arg('next_attempt_at'),
+    failed_at = CASE WHEN attempts + 1 >= sqlc.arg('max_attempts')::integer THEN CURRENT_TIMESTAMP END
+WHERE id = ANY(sqlc.arg('ids')::uuid[]);
@@ -0,0 +1,41 @@
+-- name: CreateOpenHouse :one
+INSERT INTO open_houses (address, city, state, zip, mls_number, starts_at, ends_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING *;
+
+-- name: GetOpenHouse :one
+SELECT * FROM open_houses WHERE id = $1;
+
+-- name: ListOpenHouses :many
+SELECT * FROM open_houses
+ORDER BY starts_at DESC
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
+
+-- name: CountOpenHouses :one
+SELECT COUNT(*) FROM open_houses;
+
+-- name: CreateOpenHouseSignIn :execrows
+INSERT INTO open_house_sign_ins (open_house_id, client_id, lead_id, has_agent, pre_approved, signed_in_at)
+VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT (open_house_id, client_id) DO NOTHING;
+
+-- name: GetOpenHouseSignIn :one
+SELECT * FROM open_house_sign_ins
+WHERE open_house_id = $1 AND client_id = $2;
+
+-- name: ListOpenHouseAttendees :many
+SELECT s.client_id, s.has_agent, s.pre_approved, s.signed_in_at, l.id AS lead_id, l.first_name, l.last_name, l.email, l.phone, l.marketing_consent
+FROM open_house_sign_ins s
+JOIN leads l ON l.id = s.lead_id
+WHERE s.open_house_id = $1
+ORDER BY s.signed_in_at;
+
+-- name: SetOpenHouseDeviceToken :execrows
+UPDATE open_houses
+SET device_token_hash = $2,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = $1;
+
+-- name: CountRecentOpenHouseSignIns :one
+SELECT COUNT(*) FROM open_house_sign_ins
+WHERE open_house_id = $1 AND created_at > $2;
@@ -45,10 +45,11 @@ SELECT id, title, slug, excerpt, content, author, published_at, thumbnail, statu
 FROM posts
 ORDER BY created_at DESC;
 
--- name: PublishPost :exec
+-- name: PublishPost :one
 UPDATE posts
 SET status = $2, updated_at = CURRENT_TIMESTAMP, published_at = CURRENT_TIMESTAMP
-WHERE id = $1;
+WHERE id = $1
+RETURNING *;
 
 -- name: UnpublishPost :exec
 UPDATE posts
@@ -0,0 +1,52 @@
+-- name: ListLeadsBySubject :many
+SELECT * FROM leads
+WHERE lower(email) = lower(sqlc.arg('email')::text)
+   OR (sqlc.arg('phone')::text <> '' AND phone = sqlc.arg('phone')::text)
+ORDER BY created_at;
+
+-- name: ListSpamQuarantineByEmail :many
+SELECT * FROM spam_quarantine
+WHERE lower(email) = lower(sqlc.arg('email')::text)
+ORDER BY created_at;
+
+-- name: DeleteLeadsByID :execrows
+DELETE FROM leads WHERE id = ANY(sqlc.arg('ids')::uuid[]);
+
+-- name: AnonymizeConsentRecords :execrows
+UPDATE consent_records
+SET email = sqlc.arg('replacement')::text, ip_address = '', user_agent = ''
+WHERE lower(email) = lower(sqlc.arg('email')::text);
+
+-- name: DeleteSupersededEmailSuppression :exec
+DELETE FROM email_suppressions
+WHERE email = sqlc.arg('replacement')::text
+  AND EXISTS (SELECT 1 FROM email_suppressions WHERE lower(email) = lower(sqlc.arg('email')::text));
+
+-- name: AnonymizeEmailSuppression :execrows
+UPDATE email_suppressions
+SET email = sqlc.arg('replacement')::text
+WHERE lower(email) = lower(sqlc.arg('email')::text);
+
+-- name: DeleteSpamQuarantineByEmail :execrows
+DELETE FROM spam_quarantine
+WHERE lower(email) = lower(sqlc.arg('email')::text);
+
+-- name: DeleteWebhookDeliveriesByEmail :execrows
+DELETE FROM webhook_deliveries
+WHERE lower(payload->'data'->>'email') = lower(sqlc.arg('email')::text);
+
+-- name: LockPrivacyRequests :exec
+SELECT pg_advisory_xact_lock(hashtext('privacy_requests'));
+
+-- name: GetLastPrivacyRequest :one
+SELECT * FROM privacy_requests
+ORDER BY seq DESC
+LIMIT 1;
+
+-- name: CreatePrivacyRequest :one
+INSERT INTO privacy_requests (id, action, subject_hash, details, performed_by, created_at, prev_hash, hash)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING *;
+
+-- name: ListPrivacyRequests :many
+SELECT * FROM privacy_requests ORDER BY seq;
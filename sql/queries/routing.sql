@@ -0,0 +1,44 @@
+-- name: ListRoutingRules :many
+SELECT * FROM routing_rules ORDER BY priority, created_at;
+
+-- name: ListEnabledRoutingRules :many
+SELECT * FROM routing_rules
+WHERE enabled = TRUE
+ORDER BY priority, created_at;
+
+-- name: GetRoutingRule :one
+SELECT * FROM routing_rules WHERE id = $1;
+
+-- name: CreateRoutingRule :one
+INSERT INTO routing_rules (
+    name, priority, enabled, lead_types, zips, cities, min_price, max_price, active_hours, strategy, agents
+)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+RETURNING *;
+
+-- name: UpdateRoutingRule :one
+UPDATE routing_rules
+SET name = $2,
+    priority = $3,
+    enabled = $4,
+    lead_types = $5,
+    zips = $6,
+    cities = $7,
+    min_price = $8,
+    max_price = $9,
+    active_hours = $10,
+    strategy = $11,
+    agents = $12,
+    next_agent = 0,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = $1
+RETURNING *;
+
+-- name: DeleteRoutingRule :execrows
+DELETE FROM routing_rules WHERE id = $1;
+
+-- name: AdvanceRoutingRule :one
+UPDATE routing_rules
+SET next_agent = next_agent + 1
+WHERE id = $1
+RETURNING *;
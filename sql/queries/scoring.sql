@@ -0,0 +1,21 @@
+-- name: ListScoringRules :many
+SELECT * FROM scoring_rules ORDER BY signal, created_at;
+
+-- name: CreateScoringRule :one
+INSERT INTO scoring_rules (signal, value, min_value, max_value, points)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING *;
+
+-- name: UpdateScoringRule :one
+UPDATE scoring_rules
+SET signal = $2,
+    value = $3,
+    min_value = $4,
+    max_value = $5,
+    points = $6,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = $1
+RETURNING *;
+
+-- name: DeleteScoringRule :execrows
+DELETE FROM scoring_rules WHERE id = $1;
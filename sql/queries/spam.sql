@@ -0,0 +1,8 @@
+-- name: CreateQuarantinedSubmission :exec
+INSERT INTO spam_quarantine (route, reason, score, email, ip_address, user_agent, payload)
+VALUES ($1, $2, $3, $4, $5, $6, $7);
+
+-- name: ListQuarantinedSubmissions :many
+SELECT * FROM spam_quarantine
+ORDER BY created_at DESC
+LIMIT $1 OFFSET $2;
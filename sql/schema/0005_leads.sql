@@ -0,0 +1,46 @@
+-- +goose Up
+CREATE TABLE leads (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    lead_type VARCHAR(50) NOT NULL, -- e.g., 'contact', 'mortgage', 'seller-estimate'
+    first_name VARCHAR(100) NOT NULL DEFAULT '',
+    last_name VARCHAR(100) NOT NULL DEFAULT '',
+    email VARCHAR(255) NOT NULL DEFAULT '',
+    phone VARCHAR(50) NOT NULL DEFAULT '',
+    message TEXT NOT NULL DEFAULT '',
+    payload JSONB NOT NULL DEFAULT '{}', -- Raw form submission
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_leads_email ON leads (email);
+CREATE INDEX idx_leads_created_at ON leads (created_at);
+
+-- Outbox: one row per lead per downstream system
+CREATE TABLE lead_deliveries (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    lead_id UUID NOT NULL,
+    target VARCHAR(50) NOT NULL, -- e.g., 'crm', 'brevo'
+    status VARCHAR(50) NOT NULL DEFAULT 'pending', -- 'pending', 'processing', 'delivered', 'failed'
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT,
+    next_attempt_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    delivered_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (lead_id) REFERENCES leads(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_lead_deliveries_lead_id ON lead_deliveries (lead_id);
+CREATE INDEX idx_lead_deliveries_due ON lead_deliveries (status, next_attempt_at);
+
+CREATE TABLE lead_delivery_attempts (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    delivery_id UUID NOT NULL,
+    attempt INTEGER NOT NULL,
+    status VARCHAR(50) NOT NULL, -- 'succeeded', 'failed'
+    error TEXT,
+    attempted_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (delivery_id) REFERENCES lead_deliveries(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_lead_delivery_attempts_delivery_id ON lead_delivery_attempts (delivery_id);
@@ -0,0 +1,17 @@
+-- +goose Up
+ALTER TABLE leads ADD COLUMN status VARCHAR(50) NOT NULL DEFAULT 'new'; -- e.g., 'new', 'contacted', 'qualified', 'closed'
+
+CREATE INDEX idx_leads_status ON leads (status);
+CREATE INDEX idx_leads_lead_type ON leads (lead_type);
+
+CREATE TABLE lead_notes (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    lead_id UUID NOT NULL,
+    user_id UUID,
+    body TEXT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (lead_id) REFERENCES leads(id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE SET NULL
+);
+
+CREATE INDEX idx_lead_notes_lead_id ON lead_notes (lead_id);
@@ -0,0 +1,14 @@
+-- +goose Up
+CREATE TABLE spam_quarantine (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    route VARCHAR(255) NOT NULL,
+    reason TEXT NOT NULL,
+    score INTEGER NOT NULL DEFAULT 0,
+    email VARCHAR(255) NOT NULL DEFAULT '',
+    ip_address VARCHAR(255) NOT NULL DEFAULT '',
+    user_agent TEXT NOT NULL DEFAULT '',
+    payload JSONB NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_spam_quarantine_created_at ON spam_quarantine (created_at);
@@ -0,0 +1,12 @@
+-- +goose Up
+ALTER TABLE leads
+    ADD COLUMN source VARCHAR(255) NOT NULL DEFAULT '', -- utm_source, or derived from click IDs and the referrer
+    ADD COLUMN utm_source VARCHAR(255) NOT NULL DEFAULT '',
+    ADD COLUMN utm_medium VARCHAR(255) NOT NULL DEFAULT '',
+    ADD COLUMN utm_campaign VARCHAR(255) NOT NULL DEFAULT '',
+    ADD COLUMN gclid VARCHAR(255) NOT NULL DEFAULT '',
+    ADD COLUMN fbclid VARCHAR(255) NOT NULL DEFAULT '',
+    ADD COLUMN referrer TEXT NOT NULL DEFAULT '',
+    ADD COLUMN landing_page TEXT NOT NULL DEFAULT '';
+
+CREATE INDEX idx_leads_source ON leads (source);
@@ -0,0 +1,23 @@
+-- +goose Up
+CREATE TABLE notification_rules (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    lead_type VARCHAR(50) UNIQUE NOT NULL, -- a lead type, or '*' for types without their own rule
+    recipients TEXT[] NOT NULL,
+    template_id INTEGER NOT NULL, -- Brevo template for the instant email
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    respect_quiet_hours BOOLEAN NOT NULL DEFAULT TRUE, -- false sends instantly even during quiet hours
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Notifications held during quiet hours, sent together once they end
+CREATE TABLE notification_digest_items (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    lead_id UUID NOT NULL,
+    recipients TEXT[] NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    sent_at TIMESTAMP WITH TIME ZONE,
+    FOREIGN KEY (lead_id) REFERENCES leads(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_notification_digest_items_unsent ON notification_digest_items (created_at) WHERE sent_at IS NULL;
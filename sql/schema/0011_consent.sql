@@ -0,0 +1,19 @@
+-- +goose Up
+ALTER TABLE leads ADD COLUMN marketing_consent BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- Evidence of what each visitor agreed to, kept even when they declined
+CREATE TABLE consent_records (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    lead_id UUID,
+    email VARCHAR(255) NOT NULL,
+    purpose VARCHAR(50) NOT NULL DEFAULT 'marketing',
+    consented BOOLEAN NOT NULL,
+    text_version VARCHAR(50) NOT NULL, -- version of the consent wording shown on the form
+    ip_address VARCHAR(255) NOT NULL DEFAULT '',
+    user_agent TEXT NOT NULL DEFAULT '',
+    route VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (lead_id) REFERENCES leads(id) ON DELETE SET NULL
+);
+
+CREATE INDEX idx_consent_records_email ON consent_records (lower(email));
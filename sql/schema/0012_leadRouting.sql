@@ -0,0 +1,22 @@
+-- +goose Up
+CREATE TABLE routing_rules (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name VARCHAR(100) NOT NULL,
+    priority INTEGER NOT NULL DEFAULT 0, -- lowest matching priority wins
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    lead_types TEXT[] NOT NULL DEFAULT '{}', -- empty matches every type
+    zips TEXT[] NOT NULL DEFAULT '{}',
+    cities TEXT[] NOT NULL DEFAULT '{}',
+    min_price DOUBLE PRECISION,
+    max_price DOUBLE PRECISION,
+    active_hours VARCHAR(11) NOT NULL DEFAULT '', -- HH:MM-HH:MM in the agents' time zone, empty for all day
+    strategy VARCHAR(20) NOT NULL DEFAULT 'fixed', -- fixed or round_robin
+    agents TEXT[] NOT NULL,
+    next_agent INTEGER NOT NULL DEFAULT 0, -- round-robin position in agents
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+ALTER TABLE leads
+    ADD COLUMN assigned_agent VARCHAR(255) NOT NULL DEFAULT '',
+    ADD COLUMN routing_rule_id UUID REFERENCES routing_rules(id) ON DELETE SET NULL;
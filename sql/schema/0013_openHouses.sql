@@ -0,0 +1,27 @@
+-- +goose Up
+CREATE TABLE open_houses (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    address VARCHAR(255) NOT NULL,
+    city VARCHAR(100) NOT NULL DEFAULT '',
+    state VARCHAR(50) NOT NULL DEFAULT '',
+    zip VARCHAR(20) NOT NULL DEFAULT '',
+    mls_number VARCHAR(50) NOT NULL DEFAULT '',
+    starts_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    ends_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE open_house_sign_ins (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    open_house_id UUID NOT NULL,
+    client_id VARCHAR(100) NOT NULL, -- generated by the sign-in device, so retried batches are ignored
+    lead_id UUID NOT NULL,
+    has_agent BOOLEAN NOT NULL DEFAULT FALSE,
+    pre_approved BOOLEAN NOT NULL DEFAULT FALSE,
+    signed_in_at TIMESTAMP WITH TIME ZONE NOT NULL, -- when the visitor signed in, which may be long before upload
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (open_house_id) REFERENCES open_houses(id) ON DELETE CASCADE,
+    FOREIGN KEY (lead_id) REFERENCES leads(id) ON DELETE CASCADE,
+    UNIQUE (open_house_id, client_id)
+);
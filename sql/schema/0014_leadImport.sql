@@ -0,0 +1,4 @@
+-- +goose Up
+-- Lookups used to skip imported rows that match an existing lead
+CREATE INDEX idx_leads_email_lower ON leads (lower(email));
+CREATE INDEX idx_leads_phone ON leads (phone) WHERE phone <> '';
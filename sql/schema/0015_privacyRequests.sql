@@ -0,0 +1,16 @@
+-- +goose Up
+-- Hash-chained log of fulfilled data-subject requests. Rows are only ever
+-- appended; the subject is stored as a keyed hash, never in the clear.
+CREATE TABLE privacy_requests (
+    seq BIGSERIAL PRIMARY KEY,
+    id UUID UNIQUE NOT NULL,
+    action VARCHAR(20) NOT NULL, -- 'access' or 'erasure'
+    subject_hash VARCHAR(64) NOT NULL,
+    details TEXT NOT NULL, -- JSON summary, kept as text so its hash stays verifiable
+    performed_by VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    prev_hash VARCHAR(64) NOT NULL,
+    hash VARCHAR(64) NOT NULL
+);
+
+CREATE INDEX idx_spam_quarantine_email_lower ON spam_quarantine (lower(email));
@@ -0,0 +1,15 @@
+-- +goose Up
+CREATE TABLE scoring_rules (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    signal VARCHAR(20) NOT NULL, -- lead_type, price, phone, repeat, pages or timeline
+    value VARCHAR(100) NOT NULL DEFAULT '', -- matched by lead_type and timeline
+    min_value DOUBLE PRECISION, -- inclusive bound for price, repeat and pages
+    max_value DOUBLE PRECISION, -- exclusive bound
+    points INTEGER NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+ALTER TABLE leads ADD COLUMN score INTEGER NOT NULL DEFAULT 0;
+
+CREATE INDEX idx_leads_score ON leads (score DESC, created_at DESC);
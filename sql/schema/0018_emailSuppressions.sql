@@ -0,0 +1,23 @@
+-- +goose Up
+-- Email events reported by Brevo's webhooks
+CREATE TABLE email_events (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    email VARCHAR(255) NOT NULL,
+    event VARCHAR(30) NOT NULL, -- unsubscribe, hard_bounce, spam or delivered
+    message_id VARCHAR(255) NOT NULL DEFAULT '', -- empty for marketing campaigns
+    payload JSONB NOT NULL,
+    occurred_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    received_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (email, event, message_id, occurred_at) -- Brevo retries deliveries
+);
+
+-- Addresses that get no more list additions or transactional email
+CREATE TABLE email_suppressions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    email VARCHAR(255) NOT NULL,
+    reason VARCHAR(30) NOT NULL, -- the suppressing event
+    suppressed_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX idx_email_suppressions_email ON email_suppressions (lower(email));
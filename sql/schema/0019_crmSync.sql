@@ -0,0 +1,6 @@
+-- +goose Up
+ALTER TABLE leads
+    ADD COLUMN crm_person_id BIGINT, -- Follow Up Boss person, once their webhooks mention it
+    ADD COLUMN crm_stage VARCHAR(100) NOT NULL DEFAULT '';
+
+CREATE INDEX idx_leads_crm_person_id ON leads (crm_person_id);
@@ -0,0 +1,13 @@
+-- +goose Up
+-- Responses of submissions, replayed when the same submission is repeated
+CREATE TABLE idempotency_keys (
+    key VARCHAR(320) PRIMARY KEY, -- route and Idempotency-Key header, or route and payload hash
+    request_hash VARCHAR(64) NOT NULL,
+    response_status INTEGER NOT NULL,
+    content_type VARCHAR(100) NOT NULL DEFAULT '',
+    response_body BYTEA NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE INDEX idx_idempotency_keys_expires_at ON idempotency_keys (expires_at);
@@ -0,0 +1,11 @@
+-- +goose Up
+CREATE TABLE mortgage_assumptions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    state VARCHAR(2) NOT NULL DEFAULT '', -- required unless zip is set
+    county VARCHAR(100) NOT NULL DEFAULT '',
+    zip VARCHAR(5) NOT NULL DEFAULT '',
+    tax_rate DOUBLE PRECISION NOT NULL, -- yearly share of the price
+    annual_insurance DOUBLE PRECISION NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,9 @@
+-- +goose Up
+ALTER TABLE notification_digest_items
+    ADD COLUMN attempts INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN next_attempt_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    ADD COLUMN last_error TEXT,
+    ADD COLUMN failed_at TIMESTAMP WITH TIME ZONE; -- set once attempts run out
+
+DROP INDEX idx_notification_digest_items_unsent;
+CREATE INDEX idx_notification_digest_items_unsent ON notification_digest_items (next_attempt_at) WHERE sent_at IS NULL AND failed_at IS NULL;
@@ -0,0 +1,5 @@
+-- +goose Up
+-- SHA-256 of the token sign-in devices send; NULL until one is issued
+ALTER TABLE open_houses ADD COLUMN device_token_hash BYTEA;
+
+CREATE INDEX idx_open_house_sign_ins_created_at ON open_house_sign_ins (open_house_id, created_at);
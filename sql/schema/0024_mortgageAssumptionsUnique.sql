@@ -0,0 +1,9 @@
+-- +goose Up
+-- Keep the most recently updated of any duplicate locations. Counties match
+-- case-insensitively, as they do when assumptions are looked up.
+DELETE FROM mortgage_assumptions a
+USING mortgage_assumptions b
+WHERE a.state = b.state AND lower(a.county) = lower(b.county) AND a.zip = b.zip
+  AND (a.updated_at, a.id) < (b.updated_at, b.id);
+
+CREATE UNIQUE INDEX idx_mortgage_assumptions_location ON mortgage_assumptions (state, lower(county), zip);